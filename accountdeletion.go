@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+// deleteAccountRequest confirms an account deletion
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// AccountDeletion says when a deleted account is removed for good
+type AccountDeletion struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the account and everything in it is permanently
+	// removed. Logging in before then cancels the deletion.
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// handleDeleteUser godoc
+// @Summary      Delete your account
+// @Description  Deletes the authenticated user's account after checking their password. The account, its chirps and rechirps of them disappear at once, remote ActivityPub servers following the user are sent a Delete of the account, every refresh token is revoked, access tokens already issued stop working, and the user's WebSocket and event stream connections are closed. Logging in again within the restore window (30 days by default) cancels the deletion, after which the account and all its data are removed permanently.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                true  "Bearer JWT token"
+// @Param        body           body    deleteAccountRequest  true  "Current password"
+// @Success      202  {object}  AccountDeletion
+// @Failure      400  {object}  ErrorResponse "Invalid request body"
+// @Failure      401  {object}  ErrorResponse "Unauthorized, invalid token or incorrect password"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users [delete]
+func (cfg *apiConfig) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	params := deleteAccountRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+	user, err := cfg.dbQueries.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetUserByID error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	if err := auth.CheckPasswordHash(params.Password, user.HashedPassword); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Incorrect password")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	deletedAt, err := qtx.SoftDeleteUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Deleted by a concurrent request.
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		log.Printf("SoftDeleteUser error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	// The chirps share the account's deletion time so that cancelling
+	// restores them without the ones deleted earlier.
+	deleted, err := qtx.SoftDeleteUserChirps(ctx, database.SoftDeleteUserChirpsParams{
+		DeletedAt: deletedAt,
+		UserID:    userID,
+	})
+	if err != nil {
+		log.Printf("SoftDeleteUserChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	if err := qtx.DeleteTimelineEntriesForChirps(ctx, deleted); err != nil {
+		log.Printf("DeleteTimelineEntriesForChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	if err := cfg.federation.accountDeleted(ctx, qtx, user); err != nil {
+		log.Printf("federating deletion of account %s: %v", userID, err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	if err := qtx.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		log.Printf("RevokeUserRefreshTokens error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	if err := endSessions(ctx, qtx, userID); err != nil {
+		log.Printf("PublishRealtimeEvent error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete account")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, AccountDeletion{
+		DeletedAt: deletedAt.Time,
+		PurgeAt:   deletedAt.Time.Add(cfg.restoreWindow),
+	})
+}
+
+// errAccountPurged is returned by cancelAccountDeletion once the restore
+// window has passed.
+var errAccountPurged = errors.New("account deletion can no longer be cancelled")
+
+// cancelAccountDeletion restores a deleted account and the chirps deleted
+// with it. It is called when the user logs in during the restore window.
+func (cfg *apiConfig) cancelAccountDeletion(ctx context.Context, user database.User) error {
+	if time.Since(user.DeletedAt.Time) > cfg.restoreWindow {
+		return errAccountPurged
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	restored, err := qtx.RestoreUser(ctx, database.RestoreUserParams{
+		ID:        user.ID,
+		DeletedAt: user.DeletedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("RestoreUser: %w", err)
+	}
+	if restored == 0 {
+		// Purged, or restored by a concurrent login.
+		return errAccountPurged
+	}
+	authors, err := qtx.RestoreUserChirps(ctx, database.RestoreUserChirpsParams{
+		UserID:    user.ID,
+		DeletedAt: user.DeletedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("RestoreUserChirps: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	cfg.invalidateRestoredTimelines(ctx, authors)
+	return nil
+}
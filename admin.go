@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+)
+
+// requireAdmin checks the "ApiKey" Authorization header against
+// ADMIN_API_KEY and writes an error response if it doesn't match. Admin
+// endpoints are disabled when no key is configured.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if cfg.adminKey == "" {
+		respondWithError(w, http.StatusForbidden, "Admin API is disabled")
+		return false
+	}
+	apiKey, err := auth.GetAPIKey(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't get api key")
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey), []byte(cfg.adminKey)) != 1 {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized user")
+		return false
+	}
+	return true
+}
+
+// requireModerator checks that the request carries the access token of a
+// moderator and writes an error response if it doesn't. It returns the
+// moderator's user ID.
+func (cfg *apiConfig) requireModerator(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return uuid.Nil, false
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return uuid.Nil, false
+	}
+
+	isModerator, err := cfg.dbQueries.IsModerator(r.Context(), userID)
+	if err != nil {
+		log.Printf("IsModerator error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check moderator status")
+		return uuid.Nil, false
+	}
+	if !isModerator {
+		respondWithError(w, http.StatusForbidden, "Moderators only")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
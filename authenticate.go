@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+)
+
+// errAccountInactive rejects access tokens of users whose account has been
+// deleted or suspended since the token was issued.
+var errAccountInactive = errors.New("account deleted or suspended")
+
+// validateJWT is auth.ValidateJWT for requests: besides checking the token,
+// it makes sure its user hasn't been deleted or suspended, so an access
+// token stops working as soon as that happens rather than when it expires.
+func (cfg *apiConfig) validateJWT(ctx context.Context, token string) (uuid.UUID, error) {
+	userID, _, err := cfg.validateJWTExpiry(ctx, token)
+	return userID, err
+}
+
+// validateJWTExpiry is validateJWT, also returning when the token expires.
+func (cfg *apiConfig) validateJWTExpiry(ctx context.Context, token string) (uuid.UUID, time.Time, error) {
+	userID, expiresAt, err := auth.ValidateJWTExpiry(token, cfg.jwtSecret)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	active, err := cfg.dbQueries.IsUserActive(ctx, userID)
+	if err != nil {
+		// Callers answer any error with a 401, so log this one here.
+		log.Printf("IsUserActive error: %v", err)
+		return uuid.Nil, time.Time{}, err
+	}
+	if !active {
+		return uuid.Nil, time.Time{}, errAccountInactive
+	}
+	return userID, expiresAt, nil
+}
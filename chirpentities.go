@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/entities"
+)
+
+// ChirpEntities holds the hashtags and mentions parsed from a chirp body.
+// Offsets are code point positions in the body; end is exclusive.
+type ChirpEntities struct {
+	Hashtags []HashtagEntity `json:"hashtags"`
+	Mentions []MentionEntity `json:"mentions"`
+}
+
+// HashtagEntity is a hashtag found in a chirp body
+type HashtagEntity struct {
+	Tag   string `json:"tag"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// MentionEntity is a mention of a registered user found in a chirp body
+type MentionEntity struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Start    int       `json:"start"`
+	End      int       `json:"end"`
+}
+
+// saveChirpEntities extracts hashtags and mentions from the chirp body and
+// stores them. Mentions of usernames that don't exist are ignored. It should
+// run in the same transaction that created the chirp.
+func saveChirpEntities(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	for _, tag := range entities.Hashtags(chirp.Body) {
+		hashtag, err := q.UpsertHashtag(ctx, database.UpsertHashtagParams{
+			ID:  uuid.New(),
+			Tag: tag.Tag,
+		})
+		if err != nil {
+			return fmt.Errorf("saving hashtag %q: %w", tag.Tag, err)
+		}
+		if err := q.CreateChirpHashtag(ctx, database.CreateChirpHashtagParams{
+			ChirpID:     chirp.ID,
+			HashtagID:   hashtag.ID,
+			StartOffset: int32(tag.Start),
+			EndOffset:   int32(tag.End),
+		}); err != nil {
+			return fmt.Errorf("linking hashtag %q: %w", tag.Tag, err)
+		}
+	}
+
+	mentions := entities.Mentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+	usernames := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		usernames = append(usernames, m.Username)
+	}
+	// Users who have blocked the author, or whom the author has blocked,
+	// are not mentioned.
+	users, err := q.GetUsersByUsernames(ctx, database.GetUsersByUsernamesParams{
+		Usernames: usernames,
+		AuthorID:  chirp.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("resolving mentions: %w", err)
+	}
+	userIDs := make(map[string]uuid.UUID, len(users))
+	for _, u := range users {
+		userIDs[u.Username.String] = u.ID
+	}
+	for _, m := range mentions {
+		userID, ok := userIDs[m.Username]
+		if !ok {
+			continue
+		}
+		if err := q.CreateMention(ctx, database.CreateMentionParams{
+			ChirpID:     chirp.ID,
+			UserID:      userID,
+			StartOffset: int32(m.Start),
+			EndOffset:   int32(m.End),
+		}); err != nil {
+			return fmt.Errorf("saving mention of %q: %w", m.Username, err)
+		}
+	}
+	return nil
+}
+
+// loadChirpEntities returns the stored entities of the given chirps keyed by
+// chirp ID.
+func (cfg *apiConfig) loadChirpEntities(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*ChirpEntities, error) {
+	result := make(map[uuid.UUID]*ChirpEntities, len(ids))
+	for _, id := range ids {
+		result[id] = &ChirpEntities{Hashtags: []HashtagEntity{}, Mentions: []MentionEntity{}}
+	}
+
+	hashtags, err := cfg.dbQueries.GetHashtagEntities(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("loading hashtags: %w", err)
+	}
+	for _, h := range hashtags {
+		e := result[h.ChirpID]
+		e.Hashtags = append(e.Hashtags, HashtagEntity{
+			Tag:   h.Tag,
+			Start: int(h.StartOffset),
+			End:   int(h.EndOffset),
+		})
+	}
+
+	mentions, err := cfg.dbQueries.GetMentionEntities(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("loading mentions: %w", err)
+	}
+	for _, m := range mentions {
+		e := result[m.ChirpID]
+		e.Mentions = append(e.Mentions, MentionEntity{
+			UserID:   m.UserID,
+			Username: m.Username.String,
+			Start:    int(m.StartOffset),
+			End:      int(m.EndOffset),
+		})
+	}
+	return result, nil
+}
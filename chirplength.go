@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+// Chirp length limits per membership tier, in characters as counted by
+// textlength.Count.
+const (
+	freeChirpLength      = 140
+	chirpyRedChirpLength = 280
+)
+
+// chirpLengthLimit returns the longest chirp user may post.
+func chirpLengthLimit(user database.User) int {
+	if user.IsChirpyRed {
+		return chirpyRedChirpLength
+	}
+	return freeChirpLength
+}
+
+// ChirpLengthPolicy describes how chirp length is counted
+// @Description The chirp length limit and how length is counted. Length is measured in user-perceived characters (grapheme clusters), so an emoji counts as one character; every http(s) link counts as url_weight characters regardless of its length.
+type ChirpLengthPolicy struct {
+	// MaxLength is the limit for the caller, or for free accounts when unauthenticated
+	MaxLength int `json:"max_length" example:"140"`
+	URLWeight int `json:"url_weight" example:"23"`
+	// Tiers lists the limit of each membership tier
+	Tiers ChirpLengthTiers `json:"tiers"`
+	// Length is the counted length of the text query parameter, if given
+	Length *int `json:"length,omitempty"`
+	// Remaining is MaxLength minus Length; negative when the text is too long
+	Remaining *int `json:"remaining,omitempty"`
+}
+
+// ChirpLengthTiers lists the chirp length limit of each membership tier
+type ChirpLengthTiers struct {
+	Free      int `json:"free" example:"140"`
+	ChirpyRed int `json:"chirpy_red" example:"280"`
+}
+
+// handleChirpLengthPolicy godoc
+// @Summary      Get the chirp length policy
+// @Description  Returns the caller's chirp length limit and how length is counted. Pass text to have it counted, e.g. to show the remaining characters while composing. Authentication is optional; without it the free tier limit is returned.
+// @Tags         chirps
+// @Produce      json
+// @Param        Authorization  header  string  false  "Bearer JWT token"
+// @Param        text           query   string  false  "Text to count"
+// @Success      200  {object}  ChirpLengthPolicy
+// @Failure      401  {object}  ErrorResponse "Invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/length [get]
+func (cfg *apiConfig) handleChirpLengthPolicy(w http.ResponseWriter, r *http.Request) {
+	policy := ChirpLengthPolicy{
+		MaxLength: freeChirpLength,
+		URLWeight: textlength.URLWeight,
+		Tiers: ChirpLengthTiers{
+			Free:      freeChirpLength,
+			ChirpyRed: chirpyRedChirpLength,
+		},
+	}
+
+	userID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
+	if userID != uuid.Nil {
+		user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get user")
+			return
+		}
+		policy.MaxLength = chirpLengthLimit(user)
+	}
+
+	if r.URL.Query().Has("text") {
+		length := textlength.Count(r.URL.Query().Get("text"))
+		remaining := policy.MaxLength - length
+		policy.Length = &length
+		policy.Remaining = &remaining
+	}
+	respondWithJSON(w, http.StatusOK, policy)
+}
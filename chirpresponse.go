@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+)
+
+// chirpResponses converts database chirps into API chirps. Chirps that
+// rechirp or quote another chirp get the referenced chirp embedded, and
+// every chirp carries its rechirp count, parsed entities, attachments and
+// poll. Referenced chirps are loaded in a single query so list endpoints
+// don't issue one lookup per chirp; those viewerID can't see aren't embedded.
+func (cfg *apiConfig) chirpResponses(ctx context.Context, viewerID uuid.UUID, chirps []database.Chirp) ([]Chirp, error) {
+	loaded := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, c := range chirps {
+		loaded[c.ID] = c
+	}
+
+	var missing []uuid.UUID
+	for _, c := range chirps {
+		for _, ref := range []uuid.NullUUID{c.RechirpOf, c.QuoteOf} {
+			if !ref.Valid {
+				continue
+			}
+			if _, ok := loaded[ref.UUID]; !ok {
+				missing = append(missing, ref.UUID)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		referenced, err := cfg.dbQueries.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{Ids: missing, ViewerID: viewerID})
+		if err != nil {
+			return nil, fmt.Errorf("loading referenced chirps: %w", err)
+		}
+		for _, c := range referenced {
+			loaded[c.ID] = c
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(loaded))
+	for id := range loaded {
+		ids = append(ids, id)
+	}
+	counts, err := cfg.dbQueries.CountRechirps(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("counting rechirps: %w", err)
+	}
+	rechirpCounts := make(map[uuid.UUID]int64, len(counts))
+	for _, row := range counts {
+		rechirpCounts[row.RechirpOf.UUID] = row.Count
+	}
+	chirpEntities, err := cfg.loadChirpEntities(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	media, err := cfg.dbQueries.GetMediaForChirps(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("loading attachments: %w", err)
+	}
+	variants, err := cfg.loadMediaVariants(ctx, media)
+	if err != nil {
+		return nil, fmt.Errorf("loading attachment variants: %w", err)
+	}
+	polls, err := cfg.loadPolls(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("loading polls: %w", err)
+	}
+	attachments := make(map[uuid.UUID][]Attachment, len(media))
+	for _, m := range media {
+		attachments[m.ChirpID.UUID] = append(attachments[m.ChirpID.UUID], cfg.attachment(m, variants[m.ID]))
+	}
+
+	toChirp := func(c database.Chirp) Chirp {
+		chirp := Chirp{
+			ID:           c.ID,
+			CreatedAt:    c.CreatedAt,
+			UpdatedAt:    c.UpdatedAt,
+			Body:         c.Body,
+			UserID:       c.UserID,
+			Visibility:   c.Visibility,
+			RechirpCount: rechirpCounts[c.ID],
+			Entities:     chirpEntities[c.ID],
+			Attachments:  attachments[c.ID],
+			Poll:         polls[c.ID],
+		}
+		if chirp.Attachments == nil {
+			chirp.Attachments = []Attachment{}
+		}
+		if c.RechirpOf.Valid {
+			chirp.RechirpOf = &c.RechirpOf.UUID
+		}
+		if c.QuoteOf.Valid {
+			chirp.QuoteOf = &c.QuoteOf.UUID
+		}
+		if !c.Published {
+			chirp.PublishAt = &c.PublishAt.Time
+		}
+		return chirp
+	}
+
+	responseChirps := make([]Chirp, 0, len(chirps))
+	for _, c := range chirps {
+		chirp := toChirp(c)
+		if ref, ok := loaded[c.RechirpOf.UUID]; c.RechirpOf.Valid && ok {
+			embedded := toChirp(ref)
+			chirp.RechirpedChirp = &embedded
+		}
+		if ref, ok := loaded[c.QuoteOf.UUID]; c.QuoteOf.Valid && ok {
+			embedded := toChirp(ref)
+			chirp.QuotedChirp = &embedded
+		}
+		responseChirps = append(responseChirps, chirp)
+	}
+	return responseChirps, nil
+}
+
+// chirpResponse is chirpResponses for a single chirp.
+func (cfg *apiConfig) chirpResponse(ctx context.Context, viewerID uuid.UUID, chirp database.Chirp) (Chirp, error) {
+	chirps, err := cfg.chirpResponses(ctx, viewerID, []database.Chirp{chirp})
+	if err != nil {
+		return Chirp{}, err
+	}
+	return chirps[0], nil
+}
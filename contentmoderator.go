@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/moderation"
+)
+
+// moderationReloadInterval is how often rules are reloaded from the
+// database, so changes made through another server instance take effect.
+// Changes made through this instance apply immediately.
+const moderationReloadInterval = 30 * time.Second
+
+// contentModerator holds the moderation pipeline built from the rules in
+// the database. The pipeline is swapped atomically on reload, so checks
+// never block on it.
+type contentModerator struct {
+	queries  *database.Queries
+	pipeline atomic.Pointer[moderation.Pipeline]
+}
+
+func newContentModerator(queries *database.Queries) *contentModerator {
+	m := &contentModerator{queries: queries}
+	empty, _ := moderation.New(nil)
+	m.pipeline.Store(empty)
+	return m
+}
+
+// start loads the rules and keeps reloading them until ctx is cancelled.
+func (m *contentModerator) start(ctx context.Context) {
+	if err := m.reload(ctx); err != nil {
+		log.Printf("Couldn't load moderation rules: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(moderationReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.reload(ctx); err != nil {
+					log.Printf("Couldn't reload moderation rules: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// reload rebuilds the pipeline from the enabled rules. On error the current
+// pipeline stays in place.
+func (m *contentModerator) reload(ctx context.Context) error {
+	rules, err := m.queries.ListModerationRules(ctx)
+	if err != nil {
+		return fmt.Errorf("ListModerationRules: %w", err)
+	}
+	words, err := m.queries.ListModerationWords(ctx)
+	if err != nil {
+		return fmt.Errorf("ListModerationWords: %w", err)
+	}
+	wordLists := make(map[uuid.UUID][]string)
+	for _, w := range words {
+		wordLists[w.RuleID] = append(wordLists[w.RuleID], w.Word)
+	}
+
+	var compiled []moderation.Rule
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		compiled = append(compiled, moderation.Rule{
+			ID:      rule.ID.String(),
+			Kind:    moderation.Kind(rule.Kind),
+			Action:  moderation.Action(rule.Action),
+			Words:   wordLists[rule.ID],
+			Pattern: rule.Pattern,
+		})
+	}
+	pipeline, err := moderation.New(compiled)
+	if err != nil {
+		return err
+	}
+	m.pipeline.Store(pipeline)
+	return nil
+}
+
+// check runs text through the current pipeline.
+func (m *contentModerator) check(text string) moderation.Result {
+	return m.pipeline.Load().Check(text)
+}
+
+// saveModerationFlags records the matches of flag rules for review.
+func saveModerationFlags(ctx context.Context, q *database.Queries, chirpID uuid.UUID, result moderation.Result) error {
+	for _, flag := range result.Flags() {
+		ruleID, err := uuid.Parse(flag.RuleID)
+		err = q.CreateModerationFlag(ctx, database.CreateModerationFlagParams{
+			ChirpID:     chirpID,
+			RuleID:      uuid.NullUUID{UUID: ruleID, Valid: err == nil},
+			MatchedText: flag.Text,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,665 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+const (
+	// maxConversationParticipants is the largest a conversation can be,
+	// including the user who starts it.
+	maxConversationParticipants = 50
+	// maxMessageLength is the longest message, in characters.
+	maxMessageLength = 2000
+)
+
+// Message is a direct message in a conversation
+type Message struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Participant is a member of a conversation
+type Participant struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username,omitempty"`
+	JoinedAt time.Time `json:"joined_at"`
+	// LastReadMessageID is the newest message the participant has read
+	LastReadMessageID *uuid.UUID `json:"last_read_message_id,omitempty"`
+	LastReadAt        *time.Time `json:"last_read_at,omitempty"`
+}
+
+// Conversation is a private conversation between two or more users
+type Conversation struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the last message was sent
+	UpdatedAt time.Time `json:"updated_at"`
+	// Participants are the users who haven't left
+	Participants []Participant `json:"participants"`
+	LastMessage  *Message      `json:"last_message,omitempty"`
+	// UnreadCount is the number of messages from others after the caller's
+	// read marker
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// ConversationPage is one page of the caller's conversations
+type ConversationPage struct {
+	Conversations []Conversation `json:"conversations"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+}
+
+// MessagePage is one page of a conversation's messages, newest first
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// createConversationRequest is the body for starting a conversation
+type createConversationRequest struct {
+	// ParticipantIDs are the users to talk to, not including the caller
+	ParticipantIDs []uuid.UUID `json:"participant_ids"`
+}
+
+// messageRequest is the body for sending a message
+type messageRequest struct {
+	Body string `json:"body"`
+}
+
+// readMarkerRequest is the body for moving a read marker
+type readMarkerRequest struct {
+	MessageID uuid.UUID `json:"message_id"`
+}
+
+// handleCreateConversation godoc
+// @Summary      Start a conversation
+// @Description  Starts a private conversation between the authenticated user and up to 49 other users. Users who have blocked the caller, or whom the caller has blocked, can't be added.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                     true  "Bearer JWT token"
+// @Param        conversation   body    createConversationRequest  true  "Participants"
+// @Success      201  {object}  Conversation
+// @Failure      400  {object}  ErrorResponse "No other participants or too many"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "A participant has blocked the caller or is blocked by them"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations [post]
+func (cfg *apiConfig) handleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	var params createConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	seen := map[uuid.UUID]bool{userID: true}
+	var others []uuid.UUID
+	for _, id := range params.ParticipantIDs {
+		if !seen[id] {
+			seen[id] = true
+			others = append(others, id)
+		}
+	}
+	if len(others) == 0 {
+		respondWithError(w, http.StatusBadRequest, "At least one other participant is required")
+		return
+	}
+	if len(others)+1 > maxConversationParticipants {
+		respondWithError(w, http.StatusBadRequest, "Too many participants")
+		return
+	}
+
+	ctx := r.Context()
+	found, err := cfg.dbQueries.CountUsersByIDs(ctx, others)
+	if err != nil {
+		log.Printf("CountUsersByIDs error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+		return
+	}
+	if found != int64(len(others)) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	blocked, err := cfg.dbQueries.UserBlocksAny(ctx, database.UserBlocksAnyParams{OtherIds: others, UserID: userID})
+	if err != nil {
+		log.Printf("UserBlocksAny error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+		return
+	}
+	if blocked {
+		respondWithError(w, http.StatusForbidden, "You can't message a user you blocked or who blocked you")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	conversation, err := qtx.CreateConversation(ctx, uuid.New())
+	if err != nil {
+		log.Printf("CreateConversation error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+		return
+	}
+	for _, id := range append([]uuid.UUID{userID}, others...) {
+		if err := qtx.AddConversationParticipant(ctx, database.AddConversationParticipantParams{
+			ConversationID: conversation.ID,
+			UserID:         id,
+		}); err != nil {
+			log.Printf("AddConversationParticipant error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+		return
+	}
+
+	resp, err := cfg.conversationResponses(ctx, userID, []database.Conversation{conversation}, []int64{0})
+	if err != nil {
+		log.Printf("conversationResponses error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create conversation")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp[0])
+}
+
+// handleGetConversations godoc
+// @Summary      List conversations
+// @Description  The authenticated user's conversations, most recently active first, with participants, the last message and the number of unread messages
+// @Tags         conversations
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  ConversationPage
+// @Failure      400  {object}  ErrorResponse "Invalid pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations [get]
+func (cfg *apiConfig) handleGetConversations(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := r.Context()
+	rows, err := cfg.dbQueries.ListConversations(ctx, database.ListConversationsParams{
+		UserID:          userID,
+		BeforeUpdatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("ListConversations error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch conversations")
+		return
+	}
+
+	conversations := make([]database.Conversation, len(rows))
+	unread := make([]int64, len(rows))
+	for i, row := range rows {
+		conversations[i] = row.Conversation
+		unread[i] = row.UnreadCount
+	}
+	page := ConversationPage{}
+	page.Conversations, err = cfg.conversationResponses(ctx, userID, conversations, unread)
+	if err != nil {
+		log.Printf("conversationResponses error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch conversations")
+		return
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1].Conversation
+		page.NextCursor = nextCursor(len(rows), limit, last.UpdatedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handleGetConversation godoc
+// @Summary      Get a conversation
+// @Description  One of the authenticated user's conversations
+// @Tags         conversations
+// @Produce      json
+// @Param        Authorization   header  string  true  "Bearer JWT token"
+// @Param        conversationID  path    string  true  "Conversation ID"
+// @Success      200  {object}  Conversation
+// @Failure      400  {object}  ErrorResponse "Invalid conversation ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Conversation not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations/{conversationID} [get]
+func (cfg *apiConfig) handleGetConversation(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+	ctx := r.Context()
+	row, err := cfg.dbQueries.GetConversation(ctx, database.GetConversationParams{UserID: userID, ID: conversationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetConversation error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load conversation")
+		return
+	}
+	resp, err := cfg.conversationResponses(ctx, userID, []database.Conversation{row.Conversation}, []int64{row.UnreadCount})
+	if err != nil {
+		log.Printf("conversationResponses error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load conversation")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp[0])
+}
+
+// handleSendMessage godoc
+// @Summary      Send a message
+// @Description  Sends a message to a conversation the authenticated user takes part in, and moves their read marker to it. Fails while any other participant has blocked the sender or is blocked by them.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header  string          true  "Bearer JWT token"
+// @Param        conversationID  path    string          true  "Conversation ID"
+// @Param        message         body    messageRequest  true  "Message, up to 2000 characters"
+// @Success      201  {object}  Message
+// @Failure      400  {object}  ErrorResponse "Invalid conversation ID, or empty or too long message"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "A participant has blocked the sender or is blocked by them"
+// @Failure      404  {object}  ErrorResponse "Conversation not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations/{conversationID}/messages [post]
+func (cfg *apiConfig) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	userID, conversationID, ok := cfg.conversationParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	var params messageRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	if strings.TrimSpace(params.Body) == "" {
+		respondWithError(w, http.StatusBadRequest, "Message is empty")
+		return
+	}
+	if textlength.Graphemes(params.Body) > maxMessageLength {
+		respondWithError(w, http.StatusBadRequest, "Message is too long")
+		return
+	}
+
+	ctx := r.Context()
+	blocked, err := cfg.dbQueries.ConversationHasBlock(ctx, database.ConversationHasBlockParams{
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		log.Printf("ConversationHasBlock error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	if blocked {
+		respondWithError(w, http.StatusForbidden, "You can't message a user you blocked or who blocked you")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	message, err := qtx.CreateMessage(ctx, database.CreateMessageParams{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Body:           params.Body,
+	})
+	if err != nil {
+		log.Printf("CreateMessage error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	if err := qtx.TouchConversation(ctx, database.TouchConversationParams{
+		ID:        conversationID,
+		UpdatedAt: message.CreatedAt,
+	}); err != nil {
+		log.Printf("TouchConversation error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	if _, err := qtx.MarkConversationRead(ctx, database.MarkConversationReadParams{
+		MessageID:      uuid.NullUUID{UUID: message.ID, Valid: true},
+		ReadAt:         sql.NullTime{Time: message.CreatedAt, Valid: true},
+		ConversationID: conversationID,
+		UserID:         userID,
+	}); err != nil {
+		log.Printf("MarkConversationRead error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	if err := publishRealtimeEvent(ctx, qtx, realtimeEvent{
+		Type:           realtimeMessage,
+		UserID:         userID,
+		ConversationID: conversationID,
+		ID:             message.ID,
+	}); err != nil {
+		log.Printf("PublishRealtimeEvent error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't send message")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, messageResponse(message))
+}
+
+// handleGetMessages godoc
+// @Summary      List messages
+// @Description  Messages in a conversation the authenticated user takes part in, newest first. Messages from users the caller has blocked, or who have blocked the caller, are left out.
+// @Tags         conversations
+// @Produce      json
+// @Param        Authorization   header  string  true   "Bearer JWT token"
+// @Param        conversationID  path    string  true   "Conversation ID"
+// @Param        limit           query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor          query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  MessagePage
+// @Failure      400  {object}  ErrorResponse "Invalid conversation ID or pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Conversation not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations/{conversationID}/messages [get]
+func (cfg *apiConfig) handleGetMessages(w http.ResponseWriter, r *http.Request) {
+	userID, conversationID, ok := cfg.conversationParticipant(w, r)
+	if !ok {
+		return
+	}
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := cfg.dbQueries.GetMessages(r.Context(), database.GetMessagesParams{
+		ConversationID:  conversationID,
+		ViewerID:        userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("GetMessages error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch messages")
+		return
+	}
+
+	page := MessagePage{Messages: []Message{}}
+	for _, m := range messages {
+		page.Messages = append(page.Messages, messageResponse(m))
+	}
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		page.NextCursor = nextCursor(len(messages), limit, last.CreatedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handleMarkConversationRead godoc
+// @Summary      Mark messages as read
+// @Description  Moves the authenticated user's read marker in a conversation to a message. The marker only moves forward; marking an older message has no effect.
+// @Tags         conversations
+// @Accept       json
+// @Param        Authorization   header  string             true  "Bearer JWT token"
+// @Param        conversationID  path    string             true  "Conversation ID"
+// @Param        marker          body    readMarkerRequest  true  "Newest message read"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid conversation ID or request"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Conversation or message not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations/{conversationID}/read [post]
+func (cfg *apiConfig) handleMarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	userID, conversationID, ok := cfg.conversationParticipant(w, r)
+	if !ok {
+		return
+	}
+	var params readMarkerRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+
+	ctx := r.Context()
+	message, err := cfg.dbQueries.GetMessage(ctx, database.GetMessageParams{ID: params.MessageID, ConversationID: conversationID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Message not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetMessage error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark messages as read")
+		return
+	}
+	if _, err := cfg.dbQueries.MarkConversationRead(ctx, database.MarkConversationReadParams{
+		MessageID:      uuid.NullUUID{UUID: message.ID, Valid: true},
+		ReadAt:         sql.NullTime{Time: message.CreatedAt, Valid: true},
+		ConversationID: conversationID,
+		UserID:         userID,
+	}); err != nil {
+		log.Printf("MarkConversationRead error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark messages as read")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleLeaveConversation godoc
+// @Summary      Leave a conversation
+// @Description  The authenticated user leaves a conversation. They stop receiving its messages and can no longer read or send any; their earlier messages stay visible to the others.
+// @Tags         conversations
+// @Param        Authorization   header  string  true  "Bearer JWT token"
+// @Param        conversationID  path    string  true  "Conversation ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid conversation ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Conversation not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/conversations/{conversationID}/leave [post]
+func (cfg *apiConfig) handleLeaveConversation(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+	left, err := cfg.dbQueries.LeaveConversation(r.Context(), database.LeaveConversationParams{
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		log.Printf("LeaveConversation error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't leave conversation")
+		return
+	}
+	if left == 0 {
+		respondWithError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// conversationParticipant authenticates the caller and checks they take
+// part in the conversation named in the path, writing an error response
+// when they don't. Conversations the caller isn't in are reported as not
+// found so their existence isn't revealed.
+func (cfg *apiConfig) conversationParticipant(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	member, err := cfg.dbQueries.IsConversationParticipant(r.Context(), database.IsConversationParticipantParams{
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		log.Printf("IsConversationParticipant error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load conversation")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !member {
+		respondWithError(w, http.StatusNotFound, "Conversation not found")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, conversationID, true
+}
+
+// conversationResponses converts conversations into API conversations,
+// loading the participants and last messages of all of them in one query
+// each. unreadCounts holds the caller's unread count for each conversation.
+func (cfg *apiConfig) conversationResponses(ctx context.Context, viewerID uuid.UUID, conversations []database.Conversation, unreadCounts []int64) ([]Conversation, error) {
+	ids := make([]uuid.UUID, len(conversations))
+	for i, c := range conversations {
+		ids[i] = c.ID
+	}
+	participants, err := cfg.dbQueries.GetConversationParticipants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byConversation := make(map[uuid.UUID][]Participant, len(conversations))
+	for _, p := range participants {
+		participant := Participant{
+			UserID:   p.UserID,
+			Username: p.Username.String,
+			JoinedAt: p.JoinedAt,
+		}
+		if p.LastReadMessageID.Valid {
+			participant.LastReadMessageID = &p.LastReadMessageID.UUID
+		}
+		if p.LastReadAt.Valid {
+			participant.LastReadAt = &p.LastReadAt.Time
+		}
+		byConversation[p.ConversationID] = append(byConversation[p.ConversationID], participant)
+	}
+	latest, err := cfg.dbQueries.GetLatestMessages(ctx, database.GetLatestMessagesParams{
+		ConversationIds: ids,
+		ViewerID:        viewerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lastMessages := make(map[uuid.UUID]Message, len(latest))
+	for _, m := range latest {
+		lastMessages[m.ConversationID] = messageResponse(m)
+	}
+
+	resp := make([]Conversation, 0, len(conversations))
+	for i, c := range conversations {
+		conversation := Conversation{
+			ID:           c.ID,
+			CreatedAt:    c.CreatedAt,
+			UpdatedAt:    c.UpdatedAt,
+			Participants: byConversation[c.ID],
+			UnreadCount:  unreadCounts[i],
+		}
+		if conversation.Participants == nil {
+			conversation.Participants = []Participant{}
+		}
+		if m, ok := lastMessages[c.ID]; ok {
+			conversation.LastMessage = &m
+		}
+		resp = append(resp, conversation)
+	}
+	return resp, nil
+}
+
+func messageResponse(m database.Message) Message {
+	return Message{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Body:           m.Body,
+		CreatedAt:      m.CreatedAt,
+	}
+}
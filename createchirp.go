@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+// chirpInput is a chirp about to be posted, either from POST /api/chirps or
+// from a published draft.
+type chirpInput struct {
+	Body      string
+	QuoteOf   *uuid.UUID
+	MediaIDs  []uuid.UUID
+	PublishAt *time.Time
+	Poll      *pollRequest
+	// Visibility defaults to public when empty
+	Visibility string
+}
+
+// invalidChirpError is a problem with the chirp itself. Its message is
+// returned to the client with a 400.
+type invalidChirpError struct {
+	msg string
+}
+
+func (e *invalidChirpError) Error() string {
+	return e.msg
+}
+
+func invalidChirp(msg string) error {
+	return &invalidChirpError{msg: msg}
+}
+
+// errAuthorSuspended is returned by createChirp when a moderator has
+// suspended the author.
+var errAuthorSuspended = errors.New("author is suspended")
+
+// createChirp validates, moderates and stores a chirp by author along with
+// its entities, moderation flags, poll and attachments. q should be bound to a
+// transaction; the caller commits it and then fans the chirp out to
+// timelines if it was published.
+func (cfg *apiConfig) createChirp(ctx context.Context, q *database.Queries, author database.User, in chirpInput) (database.Chirp, error) {
+	suspended, err := q.IsUserSuspended(ctx, author.ID)
+	if err != nil {
+		return database.Chirp{}, fmt.Errorf("IsUserSuspended: %w", err)
+	}
+	if suspended {
+		return database.Chirp{}, errAuthorSuspended
+	}
+	if textlength.Count(in.Body) > chirpLengthLimit(author) {
+		return database.Chirp{}, invalidChirp("Chirp is too long")
+	}
+	if len(in.MediaIDs) > maxChirpMedia {
+		return database.Chirp{}, invalidChirp("Too many attachments")
+	}
+	visibility, err := normalizeVisibility(in.Visibility)
+	if err != nil {
+		return database.Chirp{}, invalidChirp(err.Error())
+	}
+	var publishAt sql.NullTime
+	if in.PublishAt != nil {
+		if err := validatePublishAt(*in.PublishAt); err != nil {
+			return database.Chirp{}, invalidChirp(err.Error())
+		}
+		publishAt = sql.NullTime{Time: in.PublishAt.UTC(), Valid: true}
+	}
+	if in.Poll != nil {
+		opens := time.Now()
+		if publishAt.Valid {
+			opens = publishAt.Time
+		}
+		if err := validatePoll(*in.Poll, opens); err != nil {
+			return database.Chirp{}, invalidChirp(err.Error())
+		}
+	}
+
+	var quoteOf uuid.NullUUID
+	if in.QuoteOf != nil {
+		quoted, err := q.GetChirp(ctx, database.GetChirpParams{ID: *in.QuoteOf, ViewerID: author.ID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Chirp{}, invalidChirp("Quoted chirp not found")
+		}
+		if err != nil {
+			return database.Chirp{}, fmt.Errorf("GetChirp: %w", err)
+		}
+		// Quoting a rechirp quotes the chirp it reposts.
+		if quoted.RechirpOf.Valid {
+			quoteOf = quoted.RechirpOf
+		} else {
+			quoteOf = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+		}
+	}
+
+	moderated := cfg.moderator.check(in.Body)
+	if moderated.Rejected {
+		return database.Chirp{}, invalidChirp("Chirp contains prohibited content")
+	}
+	now := time.Now().UTC()
+	chirp, err := q.CreateChirp(ctx, database.CreateChirpParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Body:       moderated.Text,
+		UserID:     author.ID,
+		QuoteOf:    quoteOf,
+		PublishAt:  publishAt,
+		Visibility: visibility,
+	})
+	if err != nil {
+		return database.Chirp{}, fmt.Errorf("CreateChirp: %w", err)
+	}
+	if err := saveChirpEntities(ctx, q, chirp); err != nil {
+		return database.Chirp{}, fmt.Errorf("saveChirpEntities: %w", err)
+	}
+	if err := saveModerationFlags(ctx, q, chirp.ID, moderated); err != nil {
+		return database.Chirp{}, fmt.Errorf("saveModerationFlags: %w", err)
+	}
+	if in.Poll != nil {
+		if err := savePoll(ctx, q, chirp.ID, *in.Poll); err != nil {
+			return database.Chirp{}, fmt.Errorf("savePoll: %w", err)
+		}
+	}
+	if err := attachMedia(ctx, q, chirp, in.MediaIDs); err != nil {
+		if errors.Is(err, errInvalidMedia) {
+			return database.Chirp{}, invalidChirp("Invalid media ID")
+		}
+		return database.Chirp{}, fmt.Errorf("attachMedia: %w", err)
+	}
+	return chirp, nil
+}
@@ -0,0 +1,638 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/storage"
+)
+
+const (
+	// exportRequestInterval is how often a user can request an export. A
+	// failed export can be retried straight away.
+	exportRequestInterval = 24 * time.Hour
+	// exportTTL is how long a finished archive can be downloaded.
+	exportTTL = 7 * 24 * time.Hour
+	// exportFailedTTL is how long failed exports are kept before the row is
+	// removed.
+	exportFailedTTL = 24 * time.Hour
+	// exportLinkTTL is how long a download URL works.
+	exportLinkTTL = time.Hour
+	// exportPollInterval is how often the exporter looks for requested
+	// exports; new requests wake it straight away.
+	exportPollInterval = time.Minute
+	exportJobTimeout   = 10 * time.Minute
+	// exportClaimTimeout is how long an export can be processing before
+	// another instance takes it over, e.g. after a crash.
+	exportClaimTimeout = 30 * time.Minute
+	exportBatchSize    = 4
+)
+
+// Export states.
+const (
+	exportPending    = "pending"
+	exportProcessing = "processing"
+	exportReady      = "ready"
+	exportFailed     = "failed"
+)
+
+// DataExport is an archive of everything a user has stored on Chirpy
+type DataExport struct {
+	ID        uuid.UUID `json:"id"`
+	Status    string    `json:"status" enums:"pending,processing,ready,failed"`
+	CreatedAt time.Time `json:"created_at"`
+	// SizeBytes is the size of the archive once it is ready
+	SizeBytes int64 `json:"size_bytes,omitempty"`
+	// ExpiresAt is when the archive is deleted
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// DownloadURL downloads the archive without an Authorization header
+	// until DownloadURLExpiresAt
+	DownloadURL          string     `json:"download_url,omitempty"`
+	DownloadURLExpiresAt *time.Time `json:"download_url_expires_at,omitempty"`
+}
+
+// handleRequestExport godoc
+// @Summary      Request an export of your data
+// @Description  Starts building a zip archive of the authenticated user's profile, chirps, drafts, media, sessions and the moderation actions taken against them. A notification is sent when it is ready; GET /api/users/export then returns a download link. One export can be requested a day, and a ready archive is kept for 7 days.
+// @Tags         users
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      202  {object}  DataExport
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      429  {object}  ErrorResponse "An export was already requested today"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/export [post]
+func (cfg *apiConfig) handleRequestExport(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't request export")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	// Otherwise two requests at once could both find no recent export.
+	if err := qtx.LockDataExportRequests(ctx, userID); err != nil {
+		log.Printf("LockDataExportRequests error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't request export")
+		return
+	}
+	export, err := qtx.CreateDataExport(ctx, database.CreateDataExportParams{
+		UserID:          userID,
+		IntervalSeconds: exportRequestInterval.Seconds(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if latest, err := cfg.dbQueries.GetLatestDataExport(ctx, userID); err == nil {
+			retryAfter := time.Until(latest.CreatedAt.Add(exportRequestInterval))
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		}
+		respondWithError(w, http.StatusTooManyRequests, "You can request one export a day")
+		return
+	}
+	if err != nil {
+		log.Printf("CreateDataExport error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't request export")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't request export")
+		return
+	}
+	cfg.exporter.wakeUp()
+	respondWithJSON(w, http.StatusAccepted, cfg.dataExportResponse(export))
+}
+
+// handleGetExport godoc
+// @Summary      Your latest data export
+// @Description  The state of the authenticated user's most recent export. Once it is ready the response has a download link that works without authentication for an hour; ask again for a fresh one.
+// @Tags         users
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      200  {object}  DataExport
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "No export requested"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/export [get]
+func (cfg *apiConfig) handleGetExport(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	export, err := cfg.dbQueries.GetLatestDataExport(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No export requested")
+		return
+	}
+	if err != nil {
+		log.Printf("GetLatestDataExport error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load export")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, cfg.dataExportResponse(export))
+}
+
+// handleDownloadExport godoc
+// @Summary      Download a data export
+// @Description  Downloads an export archive through the signed link from GET /api/users/export. The link is the authorization, so no Authorization header is needed.
+// @Tags         users
+// @Produce      application/zip
+// @Param        export_id  query  string  true  "Export ID"
+// @Param        expires    query  int     true  "Link expiry, in Unix seconds"
+// @Param        signature  query  string  true  "Link signature"
+// @Success      200  {file}    file  "The zip archive"
+// @Failure      403  {object}  ErrorResponse "Invalid or expired download link"
+// @Failure      404  {object}  ErrorResponse "Export not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/export/download [get]
+func (cfg *apiConfig) handleDownloadExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	exportID, err := uuid.Parse(query.Get("export_id"))
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, "Invalid or expired download link")
+		return
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, "Invalid or expired download link")
+		return
+	}
+	if err := auth.ValidateResourceSignature(exportResource(exportID), time.Unix(expires, 0), query.Get("signature"), cfg.jwtSecret); err != nil {
+		respondWithError(w, http.StatusForbidden, "Invalid or expired download link")
+		return
+	}
+
+	ctx := r.Context()
+	export, err := cfg.dbQueries.GetReadyDataExport(ctx, exportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Export not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetReadyDataExport error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load export")
+		return
+	}
+	archive, err := cfg.media.Get(ctx, export.StorageKey.String)
+	if err != nil {
+		log.Printf("opening export %s: %v", export.ID, err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load export")
+		return
+	}
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="chirpy-export-`+export.CreatedAt.Format("2006-01-02")+`.zip"`)
+	w.Header().Set("Content-Length", strconv.FormatInt(export.SizeBytes.Int64, 10))
+	w.Header().Set("Cache-Control", "private, no-store")
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Printf("sending export %s: %v", export.ID, err)
+	}
+}
+
+// exportResource is what download links for an export sign.
+func exportResource(exportID uuid.UUID) string {
+	return "export:" + exportID.String()
+}
+
+func (cfg *apiConfig) dataExportResponse(export database.DataExport) DataExport {
+	resp := DataExport{
+		ID:        export.ID,
+		Status:    export.Status,
+		CreatedAt: export.CreatedAt,
+	}
+	if export.Status != exportReady {
+		return resp
+	}
+	resp.SizeBytes = export.SizeBytes.Int64
+	resp.ExpiresAt = &export.ExpiresAt.Time
+	if time.Now().After(export.ExpiresAt.Time) {
+		return resp
+	}
+	// The link never outlives the archive.
+	expires := time.Now().Add(exportLinkTTL).Truncate(time.Second)
+	if expires.After(export.ExpiresAt.Time) {
+		expires = export.ExpiresAt.Time.Truncate(time.Second)
+	}
+	query := url.Values{
+		"export_id": {export.ID.String()},
+		"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+		"signature": {auth.SignResource(exportResource(export.ID), expires, cfg.jwtSecret)},
+	}
+	resp.DownloadURL = cfg.publicURL + "/api/users/export/download?" + query.Encode()
+	resp.DownloadURLExpiresAt = &expires
+	return resp
+}
+
+// dataExporter builds requested export archives in the background and
+// deletes them when they expire. Requests are claimed with FOR UPDATE SKIP
+// LOCKED, so several instances can run exporters.
+type dataExporter struct {
+	queries       *database.Queries
+	store         storage.Storage
+	notifications *notifier
+	wake          chan struct{}
+}
+
+func newDataExporter(queries *database.Queries, store storage.Storage, notifications *notifier) *dataExporter {
+	return &dataExporter{
+		queries:       queries,
+		store:         store,
+		notifications: notifications,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// wakeUp starts building requested exports without waiting for the next
+// poll.
+func (e *dataExporter) wakeUp() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// start builds and expires exports until ctx is cancelled.
+func (e *dataExporter) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(exportPollInterval)
+		defer ticker.Stop()
+		for {
+			e.exportRequested(ctx)
+			e.deleteExpired(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-e.wake:
+			}
+		}
+	}()
+}
+
+func (e *dataExporter) exportRequested(ctx context.Context) {
+	for {
+		exports, err := e.queries.ClaimDataExports(ctx, database.ClaimDataExportsParams{
+			TimeoutSeconds: exportClaimTimeout.Seconds(),
+			BatchSize:      exportBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("ClaimDataExports error: %v", err)
+			}
+			return
+		}
+		for _, export := range exports {
+			e.run(ctx, export)
+		}
+		if len(exports) < exportBatchSize {
+			return
+		}
+	}
+}
+
+func (e *dataExporter) run(ctx context.Context, export database.DataExport) {
+	jobCtx, cancel := context.WithTimeout(ctx, exportJobTimeout)
+	defer cancel()
+	key, size, err := e.build(jobCtx, export)
+	if err != nil {
+		log.Printf("Building export %s failed: %v", export.ID, err)
+		if ctx.Err() != nil {
+			// Shutting down; the claim times out and it is built again.
+			return
+		}
+		if err := e.queries.FailDataExport(ctx, export.ID); err != nil {
+			log.Printf("FailDataExport error: %v", err)
+		}
+		return
+	}
+	if err := e.queries.CompleteDataExport(ctx, database.CompleteDataExportParams{
+		StorageKey: sql.NullString{String: key, Valid: true},
+		SizeBytes:  sql.NullInt64{Int64: size, Valid: true},
+		TtlSeconds: exportTTL.Seconds(),
+		ID:         export.ID,
+	}); err != nil {
+		log.Printf("CompleteDataExport error: %v", err)
+		e.store.Delete(ctx, key)
+		return
+	}
+	e.notifications.exportReady(ctx, export.UserID, export.ID)
+}
+
+// build writes the user's archive to a temporary file and stores it,
+// returning its storage key and size. Archives are private objects, so they
+// are only downloaded through signed links.
+func (e *dataExporter) build(ctx context.Context, export database.DataExport) (string, int64, error) {
+	tmp, err := os.CreateTemp("", "chirpy-export-*.zip")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	if err := e.writeArchive(ctx, zw, export.UserID); err != nil {
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+	size, err := tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	key := storage.PrivatePrefix + path.Join("exports", export.ID.String()+".zip")
+	if err := e.store.Put(ctx, key, "application/zip", tmp, size); err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
+
+// Archive contents. Times are UTC and optional fields are omitted when
+// unset.
+type (
+	exportedProfile struct {
+		ID          uuid.UUID `json:"id"`
+		Email       string    `json:"email"`
+		Username    string    `json:"username,omitempty"`
+		IsChirpyRed bool      `json:"is_chirpy_red"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+	exportedChirp struct {
+		ID         uuid.UUID   `json:"id"`
+		Body       string      `json:"body"`
+		Visibility string      `json:"visibility"`
+		RechirpOf  *uuid.UUID  `json:"rechirp_of,omitempty"`
+		QuoteOf    *uuid.UUID  `json:"quote_of,omitempty"`
+		MediaIDs   []uuid.UUID `json:"media_ids,omitempty"`
+		Published  bool        `json:"published"`
+		PublishAt  *time.Time  `json:"publish_at,omitempty"`
+		DeletedAt  *time.Time  `json:"deleted_at,omitempty"`
+		CreatedAt  time.Time   `json:"created_at"`
+		UpdatedAt  time.Time   `json:"updated_at"`
+	}
+	exportedDraft struct {
+		ID         uuid.UUID   `json:"id"`
+		Body       string      `json:"body"`
+		Visibility string      `json:"visibility"`
+		QuoteOf    *uuid.UUID  `json:"quote_of,omitempty"`
+		MediaIDs   []uuid.UUID `json:"media_ids,omitempty"`
+		CreatedAt  time.Time   `json:"created_at"`
+		UpdatedAt  time.Time   `json:"updated_at"`
+	}
+	exportedMedia struct {
+		ID          uuid.UUID  `json:"id"`
+		ChirpID     *uuid.UUID `json:"chirp_id,omitempty"`
+		ContentType string     `json:"content_type"`
+		Width       int32      `json:"width"`
+		Height      int32      `json:"height"`
+		AltText     string     `json:"alt_text,omitempty"`
+		// File is the image's path in the archive; uploads that were never
+		// processed have none.
+		File      string    `json:"file,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	exportedSession struct {
+		CreatedAt time.Time  `json:"created_at"`
+		ExpiresAt time.Time  `json:"expires_at"`
+		RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	}
+	exportedModerationAction struct {
+		ID        uuid.UUID  `json:"id"`
+		Action    string     `json:"action"`
+		ChirpID   *uuid.UUID `json:"chirp_id,omitempty"`
+		Note      string     `json:"note,omitempty"`
+		CreatedAt time.Time  `json:"created_at"`
+	}
+)
+
+// writeArchive adds the user's data to zw: profile.json, chirps.json,
+// drafts.json, media.json with the images under media/, sessions.json and
+// moderation_actions.json.
+func (e *dataExporter) writeArchive(ctx context.Context, zw *zip.Writer, userID uuid.UUID) error {
+	user, err := e.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("GetUserByID: %w", err)
+	}
+	if err := writeArchiveJSON(zw, "profile.json", exportedProfile{
+		ID:          user.ID,
+		Email:       user.Email,
+		Username:    user.Username.String,
+		IsChirpyRed: user.IsChirpyRed,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}); err != nil {
+		return err
+	}
+
+	media, err := e.queries.GetMediaByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("GetMediaByUser: %w", err)
+	}
+	chirpMedia := make(map[uuid.UUID][]uuid.UUID)
+	exportedMediaList := make([]exportedMedia, 0, len(media))
+	for _, m := range media {
+		item := exportedMedia{
+			ID:          m.ID,
+			ChirpID:     nullUUIDPtr(m.ChirpID),
+			ContentType: m.ContentType,
+			Width:       m.Width,
+			Height:      m.Height,
+			AltText:     m.AltText,
+			CreatedAt:   m.CreatedAt,
+		}
+		if m.ChirpID.Valid {
+			chirpMedia[m.ChirpID.UUID] = append(chirpMedia[m.ChirpID.UUID], m.ID)
+		}
+		if m.Status == mediaReady {
+			item.File = "media/" + m.ID.String() + path.Ext(m.StorageKey)
+			if err := e.copyMedia(ctx, zw, item.File, m.StorageKey); err != nil {
+				return err
+			}
+		}
+		exportedMediaList = append(exportedMediaList, item)
+	}
+	if err := writeArchiveJSON(zw, "media.json", exportedMediaList); err != nil {
+		return err
+	}
+
+	chirps, err := e.queries.GetChirpsForExport(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("GetChirpsForExport: %w", err)
+	}
+	exportedChirps := make([]exportedChirp, 0, len(chirps))
+	for _, c := range chirps {
+		exportedChirps = append(exportedChirps, exportedChirp{
+			ID:         c.ID,
+			Body:       c.Body,
+			Visibility: c.Visibility,
+			RechirpOf:  nullUUIDPtr(c.RechirpOf),
+			QuoteOf:    nullUUIDPtr(c.QuoteOf),
+			MediaIDs:   chirpMedia[c.ID],
+			Published:  c.Published,
+			PublishAt:  nullTimePtr(c.PublishAt),
+			DeletedAt:  nullTimePtr(c.DeletedAt),
+			CreatedAt:  c.CreatedAt,
+			UpdatedAt:  c.UpdatedAt,
+		})
+	}
+	if err := writeArchiveJSON(zw, "chirps.json", exportedChirps); err != nil {
+		return err
+	}
+
+	drafts, err := e.queries.GetDrafts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("GetDrafts: %w", err)
+	}
+	exportedDrafts := make([]exportedDraft, 0, len(drafts))
+	for _, d := range drafts {
+		exportedDrafts = append(exportedDrafts, exportedDraft{
+			ID:         d.ID,
+			Body:       d.Body,
+			Visibility: d.Visibility,
+			QuoteOf:    nullUUIDPtr(d.QuoteOf),
+			MediaIDs:   d.MediaIds,
+			CreatedAt:  d.CreatedAt,
+			UpdatedAt:  d.UpdatedAt,
+		})
+	}
+	if err := writeArchiveJSON(zw, "drafts.json", exportedDrafts); err != nil {
+		return err
+	}
+
+	sessions, err := e.queries.GetSessionsForExport(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("GetSessionsForExport: %w", err)
+	}
+	exportedSessions := make([]exportedSession, 0, len(sessions))
+	for _, s := range sessions {
+		exportedSessions = append(exportedSessions, exportedSession{
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+			RevokedAt: nullTimePtr(s.RevokedAt),
+		})
+	}
+	if err := writeArchiveJSON(zw, "sessions.json", exportedSessions); err != nil {
+		return err
+	}
+
+	actions, err := e.queries.GetModerationActionsForExport(ctx, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		return fmt.Errorf("GetModerationActionsForExport: %w", err)
+	}
+	exportedActions := make([]exportedModerationAction, 0, len(actions))
+	for _, a := range actions {
+		exportedActions = append(exportedActions, exportedModerationAction{
+			ID:        a.ID,
+			Action:    a.Action,
+			ChirpID:   nullUUIDPtr(a.ChirpID),
+			Note:      a.Note,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	return writeArchiveJSON(zw, "moderation_actions.json", exportedActions)
+}
+
+func (e *dataExporter) copyMedia(ctx context.Context, zw *zip.Writer, name, key string) error {
+	src, err := e.store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("opening media %s: %w", key, err)
+	}
+	defer src.Close()
+	// Images are already compressed.
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func writeArchiveJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// deleteExpired removes expired archives and old failed exports.
+func (e *dataExporter) deleteExpired(ctx context.Context) {
+	for {
+		exports, err := e.queries.GetExpiredDataExports(ctx, database.GetExpiredDataExportsParams{
+			FailedTtlSeconds: exportFailedTTL.Seconds(),
+			BatchSize:        purgeBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("GetExpiredDataExports error: %v", err)
+			}
+			return
+		}
+		for _, export := range exports {
+			if err := e.queries.DeleteDataExport(ctx, export.ID); err != nil {
+				log.Printf("DeleteDataExport error: %v", err)
+				return
+			}
+			if export.StorageKey.Valid {
+				deleteMediaFiles(ctx, e.store, []string{export.StorageKey.String})
+			}
+		}
+		if len(exports) < purgeBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/activitypub"
+	"github.com/odilmode/http/internal/database"
+)
+
+const (
+	// deliveryInterval is how often the delivery queue is checked for
+	// retries that have come due; new deliveries start right away.
+	deliveryInterval  = 10 * time.Second
+	deliveryBatchSize = 50
+	deliveryWorkers   = 4
+	// deliveryLease is how long a claimed delivery is hidden from other
+	// instances. A delivery whose instance dies mid-batch is retried after it.
+	deliveryLease = 5 * time.Minute
+	// Failed deliveries are retried after deliveryBaseDelay, doubling with
+	// each attempt, and dropped after deliveryMaxAttempts: about 17 hours.
+	deliveryBaseDelay   = time.Minute
+	deliveryMaxAttempts = 10
+)
+
+// federation makes Chirpy accounts part of the fediverse: it publishes
+// users as ActivityPub actors, accepts follows from remote servers and
+// delivers users' public and unlisted chirps to their remote followers.
+// Deliveries go through the ap_deliveries table, so they survive restarts
+// and are retried while the remote server is down.
+type federation struct {
+	// baseURL is where the server is reachable from other servers; actor
+	// and object IDs are made from it and must never change.
+	baseURL string
+	host    string
+	db      *sql.DB
+	queries *database.Queries
+	client  *activitypub.Client
+	wake    chan struct{}
+}
+
+// newFederation configures federation for a server reachable at publicURL.
+// allowHTTP permits http URLs, for local development against other servers
+// that don't have certificates.
+func newFederation(publicURL string, db *sql.DB, queries *database.Queries, allowHTTP bool) (*federation, error) {
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" || (u.Scheme != "https" && !(allowHTTP && u.Scheme == "http")) {
+		return nil, errors.New("must be an https URL")
+	}
+	baseURL := strings.TrimSuffix(u.Scheme+"://"+u.Host+u.Path, "/")
+	client := activitypub.NewClient("Chirpy (+" + baseURL + ")")
+	client.AllowHTTP = allowHTTP
+	return &federation{
+		baseURL: baseURL,
+		host:    u.Host,
+		db:      db,
+		queries: queries,
+		client:  client,
+		wake:    make(chan struct{}, 1),
+	}, nil
+}
+
+func (f *federation) actorURL(userID uuid.UUID) string {
+	return f.baseURL + "/ap/users/" + userID.String()
+}
+
+func (f *federation) keyID(userID uuid.UUID) string {
+	return f.actorURL(userID) + "#main-key"
+}
+
+func (f *federation) noteURL(chirpID uuid.UUID) string {
+	return f.baseURL + "/ap/chirps/" + chirpID.String()
+}
+
+// localUser finds the user an actor URL's userID names. Only users with a
+// username are actors, since remote servers address users by username.
+func (f *federation) localUser(ctx context.Context, userID string) (database.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return database.User{}, sql.ErrNoRows
+	}
+	user, err := f.queries.GetUserByID(ctx, id)
+	if err != nil {
+		return database.User{}, err
+	}
+	if !user.Username.Valid {
+		return database.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+// actorKey returns the user's key pair, making one the first time it is
+// needed.
+func (f *federation) actorKey(ctx context.Context, userID uuid.UUID) (database.ActorKey, error) {
+	key, err := f.queries.GetActorKey(ctx, userID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return key, err
+	}
+	privatePEM, publicPEM, err := activitypub.GenerateKey()
+	if err != nil {
+		return database.ActorKey{}, err
+	}
+	// Two requests may race to make the key; the first one wins and both
+	// read it back.
+	if err := f.queries.CreateActorKey(ctx, database.CreateActorKeyParams{
+		UserID:        userID,
+		PublicKeyPem:  publicPEM,
+		PrivateKeyPem: privatePEM,
+	}); err != nil {
+		return database.ActorKey{}, err
+	}
+	return f.queries.GetActorKey(ctx, userID)
+}
+
+func (f *federation) signer(ctx context.Context, userID uuid.UUID) (activitypub.Signer, error) {
+	key, err := f.actorKey(ctx, userID)
+	if err != nil {
+		return activitypub.Signer{}, err
+	}
+	private, err := activitypub.ParsePrivateKey(key.PrivateKeyPem)
+	if err != nil {
+		return activitypub.Signer{}, err
+	}
+	return activitypub.Signer{KeyID: f.keyID(userID), Key: private}, nil
+}
+
+func (f *federation) actor(user database.User, key database.ActorKey) activitypub.Actor {
+	actorURL := f.actorURL(user.ID)
+	return activitypub.Actor{
+		Context:           activitypub.Context,
+		ID:                actorURL,
+		Type:              "Person",
+		PreferredUsername: user.Username.String,
+		Name:              user.Username.String,
+		URL:               f.baseURL + "/api/users/" + user.ID.String(),
+		Inbox:             actorURL + "/inbox",
+		Outbox:            actorURL + "/outbox",
+		Followers:         actorURL + "/followers",
+		Published:         activitypub.Time(user.CreatedAt),
+		PublicKey: activitypub.PublicKey{
+			ID:           f.keyID(user.ID),
+			Owner:        actorURL,
+			PublicKeyPem: key.PublicKeyPem,
+		},
+	}
+}
+
+// federated reports whether a chirp is sent to remote servers. Rechirps,
+// and chirps only some local users may see, stay on this server.
+func federated(chirp database.Chirp) bool {
+	if !chirp.Published || chirp.DeletedAt.Valid || chirp.RechirpOf.Valid {
+		return false
+	}
+	return chirp.Visibility == visibilityPublic || chirp.Visibility == visibilityUnlisted
+}
+
+func (f *federation) note(chirp database.Chirp) activitypub.Note {
+	actorURL := f.actorURL(chirp.UserID)
+	note := activitypub.Note{
+		ID:           f.noteURL(chirp.ID),
+		Type:         "Note",
+		AttributedTo: actorURL,
+		Content:      activitypub.NoteContent(chirp.Body),
+		URL:          f.baseURL + "/api/chirps/" + chirp.ID.String(),
+		Published:    activitypub.Time(chirp.CreatedAt),
+		To:           []string{activitypub.Public},
+		Cc:           []string{actorURL + "/followers"},
+	}
+	if chirp.Visibility == visibilityUnlisted {
+		note.To, note.Cc = note.Cc, note.To
+	}
+	if chirp.QuoteOf.Valid {
+		// Servers that don't understand quoteUrl show the link instead.
+		note.QuoteURL = f.noteURL(chirp.QuoteOf.UUID)
+		link := html.EscapeString(note.QuoteURL)
+		note.Content += `<p>RE: <a href="` + link + `">` + link + `</a></p>`
+	}
+	return note
+}
+
+func (f *federation) create(chirp database.Chirp) activitypub.Activity {
+	note := f.note(chirp)
+	return activitypub.Activity{
+		Context: activitypub.Context,
+		ID:      note.ID + "/activity",
+		Type:    "Create",
+		Actor:   note.AttributedTo,
+		Object:  note,
+		To:      note.To,
+		Cc:      note.Cc,
+	}
+}
+
+// chirpPublished sends a newly published chirp to its author's remote
+// followers.
+func (f *federation) chirpPublished(ctx context.Context, chirp database.Chirp) {
+	if f == nil || !federated(chirp) {
+		return
+	}
+	if err := f.deliverToFollowers(ctx, f.queries, chirp.UserID, f.create(chirp)); err != nil {
+		log.Printf("federating chirp %s: %v", chirp.ID, err)
+	}
+}
+
+// chirpDeleted tells the remote followers who were sent a chirp that it is
+// gone.
+func (f *federation) chirpDeleted(ctx context.Context, chirp database.Chirp) {
+	if f == nil || !federated(chirp) {
+		return
+	}
+	actorURL := f.actorURL(chirp.UserID)
+	id := f.noteURL(chirp.ID)
+	if err := f.deliverToFollowers(ctx, f.queries, chirp.UserID, activitypub.Activity{
+		Context: activitypub.Context,
+		ID:      id + "#delete",
+		Type:    "Delete",
+		Actor:   actorURL,
+		Object:  activitypub.Tombstone{ID: id, Type: "Tombstone"},
+		To:      []string{activitypub.Public},
+		Cc:      []string{actorURL + "/followers"},
+	}); err != nil {
+		log.Printf("federating deletion of chirp %s: %v", chirp.ID, err)
+	}
+}
+
+// accountDeleted tells the servers with followers of user that the account
+// is gone. It is queued through q, so it is only sent if the deletion
+// commits.
+func (f *federation) accountDeleted(ctx context.Context, q *database.Queries, user database.User) error {
+	if f == nil || !user.Username.Valid {
+		return nil
+	}
+	actorURL := f.actorURL(user.ID)
+	return f.deliverToFollowers(ctx, q, user.ID, activitypub.Activity{
+		Context: activitypub.Context,
+		ID:      actorURL + "#delete",
+		Type:    "Delete",
+		Actor:   actorURL,
+		Object:  actorURL,
+		To:      []string{activitypub.Public},
+	})
+}
+
+// deliverToFollowers queues activity for every server with a follower of
+// the user, once per server. q may be bound to a transaction.
+func (f *federation) deliverToFollowers(ctx context.Context, q *database.Queries, userID uuid.UUID, activity activitypub.Activity) error {
+	data, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	if err := q.EnqueueFollowerDeliveries(ctx, database.EnqueueFollowerDeliveriesParams{
+		UserID:   userID,
+		Activity: string(data),
+	}); err != nil {
+		return fmt.Errorf("EnqueueFollowerDeliveries: %w", err)
+	}
+	f.wakeUp()
+	return nil
+}
+
+// wakeUp starts delivering queued activities without waiting for the next
+// tick.
+func (f *federation) wakeUp() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// start delivers queued activities until ctx is cancelled.
+func (f *federation) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(deliveryInterval)
+		defer ticker.Stop()
+		for {
+			f.deliverDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-f.wake:
+			}
+		}
+	}()
+}
+
+// deliverDue claims due deliveries in batches and sends them, a few at a
+// time so one slow server doesn't hold up the rest.
+func (f *federation) deliverDue(ctx context.Context) {
+	for {
+		deliveries, err := f.queries.ClaimDueDeliveries(ctx, database.ClaimDueDeliveriesParams{
+			LeaseSeconds: deliveryLease.Seconds(),
+			BatchSize:    deliveryBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("ClaimDueDeliveries error: %v", err)
+			}
+			return
+		}
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, deliveryWorkers)
+		for _, d := range deliveries {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f.deliver(ctx, d)
+				<-slots
+			}()
+		}
+		wg.Wait()
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+func (f *federation) deliver(ctx context.Context, d database.ApDelivery) {
+	signer, err := f.signer(ctx, d.UserID)
+	if err == nil {
+		err = f.client.Deliver(ctx, d.Inbox, []byte(d.Activity), signer)
+	}
+	if err == nil || activitypub.IsPermanent(err) || d.Attempts+1 >= deliveryMaxAttempts {
+		if err != nil {
+			log.Printf("giving up delivering to %s: %v", d.Inbox, err)
+		}
+		if err := f.queries.DeleteDelivery(ctx, d.ID); err != nil {
+			log.Printf("DeleteDelivery error: %v", err)
+		}
+		return
+	}
+	if err := f.queries.RetryDelivery(ctx, database.RetryDeliveryParams{
+		DelaySeconds: (deliveryBaseDelay << d.Attempts).Seconds(),
+		LastError:    sql.NullString{String: err.Error(), Valid: true},
+		ID:           d.ID,
+	}); err != nil {
+		log.Printf("RetryDelivery error: %v", err)
+	}
+}
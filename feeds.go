@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/entities"
+	"github.com/odilmode/http/internal/feed"
+)
+
+const (
+	feedSize = 50
+	// feedTitleLength is the most characters of a chirp used as its item
+	// title.
+	feedTitleLength  = 80
+	feedCacheControl = "public, max-age=300"
+)
+
+// handleUserFeed godoc
+// @Summary      A user's chirps as a feed
+// @Description  The user's 50 newest public chirps as RSS 2.0 or Atom 1.0, for feed readers. Rechirps are left out. Responses carry an ETag, and requests sending it in If-None-Match get 304 Not Modified while the feed is unchanged.
+// @Tags         feeds
+// @Produce      application/rss+xml
+// @Produce      application/atom+xml
+// @Param        userID             path    string  true   "User ID"
+// @Param        If-None-Match      header  string  false  "ETag of a previous response"
+// @Success      200  {string}  string  "The feed"
+// @Success      304  "Not Modified"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /users/{userID}/feed.rss [get]
+// @Router       /users/{userID}/feed.atom [get]
+func (cfg *apiConfig) handleUserFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	user, err := cfg.dbQueries.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetUserByID error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feed")
+		return
+	}
+	chirps, err := cfg.dbQueries.GetUserFeedChirps(ctx, database.GetUserFeedChirpsParams{
+		UserID:    userID,
+		ViewerID:  uuid.Nil,
+		PageLimit: feedSize,
+	})
+	if err != nil {
+		log.Printf("GetUserFeedChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feed")
+		return
+	}
+
+	base := cfg.publicURL
+	author := feedAuthor(user.Username)
+	f := feed.Feed{
+		Title:       author + " on Chirpy",
+		Description: "Public chirps by " + author,
+		Link:        base + "/api/users/" + userID.String(),
+		SelfLink:    base + r.URL.Path,
+	}
+	f.ID = f.Link
+	for _, chirp := range chirps {
+		f.Items = append(f.Items, chirpFeedItem(base, chirp, author))
+	}
+	serveFeed(w, r, f)
+}
+
+// handleHashtagFeed godoc
+// @Summary      Chirps using a hashtag as a feed
+// @Description  The 50 newest public chirps using a hashtag as RSS 2.0 or Atom 1.0, for feed readers. The tag is matched case-insensitively. Rechirps are left out. Responses carry an ETag, and requests sending it in If-None-Match get 304 Not Modified while the feed is unchanged.
+// @Tags         feeds
+// @Produce      application/rss+xml
+// @Produce      application/atom+xml
+// @Param        tag                path    string  true   "Hashtag"
+// @Param        If-None-Match      header  string  false  "ETag of a previous response"
+// @Success      200  {string}  string  "The feed"
+// @Success      304  "Not Modified"
+// @Failure      400  {object}  ErrorResponse "Invalid hashtag"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /hashtags/{tag}/feed.rss [get]
+// @Router       /hashtags/{tag}/feed.atom [get]
+func (cfg *apiConfig) handleHashtagFeed(w http.ResponseWriter, r *http.Request) {
+	tag := entities.NormalizeTag(r.PathValue("tag"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid hashtag")
+		return
+	}
+	rows, err := cfg.dbQueries.GetHashtagFeedChirps(r.Context(), database.GetHashtagFeedChirpsParams{
+		Tag:       tag,
+		ViewerID:  uuid.Nil,
+		PageLimit: feedSize,
+	})
+	if err != nil {
+		log.Printf("GetHashtagFeedChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feed")
+		return
+	}
+
+	base := cfg.publicURL
+	f := feed.Feed{
+		Title:       "#" + tag + " on Chirpy",
+		Description: "Public chirps tagged #" + tag,
+		Link:        base + "/api/hashtags/" + url.PathEscape(tag) + "/chirps",
+		SelfLink:    base + r.URL.Path,
+	}
+	f.ID = f.Link
+	for _, row := range rows {
+		f.Items = append(f.Items, chirpFeedItem(base, row.Chirp, feedAuthor(row.Username)))
+	}
+	serveFeed(w, r, f)
+}
+
+func chirpFeedItem(base string, chirp database.Chirp, author string) feed.Item {
+	title := feed.Title(chirp.Body, feedTitleLength)
+	if title == "" {
+		title = "Chirp by " + author
+	}
+	content := chirp.Body
+	if chirp.QuoteOf.Valid {
+		content += "\n\nQuoting " + base + "/api/chirps/" + chirp.QuoteOf.UUID.String()
+	}
+	return feed.Item{
+		ID:        "urn:uuid:" + chirp.ID.String(),
+		Title:     title,
+		Link:      base + "/api/chirps/" + chirp.ID.String(),
+		Content:   content,
+		Author:    author,
+		Published: chirp.CreatedAt,
+		Updated:   chirp.UpdatedAt,
+	}
+}
+
+func feedAuthor(username sql.NullString) string {
+	if username.String == "" {
+		return "A Chirpy user"
+	}
+	return "@" + username.String
+}
+
+// serveFeed renders f in the format named by the request path's extension.
+// The ETag is a hash of the rendered feed, so it changes whenever the feed
+// does, deletions included; ServeContent answers conditional requests. No
+// Last-Modified is sent: the newest chirp's time doesn't move when a chirp
+// is deleted, so If-Modified-Since would keep getting 304.
+func serveFeed(w http.ResponseWriter, r *http.Request, f feed.Feed) {
+	for _, item := range f.Items {
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+	}
+	var body []byte
+	var err error
+	if path.Ext(r.URL.Path) == ".atom" {
+		body, err = f.Atom()
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	} else {
+		body, err = f.RSS()
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	}
+	if err != nil {
+		log.Printf("rendering feed: %v", err)
+		w.Header().Del("Content-Type")
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feed")
+		return
+	}
+	sum := sha256.Sum256(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", feedCacheControl)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
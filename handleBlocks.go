@@ -0,0 +1,324 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+// UserListPage is one page of the authenticated user's blocked or muted
+// users
+type UserListPage struct {
+	Users      []Profile `json:"users"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// handleBlock godoc
+// @Summary      Block a user
+// @Description  The authenticated user blocks another user. Neither can see the other's chirps, follow, mention or quote the other, and existing follows between them are removed. Blocking someone twice is a no-op.
+// @Tags         users
+// @Param        userID         path    string  true  "ID of the user to block"
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID or blocking yourself"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/block [post]
+func (cfg *apiConfig) handleBlock(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := cfg.parseRelationshipRequest(w, r, "block")
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	if _, err := qtx.CreateBlock(ctx, database.CreateBlockParams{
+		BlockerID: userID,
+		BlockedID: otherID,
+	}); err != nil {
+		log.Printf("CreateBlock error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user")
+		return
+	}
+	if err := qtx.DeleteFollowsBetween(ctx, database.DeleteFollowsBetweenParams{
+		UserID:  userID,
+		OtherID: otherID,
+	}); err != nil {
+		log.Printf("DeleteFollowsBetween error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user")
+		return
+	}
+	cfg.rebuildTimelineIfBuilt(ctx, userID)
+	cfg.rebuildTimelineIfBuilt(ctx, otherID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUnblock godoc
+// @Summary      Unblock a user
+// @Description  The authenticated user unblocks another user. Follows removed by the block are not restored.
+// @Tags         users
+// @Param        userID         path    string  true  "ID of the user to unblock"
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "User not blocked"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/block [delete]
+func (cfg *apiConfig) handleUnblock(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := cfg.parseRelationshipRequest(w, r, "")
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.dbQueries.DeleteBlock(r.Context(), database.DeleteBlockParams{
+		BlockerID: userID,
+		BlockedID: otherID,
+	})
+	if err != nil {
+		log.Printf("DeleteBlock error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unblock user")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "User not blocked")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMute godoc
+// @Summary      Mute a user
+// @Description  Hides another user's chirps from the authenticated user's timeline, the chirp list, hashtag pages and mentions. The muted user isn't told and can still see and follow the muter. Muting someone twice is a no-op.
+// @Tags         users
+// @Param        userID         path    string  true  "ID of the user to mute"
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID or muting yourself"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/mute [post]
+func (cfg *apiConfig) handleMute(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := cfg.parseRelationshipRequest(w, r, "mute")
+	if !ok {
+		return
+	}
+
+	if _, err := cfg.dbQueries.CreateMute(r.Context(), database.CreateMuteParams{
+		MuterID: userID,
+		MutedID: otherID,
+	}); err != nil {
+		log.Printf("CreateMute error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mute user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUnmute godoc
+// @Summary      Unmute a user
+// @Description  The authenticated user unmutes another user
+// @Tags         users
+// @Param        userID         path    string  true  "ID of the user to unmute"
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "User not muted"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/mute [delete]
+func (cfg *apiConfig) handleUnmute(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := cfg.parseRelationshipRequest(w, r, "")
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.dbQueries.DeleteMute(r.Context(), database.DeleteMuteParams{
+		MuterID: userID,
+		MutedID: otherID,
+	})
+	if err != nil {
+		log.Printf("DeleteMute error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unmute user")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "User not muted")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetBlocks godoc
+// @Summary      List blocked users
+// @Description  Users the authenticated user has blocked, most recently blocked first
+// @Tags         users
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  UserListPage
+// @Failure      400  {object}  ErrorResponse "Invalid pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/blocks [get]
+func (cfg *apiConfig) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.GetBlocks(r.Context(), database.GetBlocksParams{
+		UserID:          userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("GetBlocks error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch blocked users")
+		return
+	}
+
+	page := UserListPage{Users: []Profile{}}
+	for _, row := range rows {
+		page.Users = append(page.Users, Profile{
+			ID:          row.ID,
+			Username:    row.Username.String,
+			CreatedAt:   row.CreatedAt,
+			IsChirpyRed: row.IsChirpyRed,
+		})
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.BlockedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handleGetMutes godoc
+// @Summary      List muted users
+// @Description  Users the authenticated user has muted, most recently muted first
+// @Tags         users
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  UserListPage
+// @Failure      400  {object}  ErrorResponse "Invalid pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/mutes [get]
+func (cfg *apiConfig) handleGetMutes(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.GetMutes(r.Context(), database.GetMutesParams{
+		UserID:          userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("GetMutes error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch muted users")
+		return
+	}
+
+	page := UserListPage{Users: []Profile{}}
+	for _, row := range rows {
+		page.Users = append(page.Users, Profile{
+			ID:          row.ID,
+			Username:    row.Username.String,
+			CreatedAt:   row.CreatedAt,
+			IsChirpyRed: row.IsChirpyRed,
+		})
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.MutedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// parseRelationshipRequest authenticates the caller and reads the target
+// user ID shared by the block and mute endpoints, writing an error response
+// when either is invalid. When verb is set the target must be another
+// existing user, since creating the relationship needs one.
+func (cfg *apiConfig) parseRelationshipRequest(w http.ResponseWriter, r *http.Request, verb string) (uuid.UUID, uuid.UUID, bool) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	otherID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if verb == "" {
+		return userID, otherID, true
+	}
+	if otherID == userID {
+		respondWithError(w, http.StatusBadRequest, "You can't "+verb+" yourself")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), otherID); errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return uuid.Nil, uuid.Nil, false
+	} else if err != nil {
+		log.Printf("GetUserByID error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't "+verb+" user")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, otherID, true
+}
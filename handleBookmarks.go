@@ -0,0 +1,156 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+// handleBookmarkChirp godoc
+// @Summary      Bookmark a chirp
+// @Description  Saves a chirp to the authenticated user's bookmarks. Bookmarks are private. Bookmarking a chirp twice has no effect.
+// @Tags         bookmarks
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        chirpID        path    string  true  "Chirp ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Chirp not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/{chirpID}/bookmark [put]
+func (cfg *apiConfig) handleBookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	ctx := r.Context()
+	if _, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: chirpID, ViewerID: userID}); errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	} else if err != nil {
+		log.Printf("GetChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't bookmark chirp")
+		return
+	}
+	if err := cfg.dbQueries.CreateBookmark(ctx, database.CreateBookmarkParams{UserID: userID, ChirpID: chirpID}); err != nil {
+		log.Printf("CreateBookmark error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't bookmark chirp")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUnbookmarkChirp godoc
+// @Summary      Remove a bookmark
+// @Description  Removes a chirp from the authenticated user's bookmarks. Removing a bookmark that doesn't exist has no effect.
+// @Tags         bookmarks
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        chirpID        path    string  true  "Chirp ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/{chirpID}/bookmark [delete]
+func (cfg *apiConfig) handleUnbookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	if err := cfg.dbQueries.DeleteBookmark(r.Context(), database.DeleteBookmarkParams{UserID: userID, ChirpID: chirpID}); err != nil {
+		log.Printf("DeleteBookmark error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove bookmark")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetBookmarks godoc
+// @Summary      List bookmarks
+// @Description  The authenticated user's bookmarked chirps, most recently bookmarked first. Deleted chirps drop out of the list. Pass next_cursor back as cursor to get the following page.
+// @Tags         bookmarks
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  TimelinePage
+// @Failure      400  {object}  ErrorResponse "Invalid pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/bookmarks [get]
+func (cfg *apiConfig) handleGetBookmarks(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	rows, err := cfg.dbQueries.GetBookmarks(ctx, database.GetBookmarksParams{
+		UserID:          userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("GetBookmarks error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get bookmarks")
+		return
+	}
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	responseChirps, err := cfg.chirpResponses(ctx, userID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirps")
+		return
+	}
+	page := TimelinePage{Chirps: responseChirps}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.BookmarkedAt, last.Chirp.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
@@ -1,11 +1,15 @@
 package main
 import(
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/odilmode/http/internal/auth"
 	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/entities"
+	"github.com/lib/pq"
 	"time"
 )
 
@@ -14,6 +18,7 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Email string `json:"email"`
+	Username string `json:"username,omitempty"`
 	Password string `json:"-"`
 	IsChirpyRed bool `json:"is_chirpy_red"`
 }
@@ -22,16 +27,37 @@ type User struct {
 type createUserRequest struct {
 	Password string `json:"password"`
 	Email string `json:"email"`
+	// Username is optional; it lets other users @mention this user
+	Username string `json:"username,omitempty"`
+}
+
+// usernameParam validates an optional username from a request body and
+// converts it into a nullable database value.
+func usernameParam(username string) (sql.NullString, bool) {
+	if username == "" {
+		return sql.NullString{}, true
+	}
+	if !entities.ValidUsername(username) {
+		return sql.NullString{}, false
+	}
+	return sql.NullString{String: entities.NormalizeUsername(username), Valid: true}, true
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
 }
 // handleCreateUsers creates a new user in the system.
 // @Summary Create a new user
-// @Description Register a new user with email and password
+// @Description Register a new user with email, password and an optional username (letters, digits and underscores, up to 30 characters)
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param user body createUserRequest true "User credentials"
 // @Success 201 {object} User
 // @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "Email or username already taken"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/users [post]
 func (cfg *apiConfig) handleCreateUsers(w http.ResponseWriter, r *http.Request) {
@@ -43,6 +69,11 @@ func (cfg *apiConfig) handleCreateUsers(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Could not decode request body", http.StatusBadRequest)
 		return
 	}
+	username, ok := usernameParam(params.Username)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid username")
+		return
+	}
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to hash password")
@@ -51,7 +82,12 @@ func (cfg *apiConfig) handleCreateUsers(w http.ResponseWriter, r *http.Request)
 	user, err := cfg.dbQueries.CreateUser(r.Context(), database.CreateUserParams{
 		Email: params.Email,
 		HashedPassword: hashedPassword,
+		Username: username,
 	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email or username already taken")
+		return
+	}
 	if err != nil {
 		log.Printf("Error calling to database: %s", err)
 		http.Error(w, "Failed to create user in database", http.StatusInternalServerError)
@@ -62,6 +98,7 @@ func (cfg *apiConfig) handleCreateUsers(w http.ResponseWriter, r *http.Request)
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 		Email:	user.Email,
+		Username: user.Username.String,
 		IsChirpyRed: user.IsChirpyRed,
 	}
 	jsonData, err := json.Marshal(mainUser)
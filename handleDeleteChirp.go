@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"github.com/odilmode/http/internal/auth"
 	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/storage"
 )
 // handleDeleteChirp deletes a chirp by its ID if the requester is the author.
 // @Summary Delete a chirp
-// @Description Delete a chirp if the authenticated user is the author
+// @Description Delete a chirp if the authenticated user is the author. The chirp and its rechirps disappear immediately but can be restored with POST /api/chirps/{chirpID}/restore until the restore window (30 days by default) passes; then they are removed permanently.
 // @Tags Chirps
 // @Accept json
 // @Produce json
@@ -27,7 +31,7 @@ func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(accessToken, cfg.jwtSecret)
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
 		return
@@ -40,7 +44,7 @@ func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
 		return
 	}
-	chirp, err := cfg.dbQueries.GetChirp(ctx, id)
+	chirp, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: id, ViewerID: userID})
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Chirp not found")
 		return
@@ -50,10 +54,76 @@ func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusForbidden, "The user is not the author")
 		return
 	}
-	err = cfg.dbQueries.DeleteChirp(ctx, id)
+	tx, err := cfg.db.BeginTx(ctx, nil)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp")
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	// Rechirps of the chirp are deleted (and restored) along with it.
+	deleted, err := qtx.SoftDeleteChirp(ctx, id)
+	if err != nil {
+		log.Printf("SoftDeleteChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp")
+		return
+	}
+	// Drop the chirps from materialized timelines so pages stay full.
+	if err := qtx.DeleteTimelineEntriesForChirps(ctx, deleted); err != nil {
+		log.Printf("DeleteTimelineEntriesForChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp")
+		return
+	}
+	cfg.federation.chirpDeleted(ctx, chirp)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// chirpMediaKeys lists the storage keys of a chirp's attachments. Attachment
+// rows go with the chirp, so the keys must be read before deleting it and
+// the files removed with deleteMediaFiles afterwards.
+func (cfg *apiConfig) chirpMediaKeys(ctx context.Context, chirpID uuid.UUID) ([]string, error) {
+	media, err := cfg.dbQueries.GetMediaForChirps(ctx, []uuid.UUID{chirpID})
+	if err != nil {
+		return nil, err
+	}
+	return storedMediaKeys(ctx, cfg.dbQueries, media)
+}
+
+// storedMediaKeys lists every storage key used by media: the upload, the
+// processed image and its thumbnails.
+func storedMediaKeys(ctx context.Context, q *database.Queries, media []database.Medium) ([]string, error) {
+	if len(media) == 0 {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(media))
+	for i, m := range media {
+		ids[i] = m.ID
+	}
+	rows, err := q.GetMediaVariants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	variants := make(map[uuid.UUID][]database.MediaVariant, len(media))
+	for _, v := range rows {
+		variants[v.MediaID] = append(variants[v.MediaID], v)
+	}
+	var keys []string
+	for _, m := range media {
+		keys = append(keys, mediaKeys(m, variants[m.ID])...)
+	}
+	return keys, nil
+}
+
+// deleteMediaFiles removes media from storage. Failures are logged; the
+// files are orphaned but no longer referenced.
+func deleteMediaFiles(ctx context.Context, store storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("Couldn't delete media %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+// maxDraftBytes caps the size of a draft body. Drafts aren't held to the
+// chirp length limit, but they shouldn't be usable as free storage either.
+const maxDraftBytes = 16 << 10
+
+// Draft is an unfinished chirp
+// @Description A chirp saved for later. Drafts can be longer than a chirp; the length limit only applies when the draft is published.
+type Draft struct {
+	ID        uuid.UUID   `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Body      string      `json:"body"`
+	QuoteOf   *uuid.UUID  `json:"quote_of,omitempty"`
+	MediaIDs  []uuid.UUID `json:"media_ids"`
+	// Visibility is the visibility the chirp will be published with
+	Visibility string `json:"visibility" example:"public"`
+	// Length is the body's length as counted for the chirp length limit
+	Length int `json:"length"`
+}
+
+// draftRequest is the body for creating or replacing a draft
+type draftRequest struct {
+	Body     string      `json:"body"`
+	QuoteOf  *uuid.UUID  `json:"quote_of,omitempty"`
+	MediaIDs []uuid.UUID `json:"media_ids,omitempty"`
+	// Visibility is public (default), unlisted, followers or mentioned
+	Visibility string `json:"visibility,omitempty" example:"public"`
+}
+
+// publishDraftRequest is the optional body for publishing a draft
+type publishDraftRequest struct {
+	// PublishAt optionally schedules the chirp instead of publishing it now
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+func draftResponse(d database.Draft) Draft {
+	resp := Draft{
+		ID:         d.ID,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+		Body:       d.Body,
+		MediaIDs:   d.MediaIds,
+		Visibility: d.Visibility,
+		Length:     textlength.Count(d.Body),
+	}
+	if d.QuoteOf.Valid {
+		resp.QuoteOf = &d.QuoteOf.UUID
+	}
+	if resp.MediaIDs == nil {
+		resp.MediaIDs = []uuid.UUID{}
+	}
+	return resp
+}
+
+// validateDraft checks the limits that apply to a draft before it is
+// published and fills in the default visibility. Quotes and media are
+// checked on publish.
+func validateDraft(params *draftRequest) error {
+	if len(params.Body) > maxDraftBytes {
+		return errors.New("Draft is too long")
+	}
+	if len(params.MediaIDs) > maxChirpMedia {
+		return errors.New("Too many attachments")
+	}
+	visibility, err := normalizeVisibility(params.Visibility)
+	if err != nil {
+		return err
+	}
+	params.Visibility = visibility
+	// media_ids is NOT NULL, and pq.Array sends a nil slice as NULL.
+	if params.MediaIDs == nil {
+		params.MediaIDs = []uuid.UUID{}
+	}
+	return nil
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+// handleCreateDraft godoc
+// @Summary      Save a draft
+// @Description  Saves an unfinished chirp. The body isn't held to the chirp length limit until the draft is published.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string        true  "Bearer JWT token"
+// @Param        draft          body    draftRequest  true  "Draft content"
+// @Success      201  {object}  Draft
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/drafts [post]
+func (cfg *apiConfig) handleCreateDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	var params draftRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	if err := validateDraft(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	draft, err := cfg.dbQueries.CreateDraft(r.Context(), database.CreateDraftParams{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Body:       params.Body,
+		QuoteOf:    nullUUID(params.QuoteOf),
+		MediaIds:   params.MediaIDs,
+		Visibility: params.Visibility,
+	})
+	if err != nil {
+		log.Printf("CreateDraft error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save draft")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, draftResponse(draft))
+}
+
+// handleGetDrafts godoc
+// @Summary      List drafts
+// @Description  Lists the caller's drafts, most recently edited first
+// @Tags         drafts
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      200  {array}   Draft
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/drafts [get]
+func (cfg *apiConfig) handleGetDrafts(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	drafts, err := cfg.dbQueries.GetDrafts(r.Context(), userID)
+	if err != nil {
+		log.Printf("GetDrafts error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get drafts")
+		return
+	}
+	resp := make([]Draft, 0, len(drafts))
+	for _, d := range drafts {
+		resp = append(resp, draftResponse(d))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleGetDraft godoc
+// @Summary      Get a draft
+// @Description  Returns one of the caller's drafts
+// @Tags         drafts
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        draftID        path    string  true  "Draft ID"
+// @Success      200  {object}  Draft
+// @Failure      400  {object}  ErrorResponse "Invalid draft ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Draft not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/drafts/{draftID} [get]
+func (cfg *apiConfig) handleGetDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID")
+		return
+	}
+	draft, err := cfg.dbQueries.GetDraft(r.Context(), database.GetDraftParams{ID: draftID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetDraft error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get draft")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, draftResponse(draft))
+}
+
+// handleUpdateDraft godoc
+// @Summary      Update a draft
+// @Description  Replaces the content of one of the caller's drafts
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string        true  "Bearer JWT token"
+// @Param        draftID        path    string        true  "Draft ID"
+// @Param        draft          body    draftRequest  true  "Draft content"
+// @Success      200  {object}  Draft
+// @Failure      400  {object}  ErrorResponse "Invalid draft ID or request"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Draft not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/drafts/{draftID} [put]
+func (cfg *apiConfig) handleUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID")
+		return
+	}
+	var params draftRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	if err := validateDraft(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	draft, err := cfg.dbQueries.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		ID:         draftID,
+		UserID:     userID,
+		Body:       params.Body,
+		QuoteOf:    nullUUID(params.QuoteOf),
+		MediaIds:   params.MediaIDs,
+		Visibility: params.Visibility,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+	if err != nil {
+		log.Printf("UpdateDraft error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update draft")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, draftResponse(draft))
+}
+
+// handleDeleteDraft godoc
+// @Summary      Delete a draft
+// @Description  Discards one of the caller's drafts
+// @Tags         drafts
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        draftID        path    string  true  "Draft ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid draft ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Draft not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/drafts/{draftID} [delete]
+func (cfg *apiConfig) handleDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID")
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteDraft(r.Context(), database.DeleteDraftParams{ID: draftID, UserID: userID})
+	if err != nil {
+		log.Printf("DeleteDraft error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete draft")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePublishDraft godoc
+// @Summary      Publish a draft
+// @Description  Turns one of the caller's drafts into a chirp. The draft goes through the same length, attachment and moderation checks as POST /api/chirps; if it passes, the chirp is created and the draft deleted in one step, so a draft can't be published twice. Set publish_at to schedule the chirp instead.
+// @Tags         drafts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string               true   "Bearer JWT token"
+// @Param        draftID        path    string               true   "Draft ID"
+// @Param        options        body    publishDraftRequest  false  "Publish options"
+// @Success      201  {object}  Chirp
+// @Failure      400  {object}  ErrorResponse "Invalid draft ID or the draft isn't a valid chirp"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Account suspended"
+// @Failure      404  {object}  ErrorResponse "Draft not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/drafts/{draftID}/publish [post]
+func (cfg *apiConfig) handlePublishDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID")
+		return
+	}
+	var params publishDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+
+	ctx := r.Context()
+	user, err := cfg.dbQueries.GetUserByID(ctx, userID)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	// Deleting the draft first locks it, so a concurrent publish of the
+	// same draft waits and then finds nothing to publish.
+	draft, err := qtx.TakeDraft(ctx, database.TakeDraftParams{ID: draftID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found")
+		return
+	}
+	if err != nil {
+		log.Printf("TakeDraft error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft")
+		return
+	}
+	in := chirpInput{
+		Body:       draft.Body,
+		MediaIDs:   draft.MediaIds,
+		PublishAt:  params.PublishAt,
+		Visibility: draft.Visibility,
+	}
+	if draft.QuoteOf.Valid {
+		in.QuoteOf = &draft.QuoteOf.UUID
+	}
+	chirp, err := cfg.createChirp(ctx, qtx, user, in)
+	var invalid *invalidChirpError
+	if errors.As(err, &invalid) {
+		respondWithError(w, http.StatusBadRequest, invalid.Error())
+		return
+	}
+	if errors.Is(err, errAuthorSuspended) {
+		respondWithError(w, http.StatusForbidden, "Account suspended")
+		return
+	}
+	if err != nil {
+		log.Printf("createChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft")
+		return
+	}
+	if chirp.Published {
+		cfg.timelines.chirpCreated(ctx, chirp)
+		cfg.notifications.chirpPublished(ctx, chirp)
+		cfg.federation.chirpPublished(ctx, chirp)
+	}
+
+	resp, err := cfg.chirpResponse(ctx, userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp)
+}
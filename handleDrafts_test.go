@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+	"github.com/lib/pq"
+)
+
+func TestValidateDraftWithoutMedia(t *testing.T) {
+	params := draftRequest{Body: "just text"}
+	if err := validateDraft(&params); err != nil {
+		t.Fatalf("validateDraft() error = %v", err)
+	}
+	// The value saved into drafts.media_ids must be an empty array, not NULL.
+	value, err := pq.Array(params.MediaIDs).Value()
+	if err != nil {
+		t.Fatalf("encoding media IDs: %v", err)
+	}
+	if value != "{}" {
+		t.Errorf("media_ids is saved as %#v, want {}", value)
+	}
+}
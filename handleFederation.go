@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/activitypub"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/httpsig"
+)
+
+const (
+	// apMaxClockSkew is how far the Date of a signed request may be from
+	// now, which bounds how long a captured request can be replayed.
+	apMaxClockSkew    = time.Hour
+	apMaxActivitySize = 1 << 20
+	apOutboxSize      = 20
+
+	// apActorRefetchInterval is how long after fetching an actor a request
+	// that doesn't verify against its cached key is rejected outright,
+	// rather than refetching the actor in case the key was rotated.
+	apActorRefetchInterval = 10 * time.Minute
+)
+
+// respondWithActivity writes an ActivityPub or WebFinger document.
+func respondWithActivity(w http.ResponseWriter, contentType string, payload any) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("encoding %s document: %v", contentType, err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't encode document")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(response)
+}
+
+// handleWebFinger godoc
+// @Summary      Look up a user by fediverse address
+// @Description  WebFinger lookup of acct:username@host, how Mastodon and other fediverse servers find a user's ActivityPub actor. Only available when federation is enabled with PUBLIC_URL.
+// @Tags         federation
+// @Produce      application/jrd+json
+// @Param        resource  query     string  true  "acct:username@host, or the actor URL"
+// @Success      200       {object}  activitypub.JRD
+// @Failure      400       {object}  ErrorResponse "Invalid resource"
+// @Failure      404       {object}  ErrorResponse "User not found"
+// @Router       /.well-known/webfinger [get]
+func (cfg *apiConfig) handleWebFinger(w http.ResponseWriter, r *http.Request) {
+	f := cfg.federation
+	ctx := r.Context()
+	resource := r.URL.Query().Get("resource")
+
+	var user database.User
+	var err error
+	if userID, ok := strings.CutPrefix(resource, f.baseURL+"/ap/users/"); ok {
+		user, err = f.localUser(ctx, userID)
+	} else {
+		username, host, parseErr := activitypub.ParseAccount(resource)
+		if parseErr != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid resource")
+			return
+		}
+		if !strings.EqualFold(host, f.host) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		user, err = cfg.dbQueries.GetUserByUsername(ctx, username)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		log.Printf("webfinger lookup error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't look up user")
+		return
+	}
+
+	actorURL := f.actorURL(user.ID)
+	profileURL := f.baseURL + "/api/users/" + user.ID.String()
+	respondWithActivity(w, "application/jrd+json", activitypub.JRD{
+		Subject: "acct:" + user.Username.String + "@" + f.host,
+		Aliases: []string{actorURL, profileURL},
+		Links: []activitypub.Link{
+			{Rel: "self", Type: activitypub.ContentType, Href: actorURL},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "application/json", Href: profileURL},
+		},
+	})
+}
+
+// handleActor godoc
+// @Summary      A user's ActivityPub actor
+// @Description  The user as an ActivityPub Person, with the public key their activities are signed with. Users without a username aren't actors. Only available when federation is enabled with PUBLIC_URL.
+// @Tags         federation
+// @Produce      application/activity+json
+// @Param        userID  path      string  true  "User ID"
+// @Success      200     {object}  activitypub.Actor
+// @Failure      404     {object}  ErrorResponse "User not found"
+// @Failure      500     {object}  ErrorResponse "Internal server error"
+// @Router       /ap/users/{userID} [get]
+func (cfg *apiConfig) handleActor(w http.ResponseWriter, r *http.Request) {
+	f := cfg.federation
+	ctx := r.Context()
+	user, ok := cfg.federatedUser(w, r)
+	if !ok {
+		return
+	}
+	key, err := f.actorKey(ctx, user.ID)
+	if err != nil {
+		log.Printf("actorKey error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load actor")
+		return
+	}
+	respondWithActivity(w, activitypub.ContentType, f.actor(user, key))
+}
+
+// handleOutbox godoc
+// @Summary      A user's ActivityPub outbox
+// @Description  The user's 20 newest public chirps as Create activities. Only available when federation is enabled with PUBLIC_URL.
+// @Tags         federation
+// @Produce      application/activity+json
+// @Param        userID  path      string  true  "User ID"
+// @Success      200     {object}  activitypub.OrderedCollection
+// @Failure      404     {object}  ErrorResponse "User not found"
+// @Failure      500     {object}  ErrorResponse "Internal server error"
+// @Router       /ap/users/{userID}/outbox [get]
+func (cfg *apiConfig) handleOutbox(w http.ResponseWriter, r *http.Request) {
+	f := cfg.federation
+	user, ok := cfg.federatedUser(w, r)
+	if !ok {
+		return
+	}
+	chirps, err := cfg.dbQueries.GetUserFeedChirps(r.Context(), database.GetUserFeedChirpsParams{
+		UserID:    user.ID,
+		ViewerID:  uuid.Nil,
+		PageLimit: apOutboxSize,
+	})
+	if err != nil {
+		log.Printf("GetUserFeedChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load outbox")
+		return
+	}
+	outbox := activitypub.OrderedCollection{
+		Context:      activitypub.Context,
+		ID:           f.actorURL(user.ID) + "/outbox",
+		Type:         "OrderedCollection",
+		OrderedItems: []any{},
+	}
+	for _, chirp := range chirps {
+		create := f.create(chirp)
+		create.Context = nil
+		outbox.OrderedItems = append(outbox.OrderedItems, create)
+	}
+	respondWithActivity(w, activitypub.ContentType, outbox)
+}
+
+// handleFollowers godoc
+// @Summary      A user's ActivityPub followers
+// @Description  How many followers the user has, on this server and others. The followers themselves aren't listed. Only available when federation is enabled with PUBLIC_URL.
+// @Tags         federation
+// @Produce      application/activity+json
+// @Param        userID  path      string  true  "User ID"
+// @Success      200     {object}  activitypub.OrderedCollection
+// @Failure      404     {object}  ErrorResponse "User not found"
+// @Failure      500     {object}  ErrorResponse "Internal server error"
+// @Router       /ap/users/{userID}/followers [get]
+func (cfg *apiConfig) handleFollowers(w http.ResponseWriter, r *http.Request) {
+	f := cfg.federation
+	ctx := r.Context()
+	user, ok := cfg.federatedUser(w, r)
+	if !ok {
+		return
+	}
+	local, err := cfg.dbQueries.CountFollowers(ctx, user.ID)
+	if err != nil {
+		log.Printf("CountFollowers error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load followers")
+		return
+	}
+	remote, err := cfg.dbQueries.CountRemoteFollowers(ctx, user.ID)
+	if err != nil {
+		log.Printf("CountRemoteFollowers error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load followers")
+		return
+	}
+	total := local + remote
+	respondWithActivity(w, activitypub.ContentType, activitypub.OrderedCollection{
+		Context:    activitypub.Context,
+		ID:         f.actorURL(user.ID) + "/followers",
+		Type:       "OrderedCollection",
+		TotalItems: &total,
+	})
+}
+
+// handleNote godoc
+// @Summary      A chirp as an ActivityPub note
+// @Description  A public or unlisted chirp as an ActivityPub Note, for remote servers resolving it. Only available when federation is enabled with PUBLIC_URL.
+// @Tags         federation
+// @Produce      application/activity+json
+// @Param        chirpID  path      string  true  "Chirp ID"
+// @Success      200      {object}  activitypub.Note
+// @Failure      404      {object}  ErrorResponse "Chirp not found"
+// @Failure      500      {object}  ErrorResponse "Internal server error"
+// @Router       /ap/chirps/{chirpID} [get]
+func (cfg *apiConfig) handleNote(w http.ResponseWriter, r *http.Request) {
+	f := cfg.federation
+	ctx := r.Context()
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	chirp, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: id, ViewerID: uuid.Nil})
+	if err == nil && federated(chirp) {
+		_, err = f.localUser(ctx, chirp.UserID.String())
+	} else if err == nil {
+		err = sql.ErrNoRows
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("loading note error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp")
+		return
+	}
+	note := f.note(chirp)
+	note.Context = activitypub.Context
+	respondWithActivity(w, activitypub.ContentType, note)
+}
+
+// handleInbox godoc
+// @Summary      Receive ActivityPub activities
+// @Description  Where remote servers send activities for a user. Requests must carry an HTTP Signature (rsa-sha256 over (request-target), host, date and digest) by the activity's actor. Follow activities are accepted automatically and Undo of a Follow removes the follower; other activities are acknowledged and ignored. Only available when federation is enabled with PUBLIC_URL.
+// @Tags         federation
+// @Accept       application/activity+json
+// @Param        userID     path    string  true  "User ID"
+// @Param        Signature  header  string  true  "HTTP Signature"
+// @Success      202        "Accepted"
+// @Failure      400        {object}  ErrorResponse "Invalid activity"
+// @Failure      401        {object}  ErrorResponse "Missing or invalid signature"
+// @Failure      404        {object}  ErrorResponse "User not found"
+// @Failure      413        {object}  ErrorResponse "Activity too large"
+// @Failure      500        {object}  ErrorResponse "Internal server error"
+// @Router       /ap/users/{userID}/inbox [post]
+func (cfg *apiConfig) handleInbox(w http.ResponseWriter, r *http.Request) {
+	f := cfg.federation
+	ctx := r.Context()
+	user, ok := cfg.federatedUser(w, r)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, apMaxActivitySize))
+	if err != nil {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Activity too large")
+		return
+	}
+	var activity activitypub.Activity
+	if err := json.Unmarshal(body, &activity); err != nil || activity.Type == "" || activity.Actor == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid activity")
+		return
+	}
+
+	signer, err := f.signer(ctx, user.ID)
+	if err != nil {
+		log.Printf("signer error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't process activity")
+		return
+	}
+	actor, err := f.verify(ctx, r, body, signer)
+	if errors.Is(err, errUnknownActor) && activity.Type == "Delete" && activity.ObjectID() == activity.Actor {
+		// A deleted account we never heard from; its key can't be fetched
+		// any more and there is nothing to clean up.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		log.Printf("rejecting activity for %s: %v", user.ID, err)
+		respondWithError(w, http.StatusUnauthorized, "Missing or invalid signature")
+		return
+	}
+	if activity.Actor != actor.ID {
+		respondWithError(w, http.StatusUnauthorized, "Activity isn't signed by its actor")
+		return
+	}
+
+	switch activity.Type {
+	case "Follow":
+		err = f.follow(ctx, user, actor, activity)
+	case "Undo":
+		err = f.undo(ctx, user, actor, activity)
+	case "Delete":
+		if activity.ObjectID() == actor.ID {
+			err = f.queries.DeleteRemoteActor(ctx, actor.ID)
+		}
+	}
+	if err != nil {
+		log.Printf("processing %s activity error: %v", activity.Type, err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't process activity")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// federatedUser loads the user named by the request path, writing a 404 if
+// they aren't an actor. ok is false when the error response was written.
+func (cfg *apiConfig) federatedUser(w http.ResponseWriter, r *http.Request) (user database.User, ok bool) {
+	user, err := cfg.federation.localUser(r.Context(), r.PathValue("userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return user, false
+	}
+	if err != nil {
+		log.Printf("GetUserByID error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load user")
+		return user, false
+	}
+	return user, true
+}
+
+// errUnknownActor is returned by verify when the signing actor isn't cached
+// and can't be fetched.
+var errUnknownActor = errors.New("unknown actor")
+
+// verify checks the request's signature and returns the remote actor that
+// made it. The actor's key is cached; if the signature doesn't verify with
+// the cached key the actor is fetched again once, in case they changed it.
+func (f *federation) verify(ctx context.Context, r *http.Request, body []byte, signer activitypub.Signer) (database.RemoteActor, error) {
+	keyID, err := httpsig.KeyID(r)
+	if err != nil {
+		return database.RemoteActor{}, err
+	}
+	actor, err := f.queries.GetRemoteActorByKeyID(ctx, keyID)
+	if err == nil {
+		err := verifyWith(r, body, actor.PublicKeyPem)
+		if err == nil {
+			return actor, nil
+		}
+		// Otherwise anyone could make us fetch the actor once per request
+		// they send.
+		if time.Since(actor.FetchedAt) < apActorRefetchInterval {
+			return database.RemoteActor{}, err
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return database.RemoteActor{}, fmt.Errorf("GetRemoteActorByKeyID: %w", err)
+	}
+
+	actorURL, _, _ := strings.Cut(keyID, "#")
+	remote, err := f.client.FetchActor(ctx, actorURL, signer)
+	if err != nil {
+		return database.RemoteActor{}, fmt.Errorf("%w: %v", errUnknownActor, err)
+	}
+	if remote.PublicKey.ID != keyID {
+		return database.RemoteActor{}, fmt.Errorf("key %s doesn't belong to %s", keyID, remote.ID)
+	}
+	// The actor is stored whether or not the request verifies, so its
+	// fetched_at limits the next refetch.
+	actor, err = f.queries.UpsertRemoteActor(ctx, database.UpsertRemoteActorParams{
+		ID:           remote.ID,
+		KeyID:        remote.PublicKey.ID,
+		PublicKeyPem: remote.PublicKey.PublicKeyPem,
+		Inbox:        remote.Inbox,
+		SharedInbox:  remote.SharedInbox(),
+		Username:     remote.PreferredUsername,
+	})
+	if err != nil {
+		return database.RemoteActor{}, fmt.Errorf("UpsertRemoteActor: %w", err)
+	}
+	if err := verifyWith(r, body, actor.PublicKeyPem); err != nil {
+		return database.RemoteActor{}, err
+	}
+	return actor, nil
+}
+
+func verifyWith(r *http.Request, body []byte, publicPEM string) error {
+	pub, err := activitypub.ParsePublicKey(publicPEM)
+	if err != nil {
+		return err
+	}
+	return httpsig.Verify(r, body, pub, apMaxClockSkew)
+}
+
+// follow records a remote follower of user and queues the Accept.
+func (f *federation) follow(ctx context.Context, user database.User, actor database.RemoteActor, follow activitypub.Activity) error {
+	actorURL := f.actorURL(user.ID)
+	if follow.ObjectID() != actorURL {
+		return nil
+	}
+	accept, err := json.Marshal(activitypub.Activity{
+		Context: activitypub.Context,
+		ID:      actorURL + "#accepts/" + uuid.NewString(),
+		Type:    "Accept",
+		Actor:   actorURL,
+		Object: activitypub.Activity{
+			ID:     follow.ID,
+			Type:   follow.Type,
+			Actor:  follow.Actor,
+			Object: actorURL,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := f.queries.WithTx(tx)
+	if err := qtx.CreateRemoteFollow(ctx, database.CreateRemoteFollowParams{
+		UserID:     user.ID,
+		ActorID:    actor.ID,
+		ActivityID: follow.ID,
+	}); err != nil {
+		return fmt.Errorf("CreateRemoteFollow: %w", err)
+	}
+	if err := qtx.EnqueueDelivery(ctx, database.EnqueueDeliveryParams{
+		UserID:   user.ID,
+		Inbox:    actor.Inbox,
+		Activity: string(accept),
+	}); err != nil {
+		return fmt.Errorf("EnqueueDelivery: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	f.wakeUp()
+	return nil
+}
+
+// undo removes a remote follower when they undo their Follow. Other undone
+// activities were never acted on.
+func (f *federation) undo(ctx context.Context, user database.User, actor database.RemoteActor, undo activitypub.Activity) error {
+	follow, err := undo.ObjectActivity()
+	if err != nil || follow.Type != "Follow" || follow.Actor != actor.ID || follow.ObjectID() != f.actorURL(user.ID) {
+		return nil
+	}
+	return f.queries.DeleteRemoteFollow(ctx, database.DeleteRemoteFollowParams{
+		UserID:  user.ID,
+		ActorID: actor.ID,
+	})
+}
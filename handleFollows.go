@@ -0,0 +1,274 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+// Profile is the public view of a user
+// @Description A user as seen by other users
+type Profile struct {
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+}
+
+// FollowListPage is one page of a follower or following list
+type FollowListPage struct {
+	// Count is the total number of users in the list
+	Count      int64     `json:"count"`
+	Users      []Profile `json:"users"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// handleFollow godoc
+// @Summary      Follow a user
+// @Description  The authenticated user starts following another user. Following someone twice is a no-op.
+// @Tags         users
+// @Produce      json
+// @Param        userID         path    string  true  "ID of the user to follow"
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID or following yourself"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Either user has blocked the other"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/follow [post]
+func (cfg *apiConfig) handleFollow(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	ctx := r.Context()
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if followeeID == userID {
+		respondWithError(w, http.StatusBadRequest, "You can't follow yourself")
+		return
+	}
+	if _, err := cfg.dbQueries.GetUserByID(ctx, followeeID); err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	blocked, err := cfg.dbQueries.UsersBlocked(ctx, database.UsersBlockedParams{
+		UserID:  userID,
+		OtherID: followeeID,
+	})
+	if err != nil {
+		log.Printf("UsersBlocked error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't follow user")
+		return
+	}
+	if blocked {
+		respondWithError(w, http.StatusForbidden, "You can't follow this user")
+		return
+	}
+
+	created, err := cfg.dbQueries.CreateFollow(ctx, database.CreateFollowParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		log.Printf("CreateFollow error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't follow user")
+		return
+	}
+	if created > 0 {
+		cfg.notifications.followed(ctx, userID, followeeID)
+	}
+	cfg.rebuildTimelineIfBuilt(ctx, userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUnfollow godoc
+// @Summary      Unfollow a user
+// @Description  The authenticated user stops following another user
+// @Tags         users
+// @Produce      json
+// @Param        userID         path    string  true  "ID of the user to unfollow"
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Not following user"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/follow [delete]
+func (cfg *apiConfig) handleUnfollow(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	deleted, err := cfg.dbQueries.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		log.Printf("DeleteFollow error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unfollow user")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Not following user")
+		return
+	}
+	cfg.rebuildTimelineIfBuilt(r.Context(), userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetFollowers godoc
+// @Summary      List followers
+// @Description  Users following the given user, most recent first
+// @Tags         users
+// @Produce      json
+// @Param        userID  path   string  true   "User ID"
+// @Param        limit   query  int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query  string  false  "Cursor from a previous page"
+// @Success      200  {object}  FollowListPage
+// @Failure      400  {object}  ErrorResponse "Invalid user ID or pagination parameters"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/followers [get]
+func (cfg *apiConfig) handleGetFollowers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, limit, cursor, ok := cfg.parseFollowListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := cfg.dbQueries.CountFollowers(ctx, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch followers")
+		return
+	}
+	rows, err := cfg.dbQueries.GetFollowers(ctx, database.GetFollowersParams{
+		UserID:          userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("GetFollowers error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch followers")
+		return
+	}
+
+	page := FollowListPage{Count: count, Users: []Profile{}}
+	for _, row := range rows {
+		page.Users = append(page.Users, Profile{
+			ID:          row.ID,
+			Username:    row.Username.String,
+			CreatedAt:   row.CreatedAt,
+			IsChirpyRed: row.IsChirpyRed,
+		})
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.FollowedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handleGetFollowing godoc
+// @Summary      List followed users
+// @Description  Users the given user follows, most recent first
+// @Tags         users
+// @Produce      json
+// @Param        userID  path   string  true   "User ID"
+// @Param        limit   query  int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query  string  false  "Cursor from a previous page"
+// @Success      200  {object}  FollowListPage
+// @Failure      400  {object}  ErrorResponse "Invalid user ID or pagination parameters"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID}/following [get]
+func (cfg *apiConfig) handleGetFollowing(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, limit, cursor, ok := cfg.parseFollowListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := cfg.dbQueries.CountFollowing(ctx, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch followed users")
+		return
+	}
+	rows, err := cfg.dbQueries.GetFollowing(ctx, database.GetFollowingParams{
+		UserID:          userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("GetFollowing error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch followed users")
+		return
+	}
+
+	page := FollowListPage{Count: count, Users: []Profile{}}
+	for _, row := range rows {
+		page.Users = append(page.Users, Profile{
+			ID:          row.ID,
+			Username:    row.Username.String,
+			CreatedAt:   row.CreatedAt,
+			IsChirpyRed: row.IsChirpyRed,
+		})
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.FollowedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// parseFollowListRequest validates the user ID and pagination parameters
+// shared by the follower and following lists, writing an error response
+// when they are invalid.
+func (cfg *apiConfig) parseFollowListRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, int32, pageCursor, bool) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, 0, pageCursor{}, false
+	}
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return uuid.Nil, 0, pageCursor{}, false
+	}
+	if _, err := cfg.dbQueries.GetUserByID(r.Context(), userID); err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return uuid.Nil, 0, pageCursor{}, false
+	}
+	return userID, limit, cursor, true
+}
@@ -6,38 +6,44 @@ import (
 	"encoding/json"
 	"net/http"
 	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
 )
 // handleGetChirp returns a single chirp by ID.
 // @Summary Get a chirp
-// @Description Retrieve a chirp by its ID
+// @Description Retrieve a chirp by its ID. Authentication is optional; chirps the caller may not see are reported as not found.
 // @Tags Chirps
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer JWT token"
 // @Param chirpID path string true "Chirp ID"
 // @Success 200 {object} Chirp
 // @Failure 400 {object} map[string]string "Invalid chirp ID"
+// @Failure 401 {object} map[string]string "Invalid token"
 // @Failure 404 {object} map[string]string "Chirp not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/chirps/{chirpID} [get]
 func (cfg *apiConfig) handleGetChirp(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	viewerID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
 	chirpID := r.PathValue("chirpID")
 	id, err := uuid.Parse(chirpID)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
 		return
 	}
-	chirp, err := cfg.dbQueries.GetChirp(ctx, id)
+	chirp, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: id, ViewerID: viewerID})
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Chirp not found")
 		return
 	}
-	responseChirp := Chirp{
-		ID: chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:	chirp.Body,
-		UserID: chirp.UserID,
+	responseChirp, err := cfg.chirpResponse(ctx, viewerID, chirp)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirp")
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -9,18 +9,24 @@ import (
 )
 // handleGetChirps godoc
 // @Summary      Get Chirps
-// @Description  Retrieve chirps, optionally filtered by author_id and sorted by creation time
+// @Description  Retrieve chirps, optionally filtered by author_id and sorted by creation time. Authentication is optional; only chirps the caller may see are returned. Unlisted chirps are only listed when filtering by author_id.
 // @Tags         chirps
 // @Accept       json
 // @Produce      json
+// @Param        Authorization  header  string  false  "Bearer JWT token"
 // @Param        author_id  query     string  false  "Filter chirps by author UUID"
 // @Param        sort       query     string  false  "Sort order: asc (default) or desc"
 // @Success      200        {array}   Chirp
 // @Failure      400        {object}  ErrorResponse "Invalid author_id"
+// @Failure      401        {object}  ErrorResponse "Invalid token"
 // @Failure      500        {object}  ErrorResponse "Failed to fetch chirps or encode response"
 // @Router       /api/chirps [get]
 func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	viewerID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
 	s := r.URL.Query().Get("author_id")
 	var chirps []database.Chirp
 	var err error
@@ -30,13 +36,13 @@ func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusBadRequest, "Invalid author_id")
 			return
 		}
-		chirps, err = cfg.dbQueries.GetChirpsByAuthor(ctx, authorID)
+		chirps, err = cfg.dbQueries.GetChirpsByAuthor(ctx, database.GetChirpsByAuthorParams{UserID: authorID, ViewerID: viewerID})
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
 			return
 		}
 	} else {
-		chirps, err = cfg.dbQueries.GetAllChirps(ctx)
+		chirps, err = cfg.dbQueries.GetAllChirps(ctx, viewerID)
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
 			return
@@ -54,15 +60,11 @@ func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	responseChirps := []Chirp{}
-	for _, c := range chirps {
-		responseChirps = append(responseChirps, Chirp{
-			ID: c.ID,
-			CreatedAt: c.CreatedAt,
-			UpdatedAt: c.UpdatedAt,
-			Body: c.Body,
-			UserID: c.UserID,
-		})
+	responseChirps, err := cfg.chirpResponses(ctx, viewerID, chirps)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/entities"
+)
+
+// handleGetHashtagChirps godoc
+// @Summary      Get chirps by hashtag
+// @Description  Retrieve chirps that use a hashtag, newest first. The tag is matched case-insensitively and may include the leading '#'. Authentication is optional; only public chirps and those the caller may see are returned.
+// @Tags         chirps
+// @Produce      json
+// @Param        Authorization  header  string  false  "Bearer JWT token"
+// @Param        tag  path      string  true  "Hashtag"
+// @Success      200  {array}   Chirp
+// @Failure      400  {object}  ErrorResponse "Invalid hashtag"
+// @Failure      401  {object}  ErrorResponse "Invalid token"
+// @Failure      500  {object}  ErrorResponse "Failed to fetch chirps"
+// @Router       /api/hashtags/{tag}/chirps [get]
+func (cfg *apiConfig) handleGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	viewerID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
+	tag := entities.NormalizeTag(r.PathValue("tag"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid hashtag")
+		return
+	}
+
+	chirps, err := cfg.dbQueries.GetChirpsByHashtag(ctx, database.GetChirpsByHashtagParams{Tag: tag, ViewerID: viewerID})
+	if err != nil {
+		log.Println("GetChirpsByHashtag error:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
+		return
+	}
+	responseChirps, err := cfg.chirpResponses(ctx, viewerID, chirps)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, responseChirps)
+}
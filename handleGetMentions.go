@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+)
+
+// handleGetMentions godoc
+// @Summary      Get chirps mentioning a user
+// @Description  Retrieve chirps that @mention the user, newest first. Authentication is optional; only chirps the caller may see are returned.
+// @Tags         users
+// @Produce      json
+// @Param        Authorization  header  string  false  "Bearer JWT token"
+// @Param        userID  path      string  true  "User ID"
+// @Success      200     {array}   Chirp
+// @Failure      400     {object}  ErrorResponse "Invalid user ID"
+// @Failure      401     {object}  ErrorResponse "Invalid token"
+// @Failure      404     {object}  ErrorResponse "User not found"
+// @Failure      500     {object}  ErrorResponse "Failed to fetch chirps"
+// @Router       /api/users/{userID}/mentions [get]
+func (cfg *apiConfig) handleGetMentions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	viewerID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if _, err := cfg.dbQueries.GetUserByID(ctx, userID); err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	chirps, err := cfg.dbQueries.GetChirpsMentioningUser(ctx, database.GetChirpsMentioningUserParams{UserID: userID, ViewerID: viewerID})
+	if err != nil {
+		log.Println("GetChirpsMentioningUser error:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
+		return
+	}
+	responseChirps, err := cfg.chirpResponses(ctx, viewerID, chirps)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, responseChirps)
+}
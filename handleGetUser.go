@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"github.com/google/uuid"
+)
+
+// UserProfile is a user's public profile with follow counts
+type UserProfile struct {
+	Profile
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// handleGetUser godoc
+// @Summary      Get a user's profile
+// @Description  Returns a user's public profile with follower and following counts
+// @Tags         users
+// @Produce      json
+// @Param        userID  path  string  true  "User ID"
+// @Success      200  {object}  UserProfile
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/users/{userID} [get]
+func (cfg *apiConfig) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	user, err := cfg.dbQueries.GetUserByID(ctx, userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	followers, err := cfg.dbQueries.CountFollowers(ctx, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to count followers")
+		return
+	}
+	following, err := cfg.dbQueries.CountFollowing(ctx, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to count followed users")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, UserProfile{
+		Profile: Profile{
+			ID:          user.ID,
+			Username:    user.Username.String,
+			CreatedAt:   user.CreatedAt,
+			IsChirpyRed: user.IsChirpyRed,
+		},
+		FollowerCount:  followers,
+		FollowingCount: following,
+	})
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 	"github.com/odilmode/http/internal/database"
@@ -23,13 +26,14 @@ type response struct {
 
 // handleLogin godoc
 // @Summary      User Login
-// @Description  Authenticates user and returns JWT access and refresh tokens
+// @Description  Authenticates user and returns JWT access and refresh tokens. Logging in to an account deleted within the restore window cancels the deletion.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        credentials  body      LoginRequest  true "User email and password"
 // @Success      200          {object}  response
 // @Failure      401          {object}  ErrorResponse "Incorrect email or password"
+// @Failure      403          {object}  ErrorResponse "Account suspended"
 // @Failure      500          {object}  ErrorResponse "Internal server error"
 // @Router       /api/login [post]
 func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
@@ -42,6 +46,10 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user, err := cfg.dbQueries.GetUserByEmail(r.Context(), params.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Logging in to a deleted account cancels the deletion.
+		user, err = cfg.dbQueries.GetDeletedUserByEmail(r.Context(), params.Email)
+	}
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
 		return
@@ -53,6 +61,29 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	suspended, err := cfg.dbQueries.IsUserSuspended(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check account status")
+		return
+	}
+	if suspended {
+		respondWithError(w, http.StatusForbidden, "Account suspended")
+		return
+	}
+
+	if user.DeletedAt.Valid {
+		err := cfg.cancelAccountDeletion(r.Context(), user)
+		if errors.Is(err, errAccountPurged) {
+			respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
+			return
+		}
+		if err != nil {
+			log.Printf("cancelAccountDeletion error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't restore account")
+			return
+		}
+	}
+
 	expirationTime := time.Hour
 	
 
@@ -87,6 +118,7 @@ func (cfg *apiConfig) handleLogin(w http.ResponseWriter, r *http.Request) {
 		User: User{
 			ID:        user.ID,
 			Email:     user.Email,
+			Username:  user.Username.String,
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: user.UpdatedAt,
 			IsChirpyRed: user.IsChirpyRed,
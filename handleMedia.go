@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"unicode/utf8"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/imaging"
+)
+
+const (
+	maxMediaBytes    = 5 << 20
+	maxAltTextLength = 1000
+	// maxChirpMedia is how many attachments a single chirp may carry.
+	maxChirpMedia = 4
+)
+
+// mediaExtensions maps the content types accepted for upload to the file
+// extension used in storage keys. Content types are sniffed from the file
+// itself; the client-supplied type is ignored.
+var mediaExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// Attachment is a media file attached to a chirp
+// @Description An uploaded image. Uploads are processed in the background: url and variants are only set once status is "ready".
+type Attachment struct {
+	ID          uuid.UUID           `json:"id"`
+	Status      string              `json:"status" enums:"pending,processing,ready,failed"`
+	URL         string              `json:"url,omitempty"`
+	ContentType string              `json:"content_type"`
+	SizeBytes   int64               `json:"size_bytes"`
+	Width       int32               `json:"width"`
+	Height      int32               `json:"height"`
+	AltText     string              `json:"alt_text"`
+	BlurHash    string              `json:"blurhash,omitempty"`
+	Variants    []AttachmentVariant `json:"variants,omitempty"`
+}
+
+// AttachmentVariant is a resized copy of an attachment
+// @Description A thumbnail of an uploaded image
+type AttachmentVariant struct {
+	Name   string `json:"name" example:"small"`
+	URL    string `json:"url"`
+	Width  int32  `json:"width"`
+	Height int32  `json:"height"`
+}
+
+func (cfg *apiConfig) attachment(m database.Medium, variants []database.MediaVariant) Attachment {
+	a := Attachment{
+		ID:          m.ID,
+		Status:      m.Status,
+		ContentType: m.ContentType,
+		SizeBytes:   m.SizeBytes,
+		Width:       m.Width,
+		Height:      m.Height,
+		AltText:     m.AltText,
+		BlurHash:    m.Blurhash,
+	}
+	if m.Status != mediaReady {
+		return a
+	}
+	a.URL = cfg.media.URL(m.StorageKey)
+	for _, v := range variants {
+		a.Variants = append(a.Variants, AttachmentVariant{
+			Name:   v.Name,
+			URL:    cfg.media.URL(v.StorageKey),
+			Width:  v.Width,
+			Height: v.Height,
+		})
+	}
+	return a
+}
+
+// loadMediaVariants returns the thumbnails of the given media keyed by media
+// ID, smallest first.
+func (cfg *apiConfig) loadMediaVariants(ctx context.Context, media []database.Medium) (map[uuid.UUID][]database.MediaVariant, error) {
+	ids := make([]uuid.UUID, len(media))
+	for i, m := range media {
+		ids[i] = m.ID
+	}
+	rows, err := cfg.dbQueries.GetMediaVariants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	variants := make(map[uuid.UUID][]database.MediaVariant, len(media))
+	for _, v := range rows {
+		variants[v.MediaID] = append(variants[v.MediaID], v)
+	}
+	return variants, nil
+}
+
+// handleUploadMedia godoc
+// @Summary      Upload media
+// @Description  Upload an image (JPEG, PNG or GIF, up to 5 MB) to attach to a chirp. The file type is detected from its content. The image is processed in the background: metadata is stripped, the orientation is applied and thumbnails are generated. Poll GET /api/media/{mediaID} for its status. The returned id can be passed in media_ids right away.
+// @Tags         media
+// @Accept       mpfd
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer JWT token"
+// @Param        file           formData  file    true   "Image file"
+// @Param        alt_text       formData  string  false  "Description of the image for screen readers"
+// @Success      202  {object}  Attachment
+// @Failure      400  {object}  ErrorResponse "Missing file or invalid alt text"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      413  {object}  ErrorResponse "File or image dimensions too large"
+// @Failure      415  {object}  ErrorResponse "Unsupported media type"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/media [post]
+func (cfg *apiConfig) handleUploadMedia(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	// Leave room for the multipart framing and the alt text field.
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaBytes+64<<10)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "File too large")
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Missing file")
+		return
+	}
+	defer file.Close()
+
+	altText := r.FormValue("alt_text")
+	if utf8.RuneCountInString(altText) > maxAltTextLength {
+		respondWithError(w, http.StatusBadRequest, "Alt text is too long")
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMediaBytes+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file")
+		return
+	}
+	if len(data) > maxMediaBytes {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "File too large")
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := mediaExtensions[contentType]
+	if !ok {
+		respondWithError(w, http.StatusUnsupportedMediaType, "Unsupported media type")
+		return
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		respondWithError(w, http.StatusUnsupportedMediaType, "Couldn't read image")
+		return
+	}
+
+	// Every frame of an animated GIF counts, so one can't be queued that
+	// would exhaust a media worker's memory when decoded.
+	if err := imaging.CheckSize(data); err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Image dimensions too large")
+			return
+		}
+		respondWithError(w, http.StatusUnsupportedMediaType, "Couldn't read image")
+		return
+	}
+
+	// The raw upload keeps its metadata until the processor has produced
+	// the published renditions, so it goes under a key that can't be
+	// derived from the media ID.
+	ctx := r.Context()
+	id := uuid.New()
+	key := uploadKey(id, ext)
+	if err := cfg.media.Put(ctx, key, contentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		log.Printf("Couldn't store media %s: %v", key, err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store file")
+		return
+	}
+
+	media, err := cfg.dbQueries.CreateMedia(ctx, database.CreateMediaParams{
+		ID:          id,
+		UserID:      userID,
+		StorageKey:  fmt.Sprintf("%s/original.%s", id, ext),
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		Width:       int32(config.Width),
+		Height:      int32(config.Height),
+		AltText:     altText,
+		UploadKey:   key,
+	})
+	if err != nil {
+		log.Printf("CreateMedia error: %v", err)
+		cfg.media.Delete(ctx, key)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save media")
+		return
+	}
+	cfg.mediaProcessor.enqueue(media.ID)
+	respondWithJSON(w, http.StatusAccepted, cfg.attachment(media, nil))
+}
+
+// handleGetMedia godoc
+// @Summary      Get media
+// @Description  Get an uploaded image and its processing status. Only the uploader can see media that isn't attached to a chirp yet, and media on a chirp is only shown to users who can see the chirp.
+// @Tags         media
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        mediaID        path    string  true  "Media ID"
+// @Success      200  {object}  Attachment
+// @Failure      400  {object}  ErrorResponse "Invalid media ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Media not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/media/{mediaID} [get]
+func (cfg *apiConfig) handleGetMedia(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("mediaID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid media ID")
+		return
+	}
+	ctx := r.Context()
+	media, err := cfg.dbQueries.GetMedia(ctx, database.GetMediaParams{ID: id, ViewerID: userID})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && media.UserID != userID && !media.ChirpID.Valid) {
+		respondWithError(w, http.StatusNotFound, "Media not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetMedia error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media")
+		return
+	}
+	variants, err := cfg.loadMediaVariants(ctx, []database.Medium{media})
+	if err != nil {
+		log.Printf("GetMediaVariants error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, cfg.attachment(media, variants[media.ID]))
+}
+
+// attachMedia links uploaded media to a new chirp in the order given. Each
+// item must belong to the author and not already be attached elsewhere.
+func attachMedia(ctx context.Context, q *database.Queries, chirp database.Chirp, mediaIDs []uuid.UUID) error {
+	for i, id := range mediaIDs {
+		attached, err := q.AttachMedia(ctx, database.AttachMediaParams{
+			ChirpID:  uuid.NullUUID{UUID: chirp.ID, Valid: true},
+			Position: int32(i),
+			ID:       id,
+			UserID:   chirp.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		if attached == 0 {
+			return errInvalidMedia
+		}
+	}
+	return nil
+}
+
+var errInvalidMedia = errors.New("media not found or already attached")
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/moderation"
+)
+
+// ModerationRule is a content moderation rule
+// @Description A moderation rule. Rules run in ascending position order; a "words" rule matches its word list after Unicode and leet-speak normalization, a "regex" rule matches its case-insensitive pattern.
+type ModerationRule struct {
+	ID          uuid.UUID `json:"id"`
+	Position    int32     `json:"position"`
+	Kind        string    `json:"kind" enums:"words,regex"`
+	Action      string    `json:"action" enums:"mask,reject,flag"`
+	Pattern     string    `json:"pattern,omitempty"`
+	Words       []string  `json:"words,omitempty"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// createModerationRuleRequest is the body for creating a moderation rule
+type createModerationRuleRequest struct {
+	Position    int32    `json:"position"`
+	Kind        string   `json:"kind" enums:"words,regex"`
+	Action      string   `json:"action" enums:"mask,reject,flag"`
+	Pattern     string   `json:"pattern"`
+	Words       []string `json:"words"`
+	Description string   `json:"description"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// updateModerationRuleRequest is the body for updating a moderation rule.
+// Omitted fields are left unchanged.
+type updateModerationRuleRequest struct {
+	Position    *int32  `json:"position"`
+	Action      *string `json:"action" enums:"mask,reject,flag"`
+	Pattern     *string `json:"pattern"`
+	Description *string `json:"description"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// moderationWordsRequest is the body for adding words to a word list
+type moderationWordsRequest struct {
+	Words []string `json:"words"`
+}
+
+// ModerationFlag is a chirp flagged by a moderation rule
+// @Description A chirp that matched a flag rule and is waiting for review
+type ModerationFlag struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ChirpID     uuid.UUID  `json:"chirp_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Body        string     `json:"body"`
+	RuleID      *uuid.UUID `json:"rule_id,omitempty"`
+	MatchedText string     `json:"matched_text"`
+}
+
+// handleListModerationRules godoc
+// @Summary      List moderation rules
+// @Description  Lists all moderation rules in the order they are applied, with their word lists.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Success      200  {array}   ModerationRule
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/rules [get]
+func (cfg *apiConfig) handleListModerationRules(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	ctx := r.Context()
+	rules, err := cfg.dbQueries.ListModerationRules(ctx)
+	if err != nil {
+		log.Printf("ListModerationRules error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't list rules")
+		return
+	}
+	words, err := cfg.dbQueries.ListModerationWords(ctx)
+	if err != nil {
+		log.Printf("ListModerationWords error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't list rules")
+		return
+	}
+	wordLists := make(map[uuid.UUID][]string)
+	for _, w := range words {
+		wordLists[w.RuleID] = append(wordLists[w.RuleID], w.Word)
+	}
+	resp := make([]ModerationRule, len(rules))
+	for i, rule := range rules {
+		resp[i] = moderationRuleResponse(rule, wordLists[rule.ID])
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleCreateModerationRule godoc
+// @Summary      Create a moderation rule
+// @Description  Creates a word list or regex rule. The rule takes effect immediately.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                       true  "ApiKey <ADMIN_API_KEY>"
+// @Param        rule           body    createModerationRuleRequest  true  "Rule"
+// @Success      201  {object}  ModerationRule
+// @Failure      400  {object}  ErrorResponse "Invalid rule"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/rules [post]
+func (cfg *apiConfig) handleCreateModerationRule(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	var params createModerationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	if params.Kind != string(moderation.KindWords) {
+		params.Words = nil
+	}
+	_, err := moderation.New([]moderation.Rule{{
+		Kind:    moderation.Kind(params.Kind),
+		Action:  moderation.Action(params.Action),
+		Words:   params.Words,
+		Pattern: params.Pattern,
+	}})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	enabled := true
+	if params.Enabled != nil {
+		enabled = *params.Enabled
+	}
+
+	ctx := r.Context()
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create rule")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	rule, err := qtx.CreateModerationRule(ctx, database.CreateModerationRuleParams{
+		Position:    params.Position,
+		Kind:        params.Kind,
+		Action:      params.Action,
+		Pattern:     params.Pattern,
+		Description: params.Description,
+		Enabled:     enabled,
+	})
+	if err != nil {
+		log.Printf("CreateModerationRule error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create rule")
+		return
+	}
+	if len(params.Words) > 0 {
+		err = qtx.AddModerationWords(ctx, database.AddModerationWordsParams{RuleID: rule.ID, Words: params.Words})
+		if err != nil {
+			log.Printf("AddModerationWords error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create rule")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create rule")
+		return
+	}
+	cfg.reloadModeration(ctx)
+	respondWithJSON(w, http.StatusCreated, moderationRuleResponse(rule, params.Words))
+}
+
+// handleUpdateModerationRule godoc
+// @Summary      Update a moderation rule
+// @Description  Changes a rule's position, action, pattern, description or enabled flag. Omitted fields are left unchanged. The change takes effect immediately.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                       true  "ApiKey <ADMIN_API_KEY>"
+// @Param        ruleID         path    string                       true  "Rule ID"
+// @Param        rule           body    updateModerationRuleRequest  true  "Fields to change"
+// @Success      200  {object}  ModerationRule
+// @Failure      400  {object}  ErrorResponse "Invalid rule"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "Rule not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/rules/{ruleID} [put]
+func (cfg *apiConfig) handleUpdateModerationRule(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+	var params updateModerationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+
+	ctx := r.Context()
+	current, err := cfg.dbQueries.GetModerationRule(ctx, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetModerationRule error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update rule")
+		return
+	}
+	// Validate the rule as it will be after the update.
+	check := moderation.Rule{
+		Kind:    moderation.Kind(current.Kind),
+		Action:  moderation.Action(current.Action),
+		Pattern: current.Pattern,
+	}
+	if params.Action != nil {
+		check.Action = moderation.Action(*params.Action)
+	}
+	if params.Pattern != nil {
+		check.Pattern = *params.Pattern
+	}
+	if _, err := moderation.New([]moderation.Rule{check}); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	update := database.UpdateModerationRuleParams{ID: ruleID}
+	if params.Position != nil {
+		update.Position = sql.NullInt32{Int32: *params.Position, Valid: true}
+	}
+	if params.Action != nil {
+		update.Action = sql.NullString{String: *params.Action, Valid: true}
+	}
+	if params.Pattern != nil {
+		update.Pattern = sql.NullString{String: *params.Pattern, Valid: true}
+	}
+	if params.Description != nil {
+		update.Description = sql.NullString{String: *params.Description, Valid: true}
+	}
+	if params.Enabled != nil {
+		update.Enabled = sql.NullBool{Bool: *params.Enabled, Valid: true}
+	}
+	rule, err := cfg.dbQueries.UpdateModerationRule(ctx, update)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	if err != nil {
+		log.Printf("UpdateModerationRule error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update rule")
+		return
+	}
+	cfg.reloadModeration(ctx)
+	respondWithJSON(w, http.StatusOK, moderationRuleResponse(rule, nil))
+}
+
+// handleDeleteModerationRule godoc
+// @Summary      Delete a moderation rule
+// @Description  Deletes a rule and its word list. The change takes effect immediately.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Param        ruleID         path    string  true  "Rule ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid rule ID"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "Rule not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/rules/{ruleID} [delete]
+func (cfg *apiConfig) handleDeleteModerationRule(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteModerationRule(r.Context(), ruleID)
+	if err != nil {
+		log.Printf("DeleteModerationRule error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete rule")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	cfg.reloadModeration(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAddModerationWords godoc
+// @Summary      Add words to a word list
+// @Description  Adds words to a "words" rule. Words already in the list are ignored. The change takes effect immediately.
+// @Tags         admin
+// @Accept       json
+// @Param        Authorization  header  string                  true  "ApiKey <ADMIN_API_KEY>"
+// @Param        ruleID         path    string                  true  "Rule ID"
+// @Param        words          body    moderationWordsRequest  true  "Words to add"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid word or not a word list rule"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "Rule not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/rules/{ruleID}/words [post]
+func (cfg *apiConfig) handleAddModerationWords(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+	var params moderationWordsRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	if len(params.Words) == 0 {
+		respondWithError(w, http.StatusBadRequest, "No words given")
+		return
+	}
+	for _, word := range params.Words {
+		if _, err := moderation.NormalizeWord(word); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	rule, err := cfg.dbQueries.GetModerationRule(ctx, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetModerationRule error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't add words")
+		return
+	}
+	if rule.Kind != string(moderation.KindWords) {
+		respondWithError(w, http.StatusBadRequest, "Rule is not a word list")
+		return
+	}
+	err = cfg.dbQueries.AddModerationWords(ctx, database.AddModerationWordsParams{RuleID: ruleID, Words: params.Words})
+	if err != nil {
+		log.Printf("AddModerationWords error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't add words")
+		return
+	}
+	cfg.reloadModeration(ctx)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteModerationWord godoc
+// @Summary      Remove a word from a word list
+// @Description  Removes a word from a "words" rule. The change takes effect immediately.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Param        ruleID         path    string  true  "Rule ID"
+// @Param        word           path    string  true  "Word as it was added"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid rule ID"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "Word not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/rules/{ruleID}/words/{word} [delete]
+func (cfg *apiConfig) handleDeleteModerationWord(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteModerationWord(r.Context(), database.DeleteModerationWordParams{
+		RuleID: ruleID,
+		Word:   r.PathValue("word"),
+	})
+	if err != nil {
+		log.Printf("DeleteModerationWord error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete word")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Word not found")
+		return
+	}
+	cfg.reloadModeration(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListModerationFlags godoc
+// @Summary      List flagged chirps
+// @Description  Lists the oldest 100 chirps flagged by moderation rules that haven't been reviewed yet.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Success      200  {array}   ModerationFlag
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/flags [get]
+func (cfg *apiConfig) handleListModerationFlags(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	rows, err := cfg.dbQueries.ListPendingModerationFlags(r.Context())
+	if err != nil {
+		log.Printf("ListPendingModerationFlags error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't list flags")
+		return
+	}
+	flags := make([]ModerationFlag, len(rows))
+	for i, row := range rows {
+		flags[i] = ModerationFlag{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			ChirpID:     row.ChirpID,
+			UserID:      row.UserID,
+			Body:        row.Body,
+			MatchedText: row.MatchedText,
+		}
+		if row.RuleID.Valid {
+			flags[i].RuleID = &row.RuleID.UUID
+		}
+	}
+	respondWithJSON(w, http.StatusOK, flags)
+}
+
+// handleResolveModerationFlag godoc
+// @Summary      Mark a flag as reviewed
+// @Description  Removes a flagged chirp from the review list. Delete the chirp separately if it breaks the rules.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Param        flagID         path    string  true  "Flag ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid flag ID"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "Flag not found or already reviewed"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderation/flags/{flagID}/resolve [post]
+func (cfg *apiConfig) handleResolveModerationFlag(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	flagID, err := uuid.Parse(r.PathValue("flagID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid flag ID")
+		return
+	}
+	resolved, err := cfg.dbQueries.ResolveModerationFlag(r.Context(), flagID)
+	if err != nil {
+		log.Printf("ResolveModerationFlag error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve flag")
+		return
+	}
+	if resolved == 0 {
+		respondWithError(w, http.StatusNotFound, "Flag not found or already reviewed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func moderationRuleResponse(rule database.ModerationRule, words []string) ModerationRule {
+	return ModerationRule{
+		ID:          rule.ID,
+		Position:    rule.Position,
+		Kind:        rule.Kind,
+		Action:      rule.Action,
+		Pattern:     rule.Pattern,
+		Words:       words,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+}
+
+// reloadModeration applies rule changes to this instance right away. Other
+// instances pick them up on their next periodic reload.
+func (cfg *apiConfig) reloadModeration(ctx context.Context) {
+	if err := cfg.moderator.reload(ctx); err != nil {
+		log.Printf("Couldn't reload moderation rules: %v", err)
+	}
+}
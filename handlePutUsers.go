@@ -10,6 +10,8 @@ import (
 type RequestBody struct {
 	Email string `json:"email"`
 	Password string `json:"password"`
+	// Username is optional; when omitted the current username is kept
+	Username string `json:"username,omitempty"`
 }
 
 type ResponseBody struct {
@@ -17,7 +19,7 @@ type ResponseBody struct {
 }
 // handlePutUsers godoc
 // @Summary      Update User Info
-// @Description  Updates authenticated user's email and password
+// @Description  Updates authenticated user's email, password and optionally username
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -26,6 +28,7 @@ type ResponseBody struct {
 // @Success      200  {object}  ResponseBody
 // @Failure      400  {object}  ErrorResponse "Invalid request body"
 // @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      409  {object}  ErrorResponse "Email or username already taken"
 // @Failure      500  {object}  ErrorResponse "Internal server error"
 // @Router       /api/users [put]
 func (cfg *apiConfig) handlePutUsers(w http.ResponseWriter, r *http.Request) {
@@ -35,7 +38,7 @@ func (cfg *apiConfig) handlePutUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	userID, err := auth.ValidateJWT(accessToken, cfg.jwtSecret)
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
 		return
@@ -50,6 +53,12 @@ func (cfg *apiConfig) handlePutUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	username, ok := usernameParam(params.Username)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid username")
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't hash password")
@@ -60,7 +69,12 @@ func (cfg *apiConfig) handlePutUsers(w http.ResponseWriter, r *http.Request) {
 		ID: userID,
 		Email: params.Email,
 		HashedPassword: hashedPassword,
+		Username: username,
 	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email or username already taken")
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't update user params")
 		return
@@ -71,6 +85,7 @@ func (cfg *apiConfig) handlePutUsers(w http.ResponseWriter, r *http.Request) {
 			CreatedAt:   updatedUser.CreatedAt,
 			UpdatedAt:   updatedUser.UpdatedAt,
 			Email:       updatedUser.Email,
+			Username:    updatedUser.Username.String,
 			IsChirpyRed: updatedUser.IsChirpyRed,
 		},
 	})
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+// handleRechirp reposts another user's chirp as the authenticated user.
+// @Summary Rechirp a chirp
+// @Description Repost a chirp. Rechirping a rechirp reposts the original chirp. Only public and unlisted chirps can be rechirped, and the rechirp gets the same visibility. Each user can rechirp a chirp once.
+// @Tags Chirps
+// @Produce json
+// @Param chirpID path string true "Chirp ID"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 201 {object} Chirp
+// @Failure 400 {object} map[string]string "Invalid chirp ID"
+// @Failure 401 {object} map[string]string "Unauthorized or missing token"
+// @Failure 403 {object} map[string]string "Chirp is followers-only or mentioned-only"
+// @Failure 404 {object} map[string]string "Chirp not found"
+// @Failure 409 {object} map[string]string "Chirp already rechirped"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/chirps/{chirpID}/rechirp [post]
+func (cfg *apiConfig) handleRechirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	ctx := r.Context()
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	original, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: id, ViewerID: userID})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	// Rechirps always point at the original chirp, never at another rechirp.
+	if original.RechirpOf.Valid {
+		original, err = cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: original.RechirpOf.UUID, ViewerID: userID})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Chirp not found")
+			return
+		}
+	}
+
+	// Rechirping would show a restricted chirp to the rechirper's followers.
+	if original.Visibility != visibilityPublic && original.Visibility != visibilityUnlisted {
+		respondWithError(w, http.StatusForbidden, "Only public and unlisted chirps can be rechirped")
+		return
+	}
+
+	now := time.Now().UTC()
+	rechirp, err := cfg.dbQueries.CreateRechirp(ctx, database.CreateRechirpParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		UserID:     userID,
+		RechirpOf:  uuid.NullUUID{UUID: original.ID, Valid: true},
+		Visibility: original.Visibility,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusConflict, "Chirp already rechirped")
+		return
+	}
+	if err != nil {
+		log.Printf("CreateRechirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp")
+		return
+	}
+	cfg.timelines.chirpCreated(ctx, rechirp)
+	cfg.notifications.chirpPublished(ctx, rechirp)
+
+	responseChirp, err := cfg.chirpResponse(ctx, userID, rechirp)
+	if err != nil {
+		log.Printf("Failed to load rechirped chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load rechirp")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, responseChirp)
+}
+
+// handleUnrechirp removes the authenticated user's rechirp of a chirp.
+// @Summary Undo a rechirp
+// @Description Remove the authenticated user's rechirp of a chirp. The ID can be the original chirp's or that of a rechirp of it. The rechirp can be restored with POST /api/chirps/{chirpID}/restore until it is purged.
+// @Tags Chirps
+// @Produce json
+// @Param chirpID path string true "ID of the rechirped chirp or of a rechirp"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Invalid chirp ID"
+// @Failure 401 {object} map[string]string "Unauthorized or missing token"
+// @Failure 404 {object} map[string]string "Rechirp not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/chirps/{chirpID}/rechirp [delete]
+func (cfg *apiConfig) handleUnrechirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+
+	ctx := r.Context()
+	// As when rechirping, a rechirp's ID stands for its original chirp.
+	chirp, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: id, ViewerID: userID})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("GetChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp")
+		return
+	}
+	if err == nil && chirp.RechirpOf.Valid {
+		id = chirp.RechirpOf.UUID
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	// The rechirp is soft-deleted like any other chirp, so it can be restored until purged.
+	deleted, err := qtx.SoftDeleteRechirp(ctx, database.SoftDeleteRechirpParams{
+		UserID:    userID,
+		RechirpOf: uuid.NullUUID{UUID: id, Valid: true},
+	})
+	if err != nil {
+		log.Printf("SoftDeleteRechirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp")
+		return
+	}
+	if len(deleted) == 0 {
+		respondWithError(w, http.StatusNotFound, "Rechirp not found")
+		return
+	}
+	// Drop the rechirp from materialized timelines so pages stay full.
+	if err := qtx.DeleteTimelineEntriesForChirps(ctx, deleted); err != nil {
+		log.Printf("DeleteTimelineEntriesForChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't undo rechirp")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
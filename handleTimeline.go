@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+// TimelinePage is one page of chirps, newest first
+type TimelinePage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// handleTimeline godoc
+// @Summary      Home timeline
+// @Description  Chirps from the users the authenticated user follows plus their own, newest first. Pass next_cursor back as cursor to get the following page.
+// @Tags         chirps
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  TimelinePage
+// @Failure      400  {object}  ErrorResponse "Invalid pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Failed to fetch timeline"
+// @Router       /api/timeline [get]
+func (cfg *apiConfig) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	var chirps []database.Chirp
+	_, err = cfg.dbQueries.GetTimeline(ctx, userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Not materialized yet: build it in the background and answer from
+		// the follow graph directly.
+		cfg.timelines.rebuild(ctx, userID)
+		chirps, err = cfg.dbQueries.GetHomeTimeline(ctx, database.GetHomeTimelineParams{
+			UserID:          userID,
+			BeforeCreatedAt: cursor.CreatedAt,
+			BeforeID:        cursor.ID,
+			PageLimit:       limit,
+		})
+	case err == nil:
+		chirps, err = cfg.cachedTimeline(ctx, userID, limit, cursor)
+	}
+	if err != nil {
+		log.Println("GetHomeTimeline error:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch timeline")
+		return
+	}
+
+	responseChirps, err := cfg.chirpResponses(ctx, userID, chirps)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch timeline")
+		return
+	}
+	page := TimelinePage{Chirps: responseChirps}
+	if len(chirps) > 0 {
+		last := chirps[len(chirps)-1]
+		page.NextCursor = nextCursor(len(chirps), limit, last.CreatedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// cachedTimeline reads a page from the user's materialized timeline and
+// merges in chirps from followed authors too popular to fan out to every
+// follower. Pages past the end of the bounded cache are read from the follow
+// graph instead.
+func (cfg *apiConfig) cachedTimeline(ctx context.Context, userID uuid.UUID, limit int32, cursor pageCursor) ([]database.Chirp, error) {
+	cached, err := cfg.dbQueries.GetCachedTimeline(ctx, database.GetCachedTimelineParams{
+		UserID:          userID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(cached) < int(limit) {
+		return cfg.dbQueries.GetHomeTimeline(ctx, database.GetHomeTimelineParams{
+			UserID:          userID,
+			BeforeCreatedAt: cursor.CreatedAt,
+			BeforeID:        cursor.ID,
+			PageLimit:       limit,
+		})
+	}
+
+	popular, err := cfg.dbQueries.GetPopularAuthorChirps(ctx, database.GetPopularAuthorChirpsParams{
+		UserID:          userID,
+		MinFollowers:    fanoutFollowerLimit,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(cached)+len(popular))
+	merged := make([]database.Chirp, 0, len(cached)+len(popular))
+	for _, c := range append(cached, popular...) {
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		merged = append(merged, c)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if !merged[i].CreatedAt.Equal(merged[j].CreatedAt) {
+			return merged[i].CreatedAt.After(merged[j].CreatedAt)
+		}
+		return bytes.Compare(merged[i].ID[:], merged[j].ID[:]) > 0
+	})
+	if len(merged) > int(limit) {
+		merged = merged[:limit]
+	}
+	return merged, nil
+}
@@ -0,0 +1,238 @@
+// Package activitypub holds the ActivityPub and WebFinger documents Chirpy
+// exchanges with fediverse servers such as Mastodon, and a client for
+// fetching remote actors and delivering activities to their inboxes.
+package activitypub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"html"
+	"strings"
+	"time"
+)
+
+const (
+	// ContentType is the media type of ActivityPub documents.
+	ContentType = "application/activity+json"
+	// Accept is sent when fetching documents; servers answer either media
+	// type.
+	Accept = `application/activity+json, application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+	// Public is the collection addressing an activity to everyone.
+	Public = "https://www.w3.org/ns/activitystreams#Public"
+)
+
+// Context is the JSON-LD context of the documents Chirpy publishes.
+var Context = []string{
+	"https://www.w3.org/ns/activitystreams",
+	"https://w3id.org/security/v1",
+}
+
+// Actor is a user, local or remote.
+type Actor struct {
+	Context           any       `json:"@context,omitempty"`
+	ID                string    `json:"id"`
+	Type              string    `json:"type"`
+	PreferredUsername string    `json:"preferredUsername"`
+	Name              string    `json:"name,omitempty"`
+	URL               string    `json:"url,omitempty"`
+	Inbox             string    `json:"inbox"`
+	Outbox            string    `json:"outbox,omitempty"`
+	Followers         string    `json:"followers,omitempty"`
+	Published         string    `json:"published,omitempty"`
+	PublicKey         PublicKey `json:"publicKey"`
+	Endpoints         *struct {
+		SharedInbox string `json:"sharedInbox,omitempty"`
+	} `json:"endpoints,omitempty"`
+}
+
+// SharedInbox is the inbox to deliver activities for any of the actor's
+// server's users to, falling back to the actor's own inbox.
+func (a Actor) SharedInbox() string {
+	if a.Endpoints != nil && a.Endpoints.SharedInbox != "" {
+		return a.Endpoints.SharedInbox
+	}
+	return a.Inbox
+}
+
+// PublicKey is the key an actor signs its requests with.
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Note is a chirp.
+type Note struct {
+	Context      any      `json:"@context,omitempty"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	AttributedTo string   `json:"attributedTo"`
+	Content      string   `json:"content"`
+	URL          string   `json:"url,omitempty"`
+	Published    string   `json:"published"`
+	Updated      string   `json:"updated,omitempty"`
+	To           []string `json:"to"`
+	Cc           []string `json:"cc"`
+	QuoteURL     string   `json:"quoteUrl,omitempty"`
+}
+
+// Tombstone replaces a deleted object.
+type Tombstone struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Activity is something an actor did to an object. Object is an object or
+// an object's ID; received activities hold it as decoded JSON.
+type Activity struct {
+	Context any      `json:"@context,omitempty"`
+	ID      string   `json:"id"`
+	Type    string   `json:"type"`
+	Actor   string   `json:"actor"`
+	Object  any      `json:"object"`
+	To      []string `json:"to,omitempty"`
+	Cc      []string `json:"cc,omitempty"`
+}
+
+// ObjectID is the ID of the activity's object, whether the object was sent
+// inline or by reference.
+func (a Activity) ObjectID() string {
+	switch object := a.Object.(type) {
+	case string:
+		return object
+	case map[string]any:
+		id, _ := object["id"].(string)
+		return id
+	}
+	return ""
+}
+
+// ObjectActivity decodes an inline object that is itself an activity, such
+// as the Follow an Undo undoes.
+func (a Activity) ObjectActivity() (Activity, error) {
+	object, ok := a.Object.(map[string]any)
+	if !ok {
+		return Activity{}, errors.New("object isn't inline")
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return Activity{}, err
+	}
+	var inner Activity
+	err = json.Unmarshal(data, &inner)
+	return inner, err
+}
+
+// OrderedCollection is a list such as an outbox or a followers list.
+type OrderedCollection struct {
+	Context      any    `json:"@context,omitempty"`
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	TotalItems   *int64 `json:"totalItems,omitempty"`
+	OrderedItems []any  `json:"orderedItems,omitempty"`
+}
+
+// JRD is a WebFinger response.
+type JRD struct {
+	Subject string   `json:"subject"`
+	Aliases []string `json:"aliases,omitempty"`
+	Links   []Link   `json:"links"`
+}
+
+// Link is a link in a WebFinger response.
+type Link struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// ParseAccount splits a WebFinger resource such as "acct:alice@chirpy.example"
+// into its user and host.
+func ParseAccount(resource string) (user, host string, err error) {
+	account, ok := strings.CutPrefix(resource, "acct:")
+	if !ok {
+		return "", "", errors.New("resource isn't an acct: URI")
+	}
+	user, host, ok = strings.Cut(strings.TrimPrefix(account, "@"), "@")
+	if !ok || user == "" || host == "" {
+		return "", "", errors.New("resource must be acct:user@host")
+	}
+	return user, host, nil
+}
+
+// Time formats t the way ActivityPub documents carry times.
+func Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// NoteContent is the HTML content of a note with text as its body.
+func NoteContent(text string) string {
+	var paragraphs []string
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, "<p>"+strings.ReplaceAll(html.EscapeString(p), "\n", "<br>")+"</p>")
+		}
+	}
+	return strings.Join(paragraphs, "")
+}
+
+// GenerateKey makes a key pair for a local actor, PEM-encoded.
+func GenerateKey() (privatePEM, publicPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", err
+	}
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+	return privatePEM, publicPEM, nil
+}
+
+// ParsePrivateKey decodes a key made by GenerateKey.
+func ParsePrivateKey(privatePEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("no PEM block in private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key isn't RSA")
+	}
+	return rsaKey, nil
+}
+
+// ParsePublicKey decodes an actor's publicKeyPem. Both PKIX and the older
+// PKCS #1 encodings are in use.
+func ParsePublicKey(publicPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("no PEM block in public key")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key isn't RSA")
+	}
+	return rsaKey, nil
+}
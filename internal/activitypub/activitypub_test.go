@@ -0,0 +1,257 @@
+package activitypub
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+	"github.com/odilmode/http/internal/httpsig"
+)
+
+// fakeRemote is a remote server with one actor, alice, whose inbox records
+// the activities delivered to it after checking their signatures.
+type fakeRemote struct {
+	*httptest.Server
+	t         *testing.T
+	signerPub string
+	status    int
+	received  []Activity
+}
+
+func newFakeRemote(t *testing.T, signerPub string) *fakeRemote {
+	f := &fakeRemote{t: t, signerPub: signerPub, status: http.StatusAccepted}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeRemote) actorURL() string { return f.URL + "/users/alice" }
+
+func (f *fakeRemote) serve(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/users/alice":
+		w.Header().Set("Content-Type", ContentType)
+		json.NewEncoder(w).Encode(Actor{
+			ID:                f.actorURL(),
+			Type:              "Person",
+			PreferredUsername: "alice",
+			Inbox:             f.URL + "/users/alice/inbox",
+			PublicKey:         PublicKey{ID: f.actorURL() + "#main-key", Owner: f.actorURL(), PublicKeyPem: f.signerPub},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/users/impostor":
+		json.NewEncoder(w).Encode(Actor{ID: f.actorURL(), Inbox: "x", PublicKey: PublicKey{PublicKeyPem: "x"}})
+	case r.Method == http.MethodPost && r.URL.Path == "/users/alice/inbox":
+		body, _ := io.ReadAll(r.Body)
+		pub, err := ParsePublicKey(f.signerPub)
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		if err := httpsig.Verify(r, body, pub, time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var a Activity
+		if err := json.Unmarshal(body, &a); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.received = append(f.received, a)
+		w.WriteHeader(f.status)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newSigner(t *testing.T) (Signer, string) {
+	t.Helper()
+	privatePEM, publicPEM, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key, err := ParsePrivateKey(privatePEM)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	return Signer{KeyID: "https://chirpy.example/ap/users/1#main-key", Key: key}, publicPEM
+}
+
+func testClient() *Client {
+	c := NewClient("Chirpy test")
+	c.AllowHTTP = true
+	return c
+}
+
+func TestFetchActor(t *testing.T) {
+	signer, publicPEM := newSigner(t)
+	remote := newFakeRemote(t, publicPEM)
+	ctx := context.Background()
+
+	actor, err := testClient().FetchActor(ctx, remote.actorURL(), signer)
+	if err != nil {
+		t.Fatalf("FetchActor() error = %v", err)
+	}
+	if actor.PreferredUsername != "alice" || actor.SharedInbox() != remote.URL+"/users/alice/inbox" {
+		t.Errorf("FetchActor() = %+v", actor)
+	}
+	if _, err := ParsePublicKey(actor.PublicKey.PublicKeyPem); err != nil {
+		t.Errorf("ParsePublicKey() error = %v", err)
+	}
+
+	if _, err := testClient().FetchActor(ctx, remote.URL+"/users/impostor", signer); err == nil {
+		t.Error("FetchActor() accepted an actor with another server's ID")
+	}
+	_, err = testClient().FetchActor(ctx, remote.URL+"/users/bob", signer)
+	if !IsPermanent(err) {
+		t.Errorf("FetchActor() of a missing actor error = %v, want a permanent error", err)
+	}
+	if _, err := NewClient("").FetchActor(ctx, remote.actorURL(), signer); !IsPermanent(err) {
+		t.Errorf("FetchActor() over http error = %v, want a permanent error", err)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	signer, publicPEM := newSigner(t)
+	remote := newFakeRemote(t, publicPEM)
+	ctx := context.Background()
+	activity, err := json.Marshal(Activity{
+		Context: Context,
+		ID:      "https://chirpy.example/ap/chirps/1/activity",
+		Type:    "Create",
+		Actor:   "https://chirpy.example/ap/users/1",
+		Object:  Note{ID: "https://chirpy.example/ap/chirps/1", Type: "Note", Content: NoteContent("hi")},
+		To:      []string{Public},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox := remote.URL + "/users/alice/inbox"
+
+	if err := testClient().Deliver(ctx, inbox, activity, signer); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if len(remote.received) != 1 || remote.received[0].Type != "Create" || remote.received[0].ObjectID() != "https://chirpy.example/ap/chirps/1" {
+		t.Errorf("remote received %+v", remote.received)
+	}
+
+	other, _ := newSigner(t)
+	if err := testClient().Deliver(ctx, inbox, activity, other); !IsPermanent(err) {
+		t.Errorf("Deliver() with the wrong key error = %v, want a permanent error", err)
+	}
+	remote.status = http.StatusServiceUnavailable
+	if err := testClient().Deliver(ctx, inbox, activity, signer); err == nil || IsPermanent(err) {
+		t.Errorf("Deliver() to an unavailable server error = %v, want a temporary error", err)
+	}
+	remote.Close()
+	if err := testClient().Deliver(ctx, inbox, activity, signer); err == nil || IsPermanent(err) {
+		t.Errorf("Deliver() to a stopped server error = %v, want a temporary error", err)
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	signer, publicPEM := newSigner(t)
+	remote := newFakeRemote(t, publicPEM)
+	ctx := context.Background()
+	// The fake remote listens on loopback, which only AllowHTTP clients may
+	// reach.
+	c := NewClient("Chirpy test")
+	if _, err := c.FetchActor(ctx, "https://"+remote.Listener.Addr().String()+"/users/alice", signer); !IsPermanent(err) {
+		t.Errorf("FetchActor() of a loopback address error = %v, want a permanent error", err)
+	}
+	if err := c.Deliver(ctx, "https://localhost:1/inbox", []byte("{}"), signer); !IsPermanent(err) {
+		t.Errorf("Deliver() to localhost error = %v, want a permanent error", err)
+	}
+
+	// Redirects are dialed through the same check, and mustn't downgrade
+	// to http.
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/users/alice", nil)
+	if err := c.checkRedirect(req, nil); !IsPermanent(err) {
+		t.Errorf("checkRedirect() to http error = %v, want a permanent error", err)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00:ec2::254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestObjectActivity(t *testing.T) {
+	var undo Activity
+	err := json.Unmarshal([]byte(`{
+		"type": "Undo",
+		"actor": "https://remote.example/users/alice",
+		"object": {
+			"id": "https://remote.example/follows/1",
+			"type": "Follow",
+			"actor": "https://remote.example/users/alice",
+			"object": "https://chirpy.example/ap/users/1"
+		}
+	}`), &undo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.ObjectID() != "https://remote.example/follows/1" {
+		t.Errorf("ObjectID() = %q", undo.ObjectID())
+	}
+	follow, err := undo.ObjectActivity()
+	if err != nil || follow.Type != "Follow" || follow.ObjectID() != "https://chirpy.example/ap/users/1" {
+		t.Errorf("ObjectActivity() = %+v, %v", follow, err)
+	}
+}
+
+func TestParseAccount(t *testing.T) {
+	tests := []struct {
+		resource, user, host string
+		ok                   bool
+	}{
+		{"acct:alice@chirpy.example", "alice", "chirpy.example", true},
+		{"acct:@alice@chirpy.example", "alice", "chirpy.example", true},
+		{"alice@chirpy.example", "", "", false},
+		{"acct:alice", "", "", false},
+	}
+	for _, tt := range tests {
+		user, host, err := ParseAccount(tt.resource)
+		if (err == nil) != tt.ok || user != tt.user || host != tt.host {
+			t.Errorf("ParseAccount(%q) = %q, %q, %v", tt.resource, user, host, err)
+		}
+	}
+}
+
+func TestNoteContent(t *testing.T) {
+	got := NoteContent("Fish & chips <3\nand peas\n\nsecond")
+	want := "<p>Fish &amp; chips &lt;3<br>and peas</p><p>second</p>"
+	if got != want {
+		t.Errorf("NoteContent() = %q, want %q", got, want)
+	}
+	if strings.Contains(NoteContent("<script>"), "<script>") {
+		t.Error("NoteContent() didn't escape HTML")
+	}
+}
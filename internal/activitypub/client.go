@@ -0,0 +1,244 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+	"github.com/odilmode/http/internal/httpsig"
+)
+
+const (
+	// maxDocumentSize bounds the documents read from remote servers.
+	maxDocumentSize = 1 << 20
+	maxRedirects    = 5
+)
+
+// Signer is a local actor's key, used to sign requests on its behalf.
+type Signer struct {
+	KeyID string
+	Key   *rsa.PrivateKey
+}
+
+// Client talks to remote ActivityPub servers. Clients made by NewClient
+// only connect to public addresses, checked after DNS resolution and on
+// every redirect, so URLs taken from remote documents can't reach this
+// server's network.
+type Client struct {
+	HTTP      *http.Client
+	UserAgent string
+	// AllowHTTP permits plain http URLs and private addresses, for
+	// development and tests.
+	AllowHTTP bool
+}
+
+// NewClient returns a client with a request timeout suitable for talking to
+// remote servers.
+func NewClient(userAgent string) *Client {
+	c := &Client{UserAgent: userAgent}
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   c.checkDial,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the remote server, hiding its
+	// address from checkDial.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	c.HTTP = &http.Client{
+		Timeout:       10 * time.Second,
+		Transport:     transport,
+		CheckRedirect: c.checkRedirect,
+	}
+	return c
+}
+
+// StatusError is a remote server answering with an unsuccessful status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s answered %d", e.URL, e.StatusCode)
+}
+
+// IsPermanent reports whether a request that failed with err would fail
+// again however often it's retried: the URL is unusable or the server
+// rejected the request itself. Network errors, timeouts, rate limiting and
+// server errors are temporary.
+func IsPermanent(err error) bool {
+	var invalid *invalidURLError
+	if errors.As(err, &invalid) {
+		return true
+	}
+	var blocked *blockedAddressError
+	if errors.As(err, &blocked) {
+		return true
+	}
+	var status *StatusError
+	if !errors.As(err, &status) {
+		return false
+	}
+	switch status.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return status.StatusCode >= 400 && status.StatusCode < 500
+}
+
+type invalidURLError struct {
+	url    string
+	reason string
+}
+
+func (e *invalidURLError) Error() string {
+	return fmt.Sprintf("invalid URL %q: %s", e.url, e.reason)
+}
+
+func (c *Client) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &invalidURLError{rawURL, err.Error()}
+	}
+	if u.Scheme != "https" && !(c.AllowHTTP && u.Scheme == "http") {
+		return &invalidURLError{rawURL, "scheme must be https"}
+	}
+	if u.Host == "" {
+		return &invalidURLError{rawURL, "no host"}
+	}
+	return nil
+}
+
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return c.checkURL(req.URL.String())
+}
+
+type blockedAddressError struct {
+	address string
+}
+
+func (e *blockedAddressError) Error() string {
+	return fmt.Sprintf("address %s isn't public", e.address)
+}
+
+// checkDial refuses connections to addresses that aren't public. It runs
+// on the resolved address of every connection, redirects included.
+func (c *Client) checkDial(network, address string, _ syscall.RawConn) error {
+	if c.AllowHTTP {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(ip) {
+		return &blockedAddressError{address}
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which some clouds also
+// use for metadata services.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddr reports whether ip is a public unicast address: not
+// loopback, private, link-local (which holds cloud metadata services such
+// as 169.254.169.254), unspecified or multicast.
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// FetchActor fetches the actor document at actorURL, signing the request
+// with signer when it has a key, as servers in secure mode require. The
+// document must have the ID it was fetched by, so a server can't vouch for
+// actors on other servers.
+func (c *Client) FetchActor(ctx context.Context, actorURL string, signer Signer) (Actor, error) {
+	if err := c.checkURL(actorURL); err != nil {
+		return Actor{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, actorURL, nil)
+	if err != nil {
+		return Actor{}, err
+	}
+	req.Header.Set("Accept", Accept)
+	resp, err := c.do(req, signer, nil)
+	if err != nil {
+		return Actor{}, err
+	}
+	defer resp.Body.Close()
+
+	var actor Actor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(&actor); err != nil {
+		return Actor{}, fmt.Errorf("decoding actor %s: %w", actorURL, err)
+	}
+	if actor.ID != actorURL {
+		return Actor{}, fmt.Errorf("actor fetched from %s has ID %q", actorURL, actor.ID)
+	}
+	if actor.Inbox == "" || actor.PublicKey.PublicKeyPem == "" {
+		return Actor{}, fmt.Errorf("actor %s has no inbox or public key", actorURL)
+	}
+	if actor.PublicKey.Owner != "" && actor.PublicKey.Owner != actor.ID {
+		return Actor{}, fmt.Errorf("actor %s has a key owned by %q", actorURL, actor.PublicKey.Owner)
+	}
+	return actor, nil
+}
+
+// Deliver posts an activity, already encoded as JSON, to inbox.
+func (c *Client) Deliver(ctx context.Context, inbox string, activity []byte, signer Signer) error {
+	if err := c.checkURL(inbox); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(activity))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	resp, err := c.do(req, signer, activity)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends req, signed when signer has a key, and returns the response if
+// its status is 2xx.
+func (c *Client) do(req *http.Request, signer Signer, body []byte) (*http.Response, error) {
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if signer.Key != nil {
+		if err := httpsig.Sign(req, signer.KeyID, signer.Key, body); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxDocumentSize))
+		resp.Body.Close()
+		return nil, &StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
@@ -58,6 +58,28 @@ func TestValidateJWT(t *testing.T) {
 	}
 }
 
+func TestValidateJWTExpiry(t *testing.T) {
+	userID := uuid.New()
+	before := time.Now().Add(time.Hour).Truncate(time.Second)
+	token, _ := MakeJWT(userID, "secret", time.Hour)
+
+	gotUserID, expiresAt, err := ValidateJWTExpiry(token, "secret")
+	if err != nil {
+		t.Fatalf("ValidateJWTExpiry() error = %v", err)
+	}
+	if gotUserID != userID {
+		t.Errorf("ValidateJWTExpiry() gotUserID = %v, want %v", gotUserID, userID)
+	}
+	if expiresAt.Before(before) || expiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("ValidateJWTExpiry() expiresAt = %v, want about an hour from now", expiresAt)
+	}
+
+	expired, _ := MakeJWT(userID, "secret", -time.Minute)
+	if _, _, err := ValidateJWTExpiry(expired, "secret"); err == nil {
+		t.Error("ValidateJWTExpiry() accepted an expired token")
+	}
+}
+
 func TestAuth(t *testing.T) {
 	tests := []struct {
 		name string
@@ -102,3 +124,32 @@ func TestAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateResourceSignature(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	signature := SignResource("/api/users/export/1/download", expires, "secret")
+
+	tests := []struct {
+		name      string
+		resource  string
+		expires   time.Time
+		signature string
+		secret    string
+		wantErr   bool
+	}{
+		{"Valid signature", "/api/users/export/1/download", expires, signature, "secret", false},
+		{"Other resource", "/api/users/export/2/download", expires, signature, "secret", true},
+		{"Extended expiry", "/api/users/export/1/download", expires.Add(time.Hour), signature, "secret", true},
+		{"Wrong secret", "/api/users/export/1/download", expires, signature, "other", true},
+		{"Expired", "/api/users/export/1/download", time.Now().Add(-time.Minute),
+			SignResource("/api/users/export/1/download", time.Now().Add(-time.Minute), "secret"), "secret", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResourceSignature(tt.resource, tt.expires, tt.signature, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResourceSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
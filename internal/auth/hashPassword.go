@@ -52,6 +52,13 @@ func MakeJWT(
 
 // ValidateJWT -
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	id, _, err := ValidateJWTExpiry(tokenString, tokenSecret)
+	return id, err
+}
+
+// ValidateJWTExpiry - like ValidateJWT, also returning when the token
+// expires, or the zero time if it doesn't
+func ValidateJWTExpiry(tokenString, tokenSecret string) (uuid.UUID, time.Time, error) {
 	claimsStruct := jwt.RegisteredClaims{}
 	token, err := jwt.ParseWithClaims(
 		tokenString,
@@ -59,27 +66,36 @@ func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
 		func(token *jwt.Token) (interface{}, error) { return []byte(tokenSecret), nil },
 	)
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, time.Time{}, err
 	}
 
 	userIDString, err := token.Claims.GetSubject()
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, time.Time{}, err
 	}
 
 	issuer, err := token.Claims.GetIssuer()
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, time.Time{}, err
 	}
 	if issuer != string(TokenTypeAccess) {
-		return uuid.Nil, errors.New("invalid issuer")
+		return uuid.Nil, time.Time{}, errors.New("invalid issuer")
+	}
+
+	var expiry time.Time
+	expiresAt, err := token.Claims.GetExpirationTime()
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	if expiresAt != nil {
+		expiry = expiresAt.Time
 	}
 
 	id, err := uuid.Parse(userIDString)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("invalid user ID: %w", err)
 	}
-	return id, nil
+	return id, expiry, nil
 }
 
 
@@ -7,29 +7,38 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, quote_of, publish_at, published, visibility)
 VALUES (
 	$1,
 	$2,
 	$3,
 	$4,
-	$5
+	$5,
+	$6,
+	$7,
+	$7 IS NULL,
+	$8
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility
 `
 
 type CreateChirpParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	UserID     uuid.UUID
+	QuoteOf    uuid.NullUUID
+	PublishAt  sql.NullTime
+	Visibility string
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -39,6 +48,9 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.UpdatedAt,
 		arg.Body,
 		arg.UserID,
+		arg.QuoteOf,
+		arg.PublishAt,
+		arg.Visibility,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -47,28 +59,46 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.Published,
+		&i.DeletedAt,
+		&i.Visibility,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
+const deleteScheduledChirp = `-- name: DeleteScheduledChirp :execrows
 DELETE FROM chirps
-WHERE id = $1
+WHERE id = $1 AND user_id = $2 AND NOT published AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
-	return err
+type DeleteScheduledChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteScheduledChirp(ctx context.Context, arg DeleteScheduledChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteScheduledChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getAllChirps = `-- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility
 FROM chirps
+WHERE published AND deleted_at IS NULL
+	AND (visibility <> 'unlisted' OR user_id = $1::uuid)
+	AND chirp_visible(id, user_id, visibility, $1::uuid)
+	AND NOT user_muted($1::uuid, user_id)
 ORDER BY created_at ASC
 `
 
-func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirps)
+func (q *Queries) GetAllChirps(ctx context.Context, viewerID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirps, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +112,12 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.Published,
+			&i.DeletedAt,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -97,13 +133,19 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 }
 
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility
 FROM chirps
-WHERE id = $1
+WHERE id = $1 AND published AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, $2::uuid)
 `
 
-func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirp, id)
+type GetChirpParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirp(ctx context.Context, arg GetChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirp, arg.ID, arg.ViewerID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -111,18 +153,258 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.Published,
+		&i.DeletedAt,
+		&i.Visibility,
 	)
 	return i, err
 }
 
 const getChirpsByAuthor = `-- name: GetChirpsByAuthor :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility FROM chirps
+WHERE user_id = $1 AND published AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, $2::uuid)
 ORDER BY created_at DESC
 `
 
-func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByAuthor, userID)
+type GetChirpsByAuthorParams struct {
+	UserID   uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirpsByAuthor(ctx context.Context, arg GetChirpsByAuthorParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthor, arg.UserID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.Published,
+			&i.DeletedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility FROM chirps
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, $2::uuid)
+`
+
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.Published,
+			&i.DeletedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirp = `-- name: GetDeletedChirp :one
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility FROM chirps
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+`
+
+type GetDeletedChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetDeletedChirp(ctx context.Context, arg GetDeletedChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedChirp, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.Published,
+		&i.DeletedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getPurgeableChirps = `-- name: GetPurgeableChirps :many
+SELECT id FROM chirps
+WHERE deleted_at < $1
+ORDER BY deleted_at
+LIMIT $2
+`
+
+type GetPurgeableChirpsParams struct {
+	DeletedAt sql.NullTime
+	Limit     int32
+}
+
+func (q *Queries) GetPurgeableChirps(ctx context.Context, arg GetPurgeableChirpsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getPurgeableChirps, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScheduledChirp = `-- name: GetScheduledChirp :one
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility FROM chirps
+WHERE id = $1 AND user_id = $2 AND NOT published AND deleted_at IS NULL
+`
+
+type GetScheduledChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetScheduledChirp(ctx context.Context, arg GetScheduledChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getScheduledChirp, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.Published,
+		&i.DeletedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getScheduledChirps = `-- name: GetScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility FROM chirps
+WHERE user_id = $1 AND NOT published AND deleted_at IS NULL
+ORDER BY publish_at, id
+`
+
+func (q *Queries) GetScheduledChirps(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduledChirps, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.Published,
+			&i.DeletedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishDueChirps = `-- name: PublishDueChirps :many
+UPDATE chirps
+SET published = TRUE, created_at = NOW(), updated_at = NOW()
+WHERE id IN (
+	SELECT due.id FROM chirps due
+	WHERE NOT due.published AND due.deleted_at IS NULL AND due.publish_at <= NOW()
+	ORDER BY due.publish_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility
+`
+
+func (q *Queries) PublishDueChirps(ctx context.Context, limit int32) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, publishDueChirps, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +418,12 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.Published,
+			&i.DeletedAt,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -149,3 +437,200 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 	}
 	return items, nil
 }
+
+const purgeChirp = `-- name: PurgeChirp :exec
+DELETE FROM chirps
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, purgeChirp, id)
+	return err
+}
+
+const restoreChirp = `-- name: RestoreChirp :many
+UPDATE chirps
+SET deleted_at = NULL
+WHERE (id = $1 OR rechirp_of = $1) AND deleted_at = $2
+RETURNING id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility
+`
+
+type RestoreChirpParams struct {
+	ID        uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, restoreChirp, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.Published,
+			&i.DeletedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUserChirps = `-- name: RestoreUserChirps :many
+UPDATE chirps
+SET deleted_at = NULL
+WHERE (user_id = $1 OR rechirp_of IN (SELECT id FROM chirps WHERE user_id = $1))
+	AND deleted_at = $2
+RETURNING user_id
+`
+
+type RestoreUserChirpsParams struct {
+	UserID    uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) RestoreUserChirps(ctx context.Context, arg RestoreUserChirpsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, restoreUserChirps, arg.UserID, arg.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :many
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE (id = $1 OR rechirp_of = $1) AND deleted_at IS NULL
+RETURNING id
+`
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, softDeleteChirp, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteUserChirps = `-- name: SoftDeleteUserChirps :many
+UPDATE chirps
+SET deleted_at = $1
+WHERE (user_id = $2 OR rechirp_of IN (SELECT id FROM chirps WHERE user_id = $2))
+	AND deleted_at IS NULL
+RETURNING id
+`
+
+type SoftDeleteUserChirpsParams struct {
+	DeletedAt sql.NullTime
+	UserID    uuid.UUID
+}
+
+func (q *Queries) SoftDeleteUserChirps(ctx context.Context, arg SoftDeleteUserChirpsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, softDeleteUserChirps, arg.DeletedAt, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateScheduledChirp = `-- name: UpdateScheduledChirp :one
+UPDATE chirps
+SET body = $3, publish_at = $4, updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND NOT published AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, rechirp_of, quote_of, publish_at, published, deleted_at, visibility
+`
+
+type UpdateScheduledChirpParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Body      string
+	PublishAt sql.NullTime
+}
+
+func (q *Queries) UpdateScheduledChirp(ctx context.Context, arg UpdateScheduledChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateScheduledChirp,
+		arg.ID,
+		arg.UserID,
+		arg.Body,
+		arg.PublishAt,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.Published,
+		&i.DeletedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
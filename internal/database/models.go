@@ -11,12 +11,275 @@ import (
 	"github.com/google/uuid"
 )
 
+type ActorKey struct {
+	UserID        uuid.UUID
+	CreatedAt     time.Time
+	PublicKeyPem  string
+	PrivateKeyPem string
+}
+
+type ApDelivery struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UserID        uuid.UUID
+	Inbox         string
+	Activity      string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+}
+
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	UserID     uuid.UUID
+	RechirpOf  uuid.NullUUID
+	QuoteOf    uuid.NullUUID
+	PublishAt  sql.NullTime
+	Published  bool
+	DeletedAt  sql.NullTime
+	Visibility string
+}
+
+type ChirpEvent struct {
+	ID        int64
+	CreatedAt time.Time
+	Type      string
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+}
+
+type ChirpHashtag struct {
+	ChirpID     uuid.UUID
+	HashtagID   uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+}
+
+type Conversation struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
+}
+
+type ConversationParticipant struct {
+	ConversationID    uuid.UUID
+	UserID            uuid.UUID
+	JoinedAt          time.Time
+	LeftAt            sql.NullTime
+	LastReadMessageID uuid.NullUUID
+	LastReadAt        sql.NullTime
+}
+
+type DataExport struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Status     string
+	StorageKey sql.NullString
+	SizeBytes  sql.NullInt64
+	ExpiresAt  sql.NullTime
+}
+
+type Draft struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	QuoteOf    uuid.NullUUID
+	MediaIds   []uuid.UUID
+	Visibility string
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type FollowerCount struct {
+	UserID    uuid.UUID
+	Followers int64
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type HiddenChirp struct {
+	ChirpID  uuid.UUID
+	HiddenAt time.Time
+}
+
+type MediaVariant struct {
+	MediaID     uuid.UUID
+	Name        string
+	StorageKey  string
+	ContentType string
+	SizeBytes   int64
+	Width       int32
+	Height      int32
+}
+
+type Medium struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UserID      uuid.UUID
+	ChirpID     uuid.NullUUID
+	Position    int32
+	StorageKey  string
+	ContentType string
+	SizeBytes   int64
+	Width       int32
+	Height      int32
+	AltText     string
+	Status      string
+	UploadKey   string
+	Blurhash    string
+	ProcessedAt sql.NullTime
+	ClaimedAt   sql.NullTime
+}
+
+type Mention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+}
+
+type Message struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Body           string
+}
+
+type ModerationAction struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	ModeratorID  uuid.UUID
+	CaseID       uuid.NullUUID
+	Action       string
+	ChirpID      uuid.NullUUID
+	TargetUserID uuid.NullUUID
+	Note         string
+}
+
+type ModerationCase struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ChirpID     uuid.UUID
+	ReportCount int32
+	ClaimedBy   uuid.NullUUID
+	ClaimedAt   sql.NullTime
+	ResolvedBy  uuid.NullUUID
+	ResolvedAt  sql.NullTime
+	Resolution  sql.NullString
+}
+
+type ModerationFlag struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	ChirpID     uuid.UUID
+	RuleID      uuid.NullUUID
+	MatchedText string
+	ReviewedAt  sql.NullTime
+}
+
+type ModerationRule struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Position    int32
+	Kind        string
+	Action      string
+	Pattern     string
+	Description string
+	Enabled     bool
+}
+
+type ModerationWord struct {
+	RuleID    uuid.UUID
+	Word      string
+	CreatedAt time.Time
+}
+
+type Moderator struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Type       string
+	ChirpID    uuid.NullUUID
+	GroupKey   string
+	ActorCount int32
+	ReadAt     sql.NullTime
+	ExportID   uuid.NullUUID
+}
+
+type NotificationActor struct {
+	NotificationID uuid.UUID
+	ActorID        uuid.UUID
+	SourceChirpID  uuid.NullUUID
+	CreatedAt      time.Time
+}
+
+type NotificationPreference struct {
+	UserID  uuid.UUID
+	Type    string
+	Enabled bool
+}
+
+type Poll struct {
+	ChirpID    uuid.UUID
+	Multiple   bool
+	ClosesAt   time.Time
+	VoterCount int32
+}
+
+type PollBallot struct {
+	ChirpID   uuid.UUID
 	UserID    uuid.UUID
+	Choices   []int32
+	CreatedAt time.Time
+}
+
+type PollOption struct {
+	ChirpID   uuid.UUID
+	Position  int32
+	Text      string
+	VoteCount int32
 }
 
 type RefreshToken struct {
@@ -28,6 +291,64 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type RemoteActor struct {
+	ID           string
+	KeyID        string
+	PublicKeyPem string
+	Inbox        string
+	SharedInbox  string
+	Username     string
+	FetchedAt    time.Time
+}
+
+type RemoteFollow struct {
+	UserID     uuid.UUID
+	ActorID    string
+	ActivityID string
+	CreatedAt  time.Time
+}
+
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	CaseID     uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Comment    string
+}
+
+type SuspendedUser struct {
+	UserID      uuid.UUID
+	SuspendedAt time.Time
+}
+
+type Timeline struct {
+	UserID  uuid.UUID
+	BuiltAt time.Time
+}
+
+type TimelineEntry struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	AuthorID  uuid.UUID
+	CreatedAt time.Time
+}
+
+type TrendEntry struct {
+	SnapshotID uuid.UUID
+	Rank       int32
+	HashtagID  uuid.UUID
+	Score      float64
+	Uses       int32
+	Authors    int32
+}
+
+type TrendSnapshot struct {
+	ID         uuid.UUID
+	WindowName string
+	ComputedAt time.Time
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -35,4 +356,6 @@ type User struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    bool
+	Username       sql.NullString
+	DeletedAt      sql.NullTime
 }
@@ -0,0 +1,118 @@
+// Package entities extracts hashtags and @mentions from chirp bodies.
+//
+// Offsets are measured in Unicode code points (runes), not bytes, and End is
+// exclusive, so body[Start:End] over []rune(body) yields the entity text
+// including its leading '#' or '@'.
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxUsernameLength is the longest username a mention can refer to.
+const MaxUsernameLength = 30
+
+// Hashtag is a #tag found in a chirp body.
+type Hashtag struct {
+	// Tag is the normalized tag without the leading '#'.
+	Tag   string
+	Start int
+	End   int
+}
+
+// Mention is an @username found in a chirp body.
+type Mention struct {
+	// Username is the normalized username without the leading '@'.
+	Username string
+	Start    int
+	End      int
+}
+
+// Hashtags returns the hashtags in body in order of appearance. A hashtag
+// starts with '#' at the beginning of the body or after a character that
+// can't be part of a word, and must contain at least one letter.
+func Hashtags(body string) []Hashtag {
+	var tags []Hashtag
+	for _, m := range scan(body, '#', isTagRune) {
+		if !strings.ContainsFunc(m.text, unicode.IsLetter) {
+			continue
+		}
+		tags = append(tags, Hashtag{Tag: NormalizeTag(m.text), Start: m.start, End: m.end})
+	}
+	return tags
+}
+
+// Mentions returns the @mentions in body in order of appearance. Email
+// addresses are not mentions because the '@' follows a word character.
+func Mentions(body string) []Mention {
+	var mentions []Mention
+	for _, m := range scan(body, '@', isUsernameRune) {
+		if len(m.text) > MaxUsernameLength {
+			continue
+		}
+		mentions = append(mentions, Mention{Username: NormalizeUsername(m.text), Start: m.start, End: m.end})
+	}
+	return mentions
+}
+
+// NormalizeTag lowercases a tag and strips a leading '#', so "#Go" and
+// "go" name the same hashtag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+// NormalizeUsername lowercases a username and strips a leading '@'.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(username, "@"))
+}
+
+// ValidUsername reports whether username can be referenced by a mention.
+func ValidUsername(username string) bool {
+	if username == "" || len(username) > MaxUsernameLength {
+		return false
+	}
+	for _, r := range username {
+		if !isUsernameRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+func isUsernameRune(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type match struct {
+	text       string
+	start, end int
+}
+
+func scan(body string, sigil rune, valid func(rune) bool) []match {
+	runes := []rune(body)
+	var matches []match
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != sigil || (i > 0 && isWordRune(runes[i-1])) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && valid(runes[j]) {
+			j++
+		}
+		if j == i+1 {
+			continue
+		}
+		matches = append(matches, match{text: string(runes[i+1 : j]), start: i, end: j})
+		i = j - 1
+	}
+	return matches
+}
@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Hashtag
+	}{
+		{
+			name: "Single tag",
+			body: "learning #Go today",
+			want: []Hashtag{{Tag: "go", Start: 9, End: 12}},
+		},
+		{
+			name: "Offsets count runes not bytes",
+			body: "héllo #café!",
+			want: []Hashtag{{Tag: "café", Start: 6, End: 11}},
+		},
+		{
+			name: "Numeric only is not a tag",
+			body: "issue #42",
+			want: nil,
+		},
+		{
+			name: "Sigil inside a word is ignored",
+			body: "c#sharp and #dotnet",
+			want: []Hashtag{{Tag: "dotnet", Start: 12, End: 19}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hashtags(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hashtags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Mention
+	}{
+		{
+			name: "Mention at start",
+			body: "@Alice hi",
+			want: []Mention{{Username: "alice", Start: 0, End: 6}},
+		},
+		{
+			name: "Email address is not a mention",
+			body: "mail bob@example.com or @bob_2.",
+			want: []Mention{{Username: "bob_2", Start: 24, End: 30}},
+		},
+		{
+			name: "Bare sigil",
+			body: "meet @ noon",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Mentions(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
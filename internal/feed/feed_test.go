@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var published = time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+
+var testFeed = Feed{
+	ID:          "urn:uuid:6f1c0d36-8d0e-4c6a-9a63-2f4f1b1d1e11",
+	Title:       "@alice on Chirpy",
+	Description: "Chirps by @alice",
+	Link:        "https://chirpy.example/api/users/6f1c0d36-8d0e-4c6a-9a63-2f4f1b1d1e11",
+	SelfLink:    "https://chirpy.example/users/6f1c0d36-8d0e-4c6a-9a63-2f4f1b1d1e11/feed.rss",
+	Items: []Item{{
+		ID:        "urn:uuid:0b7c6f5e-7a53-4b8e-9b43-3c3b7c1f9a22",
+		Title:     "Fish & chips <3",
+		Link:      "https://chirpy.example/api/chirps/0b7c6f5e-7a53-4b8e-9b43-3c3b7c1f9a22",
+		Content:   "Fish & chips <3",
+		Author:    "alice",
+		Published: published,
+		Updated:   published.Add(time.Hour),
+	}},
+}
+
+func TestRSS(t *testing.T) {
+	out, err := testFeed.RSS()
+	if err != nil {
+		t.Fatalf("RSS() error = %v", err)
+	}
+	var doc rss
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("RSS() isn't valid XML: %v\n%s", err, out)
+	}
+	if doc.Version != "2.0" || doc.Channel.Title != testFeed.Title {
+		t.Errorf("RSS() channel = %+v", doc.Channel)
+	}
+	if len(doc.Channel.Items) != 1 {
+		t.Fatalf("RSS() has %d items, want 1", len(doc.Channel.Items))
+	}
+	item := doc.Channel.Items[0]
+	if item.Description != "Fish & chips <3" || item.GUID.Value != testFeed.Items[0].ID || item.GUID.IsPermaLink {
+		t.Errorf("RSS() item = %+v", item)
+	}
+	if item.PubDate != "Fri, 14 Mar 2025 15:09:26 +0000" {
+		t.Errorf("RSS() pubDate = %q", item.PubDate)
+	}
+	if doc.Channel.LastBuildDate != "" {
+		t.Errorf("RSS() lastBuildDate = %q for a feed without an updated time", doc.Channel.LastBuildDate)
+	}
+	if !strings.Contains(string(out), `rel="self"`) {
+		t.Errorf("RSS() has no self link:\n%s", out)
+	}
+}
+
+func TestAtom(t *testing.T) {
+	out, err := testFeed.Atom()
+	if err != nil {
+		t.Fatalf("Atom() error = %v", err)
+	}
+	var doc atomFeed
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Atom() isn't valid XML: %v\n%s", err, out)
+	}
+	if doc.ID != testFeed.ID || doc.Title != testFeed.Title {
+		t.Errorf("Atom() feed = %+v", doc)
+	}
+	// Without its own updated time the feed takes the newest entry's.
+	if doc.Updated != "2025-03-14T16:09:26Z" {
+		t.Errorf("Atom() updated = %q, want the entry's", doc.Updated)
+	}
+	if len(doc.Entries) != 1 {
+		t.Fatalf("Atom() has %d entries, want 1", len(doc.Entries))
+	}
+	entry := doc.Entries[0]
+	if entry.Author.Name != "alice" || entry.Content.Value != "Fish & chips <3" || entry.Published != "2025-03-14T15:09:26Z" {
+		t.Errorf("Atom() entry = %+v", entry)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"  spaced \t  out  ", 20, "spaced out"},
+		{"first line\nsecond line", 40, "first line"},
+		{"exactly ten", 11, "exactly ten"},
+		{"a little too long", 10, "a little…"},
+		{"héllo wörld", 6, "héllo…"},
+	}
+	for _, tt := range tests {
+		if got := Title(tt.text, tt.max); got != tt.want {
+			t.Errorf("Title(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package httpsig
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const keyID = "https://remote.example/users/alice#main-key"
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signedPost(t *testing.T, key *rsa.PrivateKey, body string) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "https://chirpy.example/ap/users/1/inbox", strings.NewReader(body))
+	if err := Sign(r, keyID, key, []byte(body)); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return r
+}
+
+func TestSignVerify(t *testing.T) {
+	key := newKey(t)
+	body := `{"type":"Follow"}`
+	r := signedPost(t, key, body)
+
+	got, err := KeyID(r)
+	if err != nil || got != keyID {
+		t.Fatalf("KeyID() = %q, %v, want %q", got, err, keyID)
+	}
+	if err := Verify(r, []byte(body), &key.PublicKey, time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if !strings.Contains(r.Header.Get("Signature"), `headers="(request-target) host date digest"`) {
+		t.Errorf("Signature = %q, want it to cover the digest", r.Header.Get("Signature"))
+	}
+}
+
+func TestSignGet(t *testing.T) {
+	key := newKey(t)
+	r := httptest.NewRequest(http.MethodGet, "https://remote.example/users/alice", nil)
+	if err := Sign(r, keyID, key, nil); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if r.Header.Get("Digest") != "" {
+		t.Errorf("Digest = %q for a request without a body", r.Header.Get("Digest"))
+	}
+	if err := Verify(r, nil, &key.PublicKey, time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	key := newKey(t)
+	body := `{"type":"Follow"}`
+	tests := []struct {
+		name   string
+		modify func(r *http.Request) (body string, pub *rsa.PublicKey)
+	}{
+		{"other key", func(r *http.Request) (string, *rsa.PublicKey) {
+			return body, &newKey(t).PublicKey
+		}},
+		{"changed body", func(r *http.Request) (string, *rsa.PublicKey) {
+			return `{"type":"Undo"}`, &key.PublicKey
+		}},
+		{"changed body and digest", func(r *http.Request) (string, *rsa.PublicKey) {
+			r.Header.Set("Digest", Digest([]byte(`{"type":"Undo"}`)))
+			return `{"type":"Undo"}`, &key.PublicKey
+		}},
+		{"other path", func(r *http.Request) (string, *rsa.PublicKey) {
+			r.URL.Path = "/ap/users/2/inbox"
+			return body, &key.PublicKey
+		}},
+		{"other host", func(r *http.Request) (string, *rsa.PublicKey) {
+			r.Host = "evil.example"
+			return body, &key.PublicKey
+		}},
+		{"stale date", func(r *http.Request) (string, *rsa.PublicKey) {
+			stale := newKey(t)
+			r.Header.Del("Signature")
+			if err := Sign(r, keyID, stale, []byte(body)); err != nil {
+				t.Fatal(err)
+			}
+			r.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+			return body, &stale.PublicKey
+		}},
+		{"digest not signed", func(r *http.Request) (string, *rsa.PublicKey) {
+			r.Header.Set("Signature", strings.Replace(r.Header.Get("Signature"), " digest", "", 1))
+			return body, &key.PublicKey
+		}},
+		{"unsigned", func(r *http.Request) (string, *rsa.PublicKey) {
+			r.Header.Del("Signature")
+			return body, &key.PublicKey
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signedPost(t, key, body)
+			body, pub := tt.modify(r)
+			err := Verify(r, []byte(body), pub, time.Minute)
+			if !errors.Is(err, ErrInvalidSignature) && !errors.Is(err, ErrNoSignature) {
+				t.Errorf("Verify() error = %v, want a signature error", err)
+			}
+		})
+	}
+}
+
+func TestParseQuotedCommas(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Signature", `keyId="https://remote.example/key,1",headers="date",signature="AAAA"`)
+	got, err := KeyID(r)
+	if err != nil || got != "https://remote.example/key,1" {
+		t.Errorf("KeyID() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformedGIF = errors.New("imaging: malformed gif")
+
+// gifPixels adds up the sizes of a GIF's frames from their image
+// descriptors, without decompressing them, so an animation can be refused
+// before its frames are allocated. It stops counting once the total passes
+// MaxPixels.
+func gifPixels(data []byte) (int, error) {
+	// Header and logical screen descriptor.
+	if len(data) < 13 || string(data[:3]) != "GIF" {
+		return 0, errMalformedGIF
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+	pixels := 0
+	for pos < len(data) && pixels <= MaxPixels {
+		switch data[pos] {
+		case 0x21: // Extension: label, then data sub-blocks.
+			var ok bool
+			if pos, ok = skipSubBlocks(data, pos+2); !ok {
+				return 0, errMalformedGIF
+			}
+		case 0x2C: // Image descriptor, then an optional color table and the image data.
+			if pos+10 > len(data) {
+				return 0, errMalformedGIF
+			}
+			width := int(binary.LittleEndian.Uint16(data[pos+5:]))
+			height := int(binary.LittleEndian.Uint16(data[pos+7:]))
+			pixels += width * height
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			// Skip the LZW minimum code size.
+			var ok bool
+			if pos, ok = skipSubBlocks(data, pos+1); !ok {
+				return 0, errMalformedGIF
+			}
+		case 0x3B: // Trailer.
+			return pixels, nil
+		default:
+			return 0, errMalformedGIF
+		}
+	}
+	if pixels > MaxPixels {
+		return pixels, nil
+	}
+	return 0, errMalformedGIF
+}
+
+// skipSubBlocks returns the position after the data sub-blocks starting at
+// pos, which end with an empty block.
+func skipSubBlocks(data []byte, pos int) (int, bool) {
+	for pos < len(data) {
+		n := int(data[pos])
+		pos++
+		if n == 0 {
+			return pos, true
+		}
+		pos += n
+	}
+	return 0, false
+}
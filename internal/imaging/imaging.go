@@ -0,0 +1,174 @@
+// Package imaging prepares uploaded images for publishing: it applies the
+// EXIF orientation, drops all metadata by re-encoding the pixels, generates
+// thumbnails and computes a BlurHash placeholder.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// MaxPixels bounds the decoded size of an image so a small, highly
+// compressed upload can't exhaust memory.
+const MaxPixels = 40_000_000
+
+// ThumbnailSizes are the thumbnails generated for every image, each fitting
+// in a MaxSize×MaxSize box.
+var ThumbnailSizes = []struct {
+	Name    string
+	MaxSize int
+}{
+	{"small", 150},
+	{"medium", 600},
+	{"large", 1200},
+}
+
+// ErrTooLarge is returned for images with more than MaxPixels pixels.
+var ErrTooLarge = errors.New("imaging: image dimensions too large")
+
+// Rendition is an encoded image ready to be stored.
+type Rendition struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Result is the output of Process.
+type Result struct {
+	// Original is the full-size image, upright and without metadata.
+	Original   Rendition
+	Thumbnails []Rendition
+	BlurHash   string
+}
+
+// Process decodes an uploaded JPEG, PNG or GIF and produces its published
+// renditions. Every format is re-encoded, which discards EXIF, GPS, XMP,
+// comments and any other embedded metadata. GIFs are re-encoded frame by
+// frame so animations survive; their thumbnails use the first frame.
+func Process(data []byte) (*Result, error) {
+	if err := CheckSize(data); err != nil {
+		return nil, err
+	}
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imaging: decoding image: %w", err)
+	}
+
+	img := toRGBA(decoded)
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	result := &Result{}
+	switch format {
+	case "gif":
+		result.Original, err = reencodeGIF(data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		result.Original, err = encode("original", img, format)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	thumbFormat := format
+	if format == "gif" {
+		thumbFormat = "png"
+	}
+	for _, size := range ThumbnailSizes {
+		w, h := fit(img.Bounds().Dx(), img.Bounds().Dy(), size.MaxSize)
+		thumb, err := encode(size.Name, resize(img, w, h), thumbFormat)
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails = append(result.Thumbnails, thumb)
+	}
+
+	w, h := fit(img.Bounds().Dx(), img.Bounds().Dy(), 32)
+	result.BlurHash = blurHash(resize(img, w, h), 4, 3)
+	return result, nil
+}
+
+// CheckSize returns ErrTooLarge if decoding data would produce more than
+// MaxPixels pixels, counting every frame of an animated GIF. Only headers
+// are read, so it is cheap enough to run on upload.
+func CheckSize(data []byte) error {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("imaging: reading image header: %w", err)
+	}
+	pixels := config.Width * config.Height
+	if format == "gif" {
+		if pixels, err = gifPixels(data); err != nil {
+			return err
+		}
+	}
+	if pixels > MaxPixels {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+func encode(name string, img *image.RGBA, format string) (Rendition, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return Rendition{}, fmt.Errorf("imaging: encoding %s: %w", name, err)
+	}
+	return Rendition{
+		Name:        name,
+		Data:        buf.Bytes(),
+		ContentType: http.DetectContentType(buf.Bytes()),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}, nil
+}
+
+// reencodeGIF rewrites an animated GIF from its decoded frames. Only the
+// frames, their timing and disposal, the palette and the loop count are
+// kept; comment and application extensions such as XMP are dropped. The
+// caller has checked the frames' total size with CheckSize.
+func reencodeGIF(data []byte) (Rendition, error) {
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return Rendition{}, fmt.Errorf("imaging: decoding gif frames: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, &gif.GIF{
+		Image:           anim.Image,
+		Delay:           anim.Delay,
+		Disposal:        anim.Disposal,
+		LoopCount:       anim.LoopCount,
+		Config:          anim.Config,
+		BackgroundIndex: anim.BackgroundIndex,
+	}); err != nil {
+		return Rendition{}, fmt.Errorf("imaging: encoding original: %w", err)
+	}
+	return Rendition{
+		Name:        "original",
+		Data:        buf.Bytes(),
+		ContentType: "image/gif",
+		Width:       anim.Config.Width,
+		Height:      anim.Config.Height,
+	}, nil
+}
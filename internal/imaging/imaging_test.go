@@ -0,0 +1,187 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+)
+
+// jpegWithOrientation encodes a w×h JPEG and inserts an EXIF segment with
+// the given orientation and a GPS IFD pointer right after the SOI marker.
+func jpegWithOrientation(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 10), G: uint8(y * 10), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	// Orientation, SHORT, count 1
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0x00, 0x00)
+	// GPSInfo IFD pointer, LONG, count 1
+	tiff = append(tiff, 0x88, 0x25, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	jpg := buf.Bytes()
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for _, o := range []int{1, 3, 6, 8} {
+		if got := jpegOrientation(jpegWithOrientation(t, 4, 2, o)); got != o {
+			t.Errorf("jpegOrientation() = %d, want %d", got, o)
+		}
+	}
+	if got := jpegOrientation([]byte("not a jpeg")); got != 1 {
+		t.Errorf("jpegOrientation() on garbage = %d, want 1", got)
+	}
+}
+
+func TestProcessRotatesAndStripsMetadata(t *testing.T) {
+	data := jpegWithOrientation(t, 40, 20, 6)
+
+	result, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.Original.Width != 20 || result.Original.Height != 40 {
+		t.Errorf("Original is %dx%d, want 20x40", result.Original.Width, result.Original.Height)
+	}
+	if bytes.Contains(result.Original.Data, []byte("Exif")) {
+		t.Error("Original still contains EXIF data")
+	}
+	if result.Original.ContentType != "image/jpeg" {
+		t.Errorf("Original content type = %q, want image/jpeg", result.Original.ContentType)
+	}
+	if len(result.Thumbnails) != len(ThumbnailSizes) {
+		t.Fatalf("got %d thumbnails, want %d", len(result.Thumbnails), len(ThumbnailSizes))
+	}
+	if len(result.BlurHash) != 6+2*(4*3-1) {
+		t.Errorf("BlurHash %q has length %d", result.BlurHash, len(result.BlurHash))
+	}
+}
+
+func TestProcessReencodesAnimatedGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{LoopCount: 0}
+	for i := 0; i < 2; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 8, 4), palette)
+		frame.SetColorIndex(i, 0, 1)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	// Add a comment extension just before the trailer.
+	data := buf.Bytes()
+	comment := []byte("taken at 51.5007N 0.1246W")
+	withComment := append([]byte{}, data[:len(data)-1]...)
+	withComment = append(withComment, 0x21, 0xFE, byte(len(comment)))
+	withComment = append(withComment, comment...)
+	withComment = append(withComment, 0x00, 0x3B)
+
+	result, err := Process(withComment)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if bytes.Contains(result.Original.Data, comment) {
+		t.Error("Original still contains the GIF comment")
+	}
+	out, err := gif.DecodeAll(bytes.NewReader(result.Original.Data))
+	if err != nil {
+		t.Fatalf("decoding original: %v", err)
+	}
+	if len(out.Image) != 2 {
+		t.Errorf("Original has %d frames, want 2", len(out.Image))
+	}
+	if result.Original.Width != 8 || result.Original.Height != 4 {
+		t.Errorf("Original is %dx%d, want 8x4", result.Original.Width, result.Original.Height)
+	}
+}
+
+func TestCheckSizeCountsGIFFrames(t *testing.T) {
+	// A 5000x5000 screen with n empty frames covering it: tiny on disk,
+	// 25 million pixels a frame once decoded.
+	frames := func(n int) []byte {
+		data := []byte("GIF89a\x88\x13\x88\x13\x00\x00\x00")
+		for i := 0; i < n; i++ {
+			data = append(data, 0x2C, 0, 0, 0, 0, 0x88, 0x13, 0x88, 0x13, 0x00, 0x02, 0x00)
+		}
+		return append(data, 0x3B)
+	}
+	if err := CheckSize(frames(1)); err != nil {
+		t.Errorf("CheckSize() of one frame error = %v", err)
+	}
+	if err := CheckSize(frames(2)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("CheckSize() of two frames error = %v, want ErrTooLarge", err)
+	}
+	if err := CheckSize(frames(2)[:30]); err == nil {
+		t.Error("CheckSize() accepted a truncated GIF")
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	src.Set(1, 0, color.RGBA{B: 255, A: 255})
+
+	// Rotating [red blue] clockwise puts red on top.
+	got := orient(src, 6)
+	if got.Bounds().Dx() != 1 || got.Bounds().Dy() != 2 {
+		t.Fatalf("orient(6) size = %v, want 1x2", got.Bounds())
+	}
+	if r, _, _, _ := got.At(0, 0).RGBA(); r == 0 {
+		t.Error("orient(6) top pixel is not red")
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h, max    int
+		wantW, wantH int
+	}{
+		{4000, 3000, 1200, 1200, 900},
+		{3000, 4000, 600, 450, 600},
+		{100, 50, 150, 100, 50},
+		{10000, 1, 150, 150, 1},
+	}
+	for _, tt := range tests {
+		w, h := fit(tt.w, tt.h, tt.max)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fit(%d, %d, %d) = %d, %d, want %d, %d", tt.w, tt.h, tt.max, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestBlurHashSolidColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	got := blurHash(img, 4, 3)
+	// "L" encodes 4x3 components and "TSUA" the white average color.
+	if len(got) != 28 || got[0] != 'L' || got[2:6] != "TSUA" {
+		t.Errorf("blurHash() = %q, want 28 characters starting with L?TSUA", got)
+	}
+}
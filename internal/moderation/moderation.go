@@ -0,0 +1,238 @@
+// Package moderation checks chirp text against an ordered list of rules.
+//
+// Word rules compare each word of the text, after normalization, with a
+// word list. Regex rules match the text directly. Every rule has an action:
+// mask replaces the offending text with asterisks, reject refuses the text
+// and flag lets it through but reports it for review.
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Action is what happens when a rule matches.
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionReject Action = "reject"
+	ActionFlag   Action = "flag"
+)
+
+// Kind is how a rule matches text.
+type Kind string
+
+const (
+	KindWords Kind = "words"
+	KindRegex Kind = "regex"
+)
+
+// Mask is the replacement for masked text.
+const Mask = "****"
+
+// Rule is a single moderation rule.
+type Rule struct {
+	// ID identifies the rule in matches.
+	ID     string
+	Kind   Kind
+	Action Action
+	// Words is the word list of a KindWords rule.
+	Words []string
+	// Pattern is the regular expression of a KindRegex rule. It is matched
+	// case-insensitively.
+	Pattern string
+}
+
+// Match is a rule that matched some text.
+type Match struct {
+	RuleID string
+	Action Action
+	// Text is the matched text as written.
+	Text string
+}
+
+// Result is the outcome of checking a text.
+type Result struct {
+	// Text is the input with masked words replaced.
+	Text string
+	// Rejected is set when a reject rule matched. Rules after it are not
+	// evaluated.
+	Rejected bool
+	// Matches lists every match in rule order.
+	Matches []Match
+}
+
+// Flags returns the matches of flag rules.
+func (r Result) Flags() []Match {
+	var flags []Match
+	for _, m := range r.Matches {
+		if m.Action == ActionFlag {
+			flags = append(flags, m)
+		}
+	}
+	return flags
+}
+
+// Pipeline is a compiled, immutable list of rules. It is safe for
+// concurrent use.
+type Pipeline struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	// words holds the normalized word list.
+	words map[string]struct{}
+	// squeezed maps each normalized word with repeated letters collapsed
+	// to the shortest length a spelling of it can have.
+	squeezed map[string]int
+	re       *regexp.Regexp
+}
+
+// New compiles rules into a pipeline. Rules are applied in the given order.
+func New(rules []Rule) (*Pipeline, error) {
+	p := &Pipeline{}
+	for _, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, c)
+	}
+	return p, nil
+}
+
+func compile(rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+	switch rule.Action {
+	case ActionMask, ActionReject, ActionFlag:
+	default:
+		return c, fmt.Errorf("moderation: rule %s: unknown action %q", rule.ID, rule.Action)
+	}
+	switch rule.Kind {
+	case KindWords:
+		c.words = make(map[string]struct{}, len(rule.Words))
+		c.squeezed = make(map[string]int, len(rule.Words))
+		for _, word := range rule.Words {
+			normalized, err := NormalizeWord(word)
+			if err != nil {
+				return c, fmt.Errorf("moderation: rule %s: %w", rule.ID, err)
+			}
+			c.words[normalized] = struct{}{}
+			s := squeeze(normalized)
+			if n, ok := c.squeezed[s]; !ok || len(normalized) < n {
+				c.squeezed[s] = len(normalized)
+			}
+		}
+	case KindRegex:
+		re, err := CompilePattern(rule.Pattern)
+		if err != nil {
+			return c, fmt.Errorf("moderation: rule %s: %w", rule.ID, err)
+		}
+		c.re = re
+	default:
+		return c, fmt.Errorf("moderation: rule %s: unknown kind %q", rule.ID, rule.Kind)
+	}
+	return c, nil
+}
+
+// ErrInvalidWord is returned for word list entries that can never match a
+// single word.
+var ErrInvalidWord = errors.New("word must be a single word without spaces or punctuation")
+
+// NormalizeWord validates a word list entry and returns its normalized
+// form.
+func NormalizeWord(word string) (string, error) {
+	tokens := tokenize(strings.TrimSpace(word))
+	if len(tokens) != 1 || tokens[0].text != strings.TrimSpace(word) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidWord, word)
+	}
+	return Normalize(tokens[0].text), nil
+}
+
+// CompilePattern compiles a regex rule pattern.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// Check runs text through the pipeline.
+func (p *Pipeline) Check(text string) Result {
+	result := Result{Text: text}
+	for _, rule := range p.rules {
+		var spans [][2]int
+		switch rule.Kind {
+		case KindWords:
+			spans = rule.matchWords(result.Text)
+		case KindRegex:
+			for _, loc := range rule.re.FindAllStringIndex(result.Text, -1) {
+				if loc[0] < loc[1] {
+					spans = append(spans, [2]int{loc[0], loc[1]})
+				}
+			}
+		}
+		if len(spans) == 0 {
+			continue
+		}
+		for _, span := range spans {
+			result.Matches = append(result.Matches, Match{
+				RuleID: rule.ID,
+				Action: rule.Action,
+				Text:   result.Text[span[0]:span[1]],
+			})
+		}
+		switch rule.Action {
+		case ActionReject:
+			result.Rejected = true
+			return result
+		case ActionMask:
+			result.Text = mask(result.Text, spans)
+		}
+	}
+	return result
+}
+
+func (r compiledRule) matchWords(text string) [][2]int {
+	var spans [][2]int
+	for _, t := range tokenize(text) {
+		if r.matchWord(t.text) {
+			spans = append(spans, [2]int{t.start, t.end})
+			continue
+		}
+		// "@kerfuffle" is a mention of a bad word rather than a leet
+		// spelling of one.
+		if trimmed := strings.TrimLeft(t.text, "@"); trimmed != t.text && trimmed != "" && r.matchWord(trimmed) {
+			spans = append(spans, [2]int{t.end - len(trimmed), t.end})
+		}
+	}
+	return spans
+}
+
+func (r compiledRule) matchWord(word string) bool {
+	normalized := Normalize(word)
+	if _, ok := r.words[normalized]; ok {
+		return true
+	}
+	// Stretched spellings like "kerfuuuffle" match as long as they are at
+	// least as long as the listed word, so "as" doesn't match "ass".
+	n, ok := r.squeezed[squeeze(normalized)]
+	return ok && len(normalized) >= n
+}
+
+// mask replaces the given non-overlapping, ordered byte ranges of text.
+func mask(text string, spans [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(text[last:span[0]])
+		b.WriteString(Mask)
+		last = span[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
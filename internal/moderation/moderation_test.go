@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckMasksWords(t *testing.T) {
+	p, err := New([]Rule{{ID: "default", Kind: KindWords, Action: ActionMask, Words: []string{"kerfuffle", "sharbert", "fornax"}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Plain", "what a kerfuffle today", "what a **** today"},
+		{"Trailing punctuation", "what a kerfuffle!", "what a ****!"},
+		{"Case", "Sharbert, FORNAX.", "****, ****."},
+		{"Leet speak", "k3rfuffl3 and $h4rb3rt", "**** and ****"},
+		{"Accents and fullwidth", "kérfüffle ｆｏｒｎａｘ", "**** ****"},
+		{"Cyrillic look-alikes", "fоrnах", "****"},
+		{"Zero-width space", "kerf\u200buffle", "****"},
+		{"Stretched", "kerfuuuuffle", "****"},
+		{"Mention keeps sigil", "hi @fornax", "hi @****"},
+		{"Part of a longer word", "fornaxes are fine", "fornaxes are fine"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Check(tt.text)
+			if got.Text != tt.want {
+				t.Errorf("Check(%q).Text = %q, want %q", tt.text, got.Text, tt.want)
+			}
+			if got.Rejected {
+				t.Errorf("Check(%q) rejected", tt.text)
+			}
+		})
+	}
+}
+
+func TestCheckSqueezeNeedsFullLength(t *testing.T) {
+	p, err := New([]Rule{{ID: "r", Kind: KindWords, Action: ActionMask, Words: []string{"ass"}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := p.Check("as if").Text; got != "as if" {
+		t.Errorf("Check() = %q, want unchanged", got)
+	}
+	if got := p.Check("asssss").Text; got != Mask {
+		t.Errorf("Check() = %q, want %q", got, Mask)
+	}
+}
+
+func TestCheckRuleOrderAndActions(t *testing.T) {
+	p, err := New([]Rule{
+		{ID: "mask", Kind: KindWords, Action: ActionMask, Words: []string{"fornax"}},
+		{ID: "flag", Kind: KindRegex, Action: ActionFlag, Pattern: `buy\s+now`},
+		{ID: "reject", Kind: KindRegex, Action: ActionReject, Pattern: `\bspam\b`},
+		{ID: "never", Kind: KindWords, Action: ActionFlag, Words: []string{"spam"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got := p.Check("fornax BUY  now")
+	if got.Rejected || got.Text != "**** BUY  now" {
+		t.Errorf("Check() = %+v", got)
+	}
+	wantFlags := []Match{{RuleID: "flag", Action: ActionFlag, Text: "BUY  now"}}
+	if !reflect.DeepEqual(got.Flags(), wantFlags) {
+		t.Errorf("Flags() = %v, want %v", got.Flags(), wantFlags)
+	}
+
+	got = p.Check("this is Spam")
+	if !got.Rejected {
+		t.Fatalf("Check() not rejected")
+	}
+	want := []Match{{RuleID: "reject", Action: ActionReject, Text: "Spam"}}
+	if !reflect.DeepEqual(got.Matches, want) {
+		t.Errorf("Matches = %v, want %v", got.Matches, want)
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"Unknown action", Rule{Kind: KindWords, Action: "delete"}},
+		{"Unknown kind", Rule{Kind: "glob", Action: ActionMask}},
+		{"Bad regex", Rule{Kind: KindRegex, Action: ActionMask, Pattern: "("}},
+		{"Phrase in word list", Rule{Kind: KindWords, Action: ActionMask, Words: []string{"two words"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]Rule{tt.rule}); err == nil {
+				t.Errorf("New() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// leet maps digits and symbols commonly used in place of letters.
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+}
+
+// confusables maps Cyrillic and Greek letters that render like Latin ones.
+// NFKD doesn't fold these because they are distinct letters.
+var confusables = map[rune]rune{
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j',
+	'ѕ': 's', 'α': 'a', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x', 'ν': 'v',
+}
+
+// Normalize folds a word to the form used for matching: compatibility
+// decomposition (so fullwidth and stylized letters become plain ones),
+// lowercase, accents and invisible format characters removed, look-alike
+// letters and leet-speak substitutions mapped to ASCII.
+func Normalize(word string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(word) {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if c, ok := confusables[r]; ok {
+			r = c
+		} else if l, ok := leet[r]; ok {
+			r = l
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// squeeze collapses runs of the same rune, so "kerfuuuffle" and "kerfuffle"
+// compare equal.
+func squeeze(s string) string {
+	var b strings.Builder
+	var last rune = -1
+	for _, r := range s {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+type token struct {
+	// start and end are byte offsets into the text; end is exclusive.
+	start, end int
+	text       string
+}
+
+// isWordRune reports whether r can be part of a word. '@' and '$' are
+// included because they stand in for letters; format characters are
+// included so zero-width spaces can't split a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r) ||
+		unicode.Is(unicode.Cf, r) || r == '@' || r == '$'
+}
+
+// tokenize splits text into words. Punctuation and whitespace separate
+// words and are never part of one, so "kerfuffle!" yields "kerfuffle".
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{start: start, end: i, text: text[start:i]})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{start: start, end: len(text), text: text[start:]})
+	}
+	return tokens
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalDisk stores objects as files below Dir. It also serves them over
+// HTTP, so it can be mounted at BaseURL.
+type LocalDisk struct {
+	Dir string
+	// BaseURL is the URL prefix the handler is mounted at, e.g. "/media".
+	BaseURL string
+}
+
+// NewLocalDisk returns a LocalDisk rooted at dir, creating it if needed.
+func NewLocalDisk(dir, baseURL string) (*LocalDisk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating media directory: %w", err)
+	}
+	return &LocalDisk{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (d *LocalDisk) path(key string) string {
+	return filepath.Join(d.Dir, filepath.FromSlash(key))
+}
+
+// Put writes the object to a temporary file first so readers never see a
+// partially written object.
+func (d *LocalDisk) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.CopyN(tmp, body, size); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *LocalDisk) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (d *LocalDisk) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (d *LocalDisk) URL(key string) string {
+	return d.BaseURL + "/" + key
+}
+
+// ServeHTTP serves the object named by the request path, relative to the
+// prefix the handler is mounted at. Directories are never listed, and
+// private objects are never served.
+func (d *LocalDisk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if validKey(key) != nil || isPrivate(key) {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(d.path(key))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
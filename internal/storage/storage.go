@@ -0,0 +1,65 @@
+// Package storage keeps uploaded media blobs. Implementations are addressed
+// by key, a slash-separated relative path such as "3f0c.../original.jpg".
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no object exists for the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage stores and serves media objects.
+type Storage interface {
+	// Put stores size bytes read from body under key, replacing any
+	// existing object.
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// Get opens the object stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key. Deleting a missing object is not
+	// an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL clients use to download the object.
+	URL(key string) string
+}
+
+// PrivatePrefix starts the keys of objects that are only read through the
+// application, such as data exports. LocalDisk doesn't serve them and S3
+// stores them with a private ACL, so URL must not be used for them.
+const PrivatePrefix = "private/"
+
+// isPrivate reports whether key is under PrivatePrefix. Case is ignored
+// because on a case-insensitive file system "PRIVATE/..." opens the same
+// file.
+func isPrivate(key string) bool {
+	return len(key) >= len(PrivatePrefix) && strings.EqualFold(key[:len(PrivatePrefix)], PrivatePrefix)
+}
+
+// validKey rejects keys that could escape the storage root or that would
+// need escaping in a URL. Keys may only use letters, digits, '-', '_', '.'
+// and '/' as a separator.
+func validKey(key string) error {
+	if key == "" || strings.ContainsFunc(key, invalidKeyRune) {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return nil
+}
+
+func invalidKeyRune(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return false
+	case r == '-' || r == '_' || r == '.' || r == '/':
+		return false
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+package textlength
+
+import "github.com/rivo/uniseg"
+
+// Graphemes returns the number of user-perceived characters in s: extended
+// grapheme clusters as defined by UAX #29, so an emoji with a skin tone, a
+// family emoji joined with ZWJs, a flag or a letter followed by combining
+// accents each count once. Invalid UTF-8 bytes count as one character each.
+func Graphemes(s string) int {
+	return uniseg.GraphemeClusterCount(s)
+}
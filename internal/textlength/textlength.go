@@ -0,0 +1,28 @@
+// Package textlength measures chirp length the way users perceive it:
+// in grapheme clusters rather than bytes or code points, with every link
+// counting as a fixed number of characters regardless of its length.
+package textlength
+
+import (
+	"regexp"
+)
+
+// URLWeight is how many characters a link counts as. Links are usually
+// shortened when displayed, so a long link shouldn't use up the chirp.
+const URLWeight = 23
+
+// urlPattern finds http and https links. Trailing punctuation is left out
+// so "see https://example.com." ends the link before the period.
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]*[^\s<>".,;:!?'")\]}]`)
+
+// Count returns the weighted length of text: the number of grapheme
+// clusters outside links plus URLWeight for each link.
+func Count(text string) int {
+	count := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		count += Graphemes(text[last:loc[0]]) + URLWeight
+		last = loc[1]
+	}
+	return count + Graphemes(text[last:])
+}
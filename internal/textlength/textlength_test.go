@@ -0,0 +1,63 @@
+package textlength
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"Empty", "", 0},
+		{"ASCII", "hello", 5},
+		{"Precomposed accent", "café", 4},
+		{"Combining accent", "cafe\u0301", 4},
+		{"CRLF", "a\r\nb", 3},
+		{"Emoji", "👍👍", 2},
+		{"Skin tone", "👍🏽", 1},
+		{"ZWJ family", "👨\u200d👩\u200d👧\u200d👦", 1},
+		{"Flags", "🇺🇿🇯🇵", 2},
+		{"Odd regional indicator", "🇺🇿🇯", 2},
+		{"Variation selector", "❤️", 1},
+		{"Hangul jamo", "\u1100\u1161\u11a8", 1},
+		{"Hangul syllables", "한국어", 3},
+		{"Devanagari spacing mark", "कि", 1},
+		{"Tamil spacing mark", "நி", 1},
+		{"Thai tone mark", "ส้ม", 2},
+		{"Prepend", "\u06001", 1},
+		{"Invalid UTF-8", "a\xffb", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Graphemes(tt.text); got != tt.want {
+				t.Errorf("Graphemes(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	longURL := "https://example.com/" + strings.Repeat("a", 100)
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"No links", "hello 👋🏽", 7},
+		{"Link", "read " + longURL, 5 + URLWeight},
+		{"Short link still counts the full weight", "http://x.io", URLWeight},
+		{"Trailing period is not part of the link", "see https://example.com.", 4 + URLWeight + 1},
+		{"Two links", "https://a.com https://b.com", 2*URLWeight + 1},
+		{"Not a link", "ftp://example.com", 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.text); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
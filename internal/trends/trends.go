@@ -0,0 +1,129 @@
+// Package trends ranks hashtags by how much faster they are being used than
+// usual.
+//
+// Each use of a tag within a sliding window counts with a weight that halves
+// every half-life, so a burst in the last few minutes outweighs the same
+// number of uses spread over the whole window. The decayed count is compared
+// with what the tag's baseline rate over a longer period would have produced
+// in the same window, so a tag that is always busy doesn't trend just for
+// being busy.
+package trends
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Window is a sliding window trends are computed over.
+type Window struct {
+	// Name identifies the window in the API, e.g. "1h".
+	Name     string
+	Length   time.Duration
+	HalfLife time.Duration
+}
+
+// Windows are the windows trends are computed for.
+var Windows = []Window{
+	{Name: "1h", Length: time.Hour, HalfLife: 15 * time.Minute},
+	{Name: "24h", Length: 24 * time.Hour, HalfLife: 6 * time.Hour},
+}
+
+// Lookup returns the window with the given name.
+func Lookup(name string) (Window, bool) {
+	for _, w := range Windows {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// Usage is how a tag was used, as input to ranking.
+type Usage struct {
+	Tag string
+	// Ages are how long ago each use in the window happened.
+	Ages []time.Duration
+	// Authors is the number of distinct users behind the uses in the window.
+	Authors int
+	// BaselineUses is the number of uses in the baseline period before the
+	// window.
+	BaselineUses int
+}
+
+// Trend is a ranked tag.
+type Trend struct {
+	Tag     string
+	Score   float64
+	Uses    int
+	Authors int
+}
+
+// Ranker scores and ranks tag usage for one window.
+type Ranker struct {
+	Window Window
+	// Baseline is the length of the period before the window that usual
+	// usage is measured over.
+	Baseline time.Duration
+	// MinAuthors is the fewest distinct authors a tag needs to trend, so one
+	// user can't push a tag up alone.
+	MinAuthors int
+}
+
+// Score returns how far the tag's decayed use count in the window exceeds
+// the count its baseline rate predicts, in standard deviations of a Poisson
+// count with that rate. A tag with no baseline is measured against a rate of
+// zero, smoothed so that a handful of uses doesn't score as infinite.
+func (r Ranker) Score(u Usage) float64 {
+	var decayed float64
+	for _, age := range u.Ages {
+		if age < 0 {
+			age = 0
+		}
+		decayed += r.weight(age)
+	}
+	expected := 0.0
+	if r.Baseline > 0 {
+		rate := float64(u.BaselineUses) / r.Baseline.Seconds()
+		expected = rate * r.weightIntegral()
+	}
+	return (decayed - expected) / math.Sqrt(expected+1)
+}
+
+// Rank returns up to limit tags with a positive score and enough authors,
+// highest score first. Ties are broken by tag so the order is stable.
+func (r Ranker) Rank(usage []Usage, limit int) []Trend {
+	var ranked []Trend
+	for _, u := range usage {
+		if u.Authors < r.MinAuthors {
+			continue
+		}
+		score := r.Score(u)
+		if score <= 0 {
+			continue
+		}
+		ranked = append(ranked, Trend{Tag: u.Tag, Score: score, Uses: len(u.Ages), Authors: u.Authors})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Tag < ranked[j].Tag
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// weight is the contribution of a use that happened age ago.
+func (r Ranker) weight(age time.Duration) float64 {
+	return math.Exp2(-age.Seconds() / r.Window.HalfLife.Seconds())
+}
+
+// weightIntegral is the sum of weights over the window per unit of rate:
+// the decayed count a tag used once a second would have.
+func (r Ranker) weightIntegral() float64 {
+	h := r.Window.HalfLife.Seconds()
+	return h / math.Ln2 * (1 - math.Exp2(-r.Window.Length.Seconds()/h))
+}
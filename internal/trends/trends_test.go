@@ -0,0 +1,90 @@
+package trends
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var hour = Ranker{Window: Windows[0], Baseline: 7 * 24 * time.Hour, MinAuthors: 2}
+
+func uses(n int, age time.Duration) []time.Duration {
+	ages := make([]time.Duration, n)
+	for i := range ages {
+		ages[i] = age
+	}
+	return ages
+}
+
+func TestScoreFavoursRecentUses(t *testing.T) {
+	recent := hour.Score(Usage{Ages: uses(10, time.Minute)})
+	old := hour.Score(Usage{Ages: uses(10, 50*time.Minute)})
+	if recent <= old {
+		t.Errorf("Score(recent) = %v, want more than Score(old) = %v", recent, old)
+	}
+}
+
+func TestScoreHalvesEachHalfLife(t *testing.T) {
+	now := hour.Score(Usage{Ages: uses(8, 0)})
+	later := hour.Score(Usage{Ages: uses(8, 15*time.Minute)})
+	// With no baseline the score is the decayed count itself.
+	if math.Abs(now-8) > 1e-9 || math.Abs(later-4) > 1e-9 {
+		t.Errorf("Score() = %v then %v, want 8 then 4", now, later)
+	}
+}
+
+func TestScoreIsRelativeToBaseline(t *testing.T) {
+	// A tag used 20 times in the last hour, and at that same rate for the
+	// past week, isn't trending.
+	steady := Usage{BaselineUses: 20 * 7 * 24}
+	for i := 0; i < 20; i++ {
+		steady.Ages = append(steady.Ages, time.Duration(i)*3*time.Minute)
+	}
+	if s := hour.Score(steady); math.Abs(s) > 1 {
+		t.Errorf("Score(steady) = %v, want about 0", s)
+	}
+
+	surge := Usage{Ages: uses(20, 5*time.Minute), BaselineUses: 7}
+	if s := hour.Score(surge); s < 5 {
+		t.Errorf("Score(surge) = %v, want a clear surge", s)
+	}
+	if hour.Score(surge) <= hour.Score(Usage{Ages: uses(20, 5*time.Minute), BaselineUses: 20 * 7 * 24}) {
+		t.Error("same uses scored higher against a busier baseline")
+	}
+}
+
+func TestRank(t *testing.T) {
+	usage := []Usage{
+		{Tag: "steady", Ages: uses(5, 30*time.Minute), Authors: 5, BaselineUses: 5000},
+		{Tag: "small", Ages: uses(3, time.Minute), Authors: 3},
+		{Tag: "big", Ages: uses(30, time.Minute), Authors: 12},
+		{Tag: "solo", Ages: uses(50, time.Minute), Authors: 1},
+		{Tag: "tied", Ages: uses(3, time.Minute), Authors: 2},
+	}
+	got := hour.Rank(usage, 10)
+	want := []string{"big", "small", "tied"}
+	if len(got) != len(want) {
+		t.Fatalf("Rank() = %+v, want tags %v", got, want)
+	}
+	for i, tag := range want {
+		if got[i].Tag != tag {
+			t.Errorf("Rank()[%d].Tag = %q, want %q", i, got[i].Tag, tag)
+		}
+	}
+	if got[0].Uses != 30 || got[0].Authors != 12 {
+		t.Errorf("Rank()[0] = %+v, want 30 uses by 12 authors", got[0])
+	}
+
+	if got := hour.Rank(usage, 1); len(got) != 1 || got[0].Tag != "big" {
+		t.Errorf("Rank(limit 1) = %+v, want only big", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if w, ok := Lookup("24h"); !ok || w.Length != 24*time.Hour {
+		t.Errorf("Lookup(24h) = %+v, %v", w, ok)
+	}
+	if _, ok := Lookup("7d"); ok {
+		t.Error("Lookup(7d) found a window")
+	}
+}
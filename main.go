@@ -2,13 +2,16 @@ package main
 import _ "github.com/lib/pq"
 import _ "github.com/odilmode/http/docs"
 import (
+	"context"
 	"log"
 	"net/http"
 	"sync/atomic"
+	"time"
 	"os"
 	"database/sql"
 	"github.com/joho/godotenv"
 	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/storage"
 	"github.com/swaggo/http-swagger"
 )
 
@@ -16,10 +19,26 @@ import (
 type apiConfig struct {
 	fileserverHits		atomic.Int32
 	writeHandler		string
+	db			*sql.DB
 	dbQueries		*database.Queries
 	Platform		string
 	jwtSecret		string
 	polkaKey		string
+	adminKey		string
+	timelines		*timelineFanout
+	media			storage.Storage
+	mediaProcessor		*mediaProcessor
+	moderator		*contentModerator
+	publisher		*chirpPublisher
+	notifications		*notifier
+	stream			*chirpStream
+	realtime		*realtimeHub
+	federation		*federation
+	// publicURL is the scheme and host, with no trailing slash, that links
+	// in responses are made from.
+	publicURL		string
+	exporter		*dataExporter
+	restoreWindow		time.Duration
 }
 
 // @title Chirpy API
@@ -62,28 +81,167 @@ func main() {
 		log.Fatal("Polka_Key is not set")
 	}
 	platform := os.Getenv("PLATFORM")
+	// Admin endpoints other than /admin/reset and /admin/metrics are
+	// disabled unless ADMIN_API_KEY is set.
+	adminKey := os.Getenv("ADMIN_API_KEY")
+	// Deleted chirps and accounts can be restored for RESTORE_WINDOW (a Go
+	// duration such as "720h") before they are purged.
+	restoreWindow := defaultRestoreWindow
+	if v := os.Getenv("RESTORE_WINDOW"); v != "" {
+		restoreWindow, err = time.ParseDuration(v)
+		if err != nil || restoreWindow <= 0 {
+			log.Fatalf("invalid RESTORE_WINDOW %q\n", v)
+		}
+	}
+	// ActivityPub federation is enabled when PUBLIC_URL, the URL other
+	// servers reach this one at, is set. It must not change once remote
+	// servers know about it. Links in feeds and other responses are made
+	// from it too, never from the request's Host header; without it they
+	// point at localhost.
+	publicURL := "http://localhost:" + port
+	var fed *federation
+	if v := os.Getenv("PUBLIC_URL"); v != "" {
+		fed, err = newFederation(v, db, dbQueries, platform == "dev")
+		if err != nil {
+			log.Fatalf("invalid PUBLIC_URL %q: %s\n", v, err)
+		}
+		publicURL = fed.baseURL
+	}
+	mediaStorage, mediaHandler, err := newMediaStorage()
+	if err != nil {
+		log.Fatalf("error configuring media storage: %s\n", err)
+	}
 	apiCfg := &apiConfig{
 		fileserverHits: atomic.Int32{},
+		db:		db,
 		dbQueries:	dbQueries,
 		Platform:	platform,
 		jwtSecret:	jwtSecret,
 		polkaKey:	polka,
+		adminKey:	adminKey,
+		timelines:	newTimelineFanout(db, dbQueries),
+		media:		mediaStorage,
+		mediaProcessor:	newMediaProcessor(dbQueries, mediaStorage),
+		moderator:	newContentModerator(dbQueries),
+		notifications:	newNotifier(db, dbQueries),
+		stream:		newChirpStream(dbQueries, dbURL),
+		realtime:	newRealtimeHub(dbURL),
+		federation:	fed,
+		publicURL:	publicURL,
+		restoreWindow:	restoreWindow,
+	}
+	apiCfg.timelines.start(context.Background(), 4)
+	apiCfg.mediaProcessor.start(context.Background(), 2)
+	apiCfg.moderator.start(context.Background())
+	apiCfg.publisher = newChirpPublisher(dbQueries, apiCfg.timelines, apiCfg.notifications, apiCfg.federation)
+	apiCfg.publisher.start(context.Background())
+	newPurger(dbQueries, mediaStorage, restoreWindow).start(context.Background())
+	apiCfg.exporter = newDataExporter(dbQueries, mediaStorage, apiCfg.notifications)
+	apiCfg.exporter.start(context.Background())
+	newTrendsJob(db, dbQueries).start(context.Background())
+	apiCfg.stream.start(context.Background())
+	apiCfg.realtime.start(context.Background())
+	if apiCfg.federation != nil {
+		apiCfg.federation.start(context.Background())
 	}
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
 	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", fs)))
+	if mediaHandler != nil {
+		mux.Handle("GET /media/", http.StripPrefix("/media", mediaHandler))
+	}
 	mux.HandleFunc("GET /api/healthz", handleReadiness)
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handleMetrics)
 	mux.HandleFunc("POST /admin/reset", apiCfg.resetMetrics)
+	mux.HandleFunc("GET /admin/moderation/rules", apiCfg.handleListModerationRules)
+	mux.HandleFunc("POST /admin/moderation/rules", apiCfg.handleCreateModerationRule)
+	mux.HandleFunc("PUT /admin/moderation/rules/{ruleID}", apiCfg.handleUpdateModerationRule)
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}", apiCfg.handleDeleteModerationRule)
+	mux.HandleFunc("POST /admin/moderation/rules/{ruleID}/words", apiCfg.handleAddModerationWords)
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}/words/{word}", apiCfg.handleDeleteModerationWord)
+	mux.HandleFunc("GET /admin/moderation/flags", apiCfg.handleListModerationFlags)
+	mux.HandleFunc("POST /admin/moderation/flags/{flagID}/resolve", apiCfg.handleResolveModerationFlag)
+	mux.HandleFunc("PUT /admin/moderators/{userID}", apiCfg.handleAddModerator)
+	mux.HandleFunc("DELETE /admin/moderators/{userID}", apiCfg.handleRemoveModerator)
 	mux.HandleFunc("POST /api/users", apiCfg.handleCreateUsers)
 	mux.HandleFunc("POST /api/chirps", apiCfg.handleChirps)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handleGetChirps)
+	mux.HandleFunc("GET /api/chirps/length", apiCfg.handleChirpLengthPolicy)
+	mux.HandleFunc("GET /api/chirps/scheduled", apiCfg.handleGetScheduledChirps)
+	mux.HandleFunc("PUT /api/chirps/scheduled", apiCfg.handleUpdateScheduledChirp)
+	mux.HandleFunc("DELETE /api/chirps/scheduled", apiCfg.handleDeleteScheduledChirp)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handleGetChirp)
 	mux.HandleFunc("POST /api/login", apiCfg.handleLogin)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handleRefresh)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handleRevoke)
 	mux.HandleFunc("PUT /api/users", apiCfg.handlePutUsers)
+	mux.HandleFunc("DELETE /api/users", apiCfg.handleDeleteUser)
+	mux.HandleFunc("POST /api/users/export", apiCfg.handleRequestExport)
+	mux.HandleFunc("GET /api/users/export", apiCfg.handleGetExport)
+	mux.HandleFunc("GET /api/users/export/download", apiCfg.handleDownloadExport)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handleDeleteChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.handleRestoreChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.handleRechirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.handleUnrechirp)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}/bookmark", apiCfg.handleBookmarkChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", apiCfg.handleUnbookmarkChirp)
+	mux.HandleFunc("GET /api/bookmarks", apiCfg.handleGetBookmarks)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.handleVotePoll)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reports", apiCfg.handleReportChirp)
+	mux.HandleFunc("GET /api/moderation/cases", apiCfg.handleListModerationCases)
+	mux.HandleFunc("GET /api/moderation/cases/{caseID}", apiCfg.handleGetModerationCase)
+	mux.HandleFunc("POST /api/moderation/cases/{caseID}/claim", apiCfg.handleClaimModerationCase)
+	mux.HandleFunc("POST /api/moderation/cases/{caseID}/resolve", apiCfg.handleResolveModerationCase)
+	mux.HandleFunc("GET /api/moderation/actions", apiCfg.handleListModerationActions)
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handleWebhooks)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handleGetHashtagChirps)
+	mux.HandleFunc("GET /api/trends", apiCfg.handleGetTrends)
+	mux.HandleFunc("GET /api/stream/chirps", apiCfg.handleStreamChirps)
+	mux.HandleFunc("GET /api/ws", apiCfg.handleWebSocket)
+	mux.HandleFunc("GET /users/{userID}/feed.rss", apiCfg.handleUserFeed)
+	mux.HandleFunc("GET /users/{userID}/feed.atom", apiCfg.handleUserFeed)
+	mux.HandleFunc("GET /hashtags/{tag}/feed.rss", apiCfg.handleHashtagFeed)
+	mux.HandleFunc("GET /hashtags/{tag}/feed.atom", apiCfg.handleHashtagFeed)
+	if apiCfg.federation != nil {
+		mux.HandleFunc("GET /.well-known/webfinger", apiCfg.handleWebFinger)
+		mux.HandleFunc("GET /ap/users/{userID}", apiCfg.handleActor)
+		mux.HandleFunc("GET /ap/users/{userID}/outbox", apiCfg.handleOutbox)
+		mux.HandleFunc("GET /ap/users/{userID}/followers", apiCfg.handleFollowers)
+		mux.HandleFunc("POST /ap/users/{userID}/inbox", apiCfg.handleInbox)
+		mux.HandleFunc("GET /ap/chirps/{chirpID}", apiCfg.handleNote)
+	}
+	mux.HandleFunc("GET /api/users/{userID}/mentions", apiCfg.handleGetMentions)
+	mux.HandleFunc("GET /api/users/{userID}", apiCfg.handleGetUser)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handleFollow)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handleUnfollow)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handleGetFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handleGetFollowing)
+	mux.HandleFunc("POST /api/users/{userID}/block", apiCfg.handleBlock)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", apiCfg.handleUnblock)
+	mux.HandleFunc("GET /api/blocks", apiCfg.handleGetBlocks)
+	mux.HandleFunc("POST /api/users/{userID}/mute", apiCfg.handleMute)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", apiCfg.handleUnmute)
+	mux.HandleFunc("GET /api/mutes", apiCfg.handleGetMutes)
+	mux.HandleFunc("GET /api/notifications", apiCfg.handleGetNotifications)
+	mux.HandleFunc("POST /api/notifications/{notificationID}/read", apiCfg.handleMarkNotificationRead)
+	mux.HandleFunc("POST /api/notifications/read-all", apiCfg.handleMarkAllNotificationsRead)
+	mux.HandleFunc("GET /api/notifications/preferences", apiCfg.handleGetNotificationPreferences)
+	mux.HandleFunc("PUT /api/notifications/preferences", apiCfg.handleUpdateNotificationPreferences)
+	mux.HandleFunc("POST /api/conversations", apiCfg.handleCreateConversation)
+	mux.HandleFunc("GET /api/conversations", apiCfg.handleGetConversations)
+	mux.HandleFunc("GET /api/conversations/{conversationID}", apiCfg.handleGetConversation)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/messages", apiCfg.handleSendMessage)
+	mux.HandleFunc("GET /api/conversations/{conversationID}/messages", apiCfg.handleGetMessages)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/read", apiCfg.handleMarkConversationRead)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/leave", apiCfg.handleLeaveConversation)
+	mux.HandleFunc("GET /api/timeline", apiCfg.handleTimeline)
+	mux.HandleFunc("POST /api/media", apiCfg.handleUploadMedia)
+	mux.HandleFunc("GET /api/media/{mediaID}", apiCfg.handleGetMedia)
+	mux.HandleFunc("POST /api/drafts", apiCfg.handleCreateDraft)
+	mux.HandleFunc("GET /api/drafts", apiCfg.handleGetDrafts)
+	mux.HandleFunc("GET /api/drafts/{draftID}", apiCfg.handleGetDraft)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handleUpdateDraft)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handleDeleteDraft)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.handlePublishDraft)
 
 
 	server := &http.Server{
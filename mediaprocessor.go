@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/imaging"
+	"github.com/odilmode/http/internal/storage"
+)
+
+const (
+	mediaQueueSize  = 256
+	mediaJobTimeout = 2 * time.Minute
+	// mediaSweepInterval is how often uploads that were never processed
+	// (e.g. because the server restarted) are put back on the queue.
+	mediaSweepInterval = 5 * time.Minute
+	// mediaClaimLease is how long an upload stays claimed by the worker
+	// processing it. It outlasts mediaJobTimeout so a live worker never
+	// loses its claim.
+	mediaClaimLease = 2 * mediaJobTimeout
+)
+
+// Processing states of a media item.
+const (
+	mediaPending    = "pending"
+	mediaProcessing = "processing"
+	mediaReady      = "ready"
+	mediaFailed     = "failed"
+)
+
+// mediaProcessor turns raw uploads into published images in the background.
+// The number of workers bounds how many images are decoded at once, which
+// keeps memory use predictable under bursts of large uploads.
+type mediaProcessor struct {
+	queries *database.Queries
+	store   storage.Storage
+	jobs    chan uuid.UUID
+}
+
+func newMediaProcessor(queries *database.Queries, store storage.Storage) *mediaProcessor {
+	return &mediaProcessor{
+		queries: queries,
+		store:   store,
+		jobs:    make(chan uuid.UUID, mediaQueueSize),
+	}
+}
+
+// start launches the workers and the sweeper. They exit when ctx is
+// cancelled.
+func (p *mediaProcessor) start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-p.jobs:
+					p.run(ctx, id)
+				}
+			}
+		}()
+	}
+	go p.sweep(ctx)
+}
+
+// enqueue schedules an upload for processing. If the queue is full the
+// upload stays pending and is picked up by the next sweep.
+func (p *mediaProcessor) enqueue(id uuid.UUID) {
+	select {
+	case p.jobs <- id:
+	default:
+		log.Printf("Media queue full, deferring %s", id)
+	}
+}
+
+// sweep requeues pending uploads at startup and then periodically. Uploads
+// younger than one interval are skipped because they are most likely still
+// queued.
+func (p *mediaProcessor) sweep(ctx context.Context) {
+	ticker := time.NewTicker(mediaSweepInterval)
+	defer ticker.Stop()
+	cutoff := time.Now()
+	for {
+		ids, err := p.queries.ListUnprocessedMedia(ctx, cutoff)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("ListUnprocessedMedia error: %v", err)
+		}
+		for _, id := range ids {
+			p.enqueue(id)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			cutoff = now.Add(-mediaSweepInterval)
+		}
+	}
+}
+
+func (p *mediaProcessor) run(ctx context.Context, id uuid.UUID) {
+	ctx, cancel := context.WithTimeout(ctx, mediaJobTimeout)
+	defer cancel()
+
+	media, err := p.queries.ClaimMediaForProcessing(ctx, database.ClaimMediaForProcessingParams{
+		ID:           id,
+		LeaseSeconds: mediaClaimLease.Seconds(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already processed, or being processed by another worker.
+		return
+	}
+	if err != nil {
+		log.Printf("ClaimMediaForProcessing error: %v", err)
+		return
+	}
+
+	if err := p.process(ctx, media); err != nil {
+		log.Printf("Processing media %s failed: %v", id, err)
+		if ctx.Err() != nil {
+			// Timed out or shutting down; leave it for the next sweep.
+			return
+		}
+		if err := p.queries.FailMediaProcessing(ctx, id); err != nil {
+			log.Printf("FailMediaProcessing error: %v", err)
+		}
+		p.store.Delete(ctx, media.UploadKey)
+	}
+}
+
+// process stores the renditions of an upload and marks it ready. The raw
+// upload, which may still carry EXIF and GPS data, is deleted afterwards.
+func (p *mediaProcessor) process(ctx context.Context, media database.Medium) error {
+	data, err := p.readUpload(ctx, media.UploadKey)
+	if err != nil {
+		return err
+	}
+	result, err := imaging.Process(data)
+	if err != nil {
+		return err
+	}
+
+	original := result.Original
+	originalKey := renditionKey(media.ID, original)
+	if err := p.put(ctx, originalKey, original); err != nil {
+		return err
+	}
+	for _, thumb := range result.Thumbnails {
+		key := renditionKey(media.ID, thumb)
+		if err := p.put(ctx, key, thumb); err != nil {
+			return err
+		}
+		err := p.queries.UpsertMediaVariant(ctx, database.UpsertMediaVariantParams{
+			MediaID:     media.ID,
+			Name:        thumb.Name,
+			StorageKey:  key,
+			ContentType: thumb.ContentType,
+			SizeBytes:   int64(len(thumb.Data)),
+			Width:       int32(thumb.Width),
+			Height:      int32(thumb.Height),
+		})
+		if err != nil {
+			return fmt.Errorf("UpsertMediaVariant: %w", err)
+		}
+	}
+
+	err = p.queries.CompleteMediaProcessing(ctx, database.CompleteMediaProcessingParams{
+		ID:          media.ID,
+		StorageKey:  originalKey,
+		ContentType: original.ContentType,
+		SizeBytes:   int64(len(original.Data)),
+		Width:       int32(original.Width),
+		Height:      int32(original.Height),
+		Blurhash:    result.BlurHash,
+	})
+	if err != nil {
+		return fmt.Errorf("CompleteMediaProcessing: %w", err)
+	}
+	if err := p.store.Delete(ctx, media.UploadKey); err != nil {
+		log.Printf("Couldn't delete upload %s: %v", media.UploadKey, err)
+	}
+	return nil
+}
+
+func (p *mediaProcessor) readUpload(ctx context.Context, key string) ([]byte, error) {
+	body, err := p.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("reading upload %s: %w", key, err)
+	}
+	defer body.Close()
+	return io.ReadAll(io.LimitReader(body, maxMediaBytes+1))
+}
+
+func (p *mediaProcessor) put(ctx context.Context, key string, r imaging.Rendition) error {
+	err := p.store.Put(ctx, key, r.ContentType, bytes.NewReader(r.Data), int64(len(r.Data)))
+	if err != nil {
+		return fmt.Errorf("storing %s: %w", key, err)
+	}
+	return nil
+}
+
+// renditionKey is the storage key of a processed image, e.g.
+// "<media id>/small.jpg".
+func renditionKey(id uuid.UUID, r imaging.Rendition) string {
+	return fmt.Sprintf("%s/%s.%s", id, r.Name, mediaExtensions[r.ContentType])
+}
+
+// mediaKeys lists every storage key belonging to a media item so they can be
+// removed together.
+func mediaKeys(m database.Medium, variants []database.MediaVariant) []string {
+	var keys []string
+	for _, key := range []string{m.StorageKey, m.UploadKey} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	for _, v := range variants {
+		keys = append(keys, v.StorageKey)
+	}
+	return keys
+}
+
+// uploadKey returns a fresh, unguessable key for a raw upload. The upload
+// is only reachable until processing finishes, but it still contains the
+// original metadata so it must not be discoverable from the media ID.
+func uploadKey(id uuid.UUID, ext string) string {
+	return path.Join(id.String(), "upload-"+strings.ReplaceAll(uuid.NewString(), "-", "")+"."+ext)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"github.com/odilmode/http/internal/storage"
+)
+
+// newMediaStorage picks the media backend from the environment.
+// MEDIA_STORAGE=s3 stores uploads in an S3-compatible bucket configured by
+// the S3_* variables; anything else stores them on local disk under
+// MEDIA_DIR (default "./uploads"). For local storage the returned handler
+// serves the files and must be mounted at /media.
+func newMediaStorage() (storage.Storage, http.Handler, error) {
+	if os.Getenv("MEDIA_STORAGE") == "s3" {
+		s3 := &storage.S3{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		}
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			return nil, nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY must be set")
+		}
+		if s3.Region == "" {
+			s3.Region = "us-east-1"
+		}
+		return s3, nil, nil
+	}
+
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	local, err := storage.NewLocalDisk(dir, "/media")
+	if err != nil {
+		return nil, nil, err
+	}
+	return local, local, nil
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+// Moderator actions, as recorded in the audit log. All but claim resolve a
+// case.
+const (
+	moderationClaim         = "claim"
+	moderationHideChirp     = "hide_chirp"
+	moderationSuspendAuthor = "suspend_author"
+	moderationDismiss       = "dismiss"
+)
+
+// maxModerationNoteLength is the longest note a moderator can give, in
+// characters.
+const maxModerationNoteLength = 1000
+
+// ModerationCase is a reported chirp
+// @Description The reports against a chirp, grouped until a moderator resolves them. Reports and actions are only included when a single case is fetched.
+type ModerationCase struct {
+	ID          uuid.UUID          `json:"id"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	ChirpID     uuid.UUID          `json:"chirp_id"`
+	ChirpBody   string             `json:"chirp_body"`
+	AuthorID    uuid.UUID          `json:"author_id"`
+	ReportCount int32              `json:"report_count"`
+	ClaimedBy   *uuid.UUID         `json:"claimed_by,omitempty"`
+	ClaimedAt   *time.Time         `json:"claimed_at,omitempty"`
+	ResolvedBy  *uuid.UUID         `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time         `json:"resolved_at,omitempty"`
+	Resolution  string             `json:"resolution,omitempty" enums:"hide_chirp,suspend_author,dismiss"`
+	Reports     []Report           `json:"reports,omitempty"`
+	Actions     []ModerationAction `json:"actions,omitempty"`
+}
+
+// Report is a user's report of a chirp
+type Report struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment,omitempty"`
+}
+
+// ModerationAction is an entry in the moderation audit log
+type ModerationAction struct {
+	ID           uuid.UUID  `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ModeratorID  uuid.UUID  `json:"moderator_id"`
+	CaseID       *uuid.UUID `json:"case_id,omitempty"`
+	Action       string     `json:"action" enums:"claim,hide_chirp,suspend_author,dismiss"`
+	ChirpID      *uuid.UUID `json:"chirp_id,omitempty"`
+	TargetUserID *uuid.UUID `json:"target_user_id,omitempty"`
+	Note         string     `json:"note"`
+}
+
+// ModerationActionPage is one page of the moderation audit log
+type ModerationActionPage struct {
+	Actions    []ModerationAction `json:"actions"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// resolveCaseRequest is the body for resolving a moderation case
+type resolveCaseRequest struct {
+	Action string `json:"action" enums:"hide_chirp,suspend_author,dismiss"`
+	// Note says why; it is required and kept in the audit log
+	Note string `json:"note"`
+}
+
+func moderationCaseResponse(c database.ModerationCase, body string, authorID uuid.UUID) ModerationCase {
+	resp := ModerationCase{
+		ID:          c.ID,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		ChirpID:     c.ChirpID,
+		ChirpBody:   body,
+		AuthorID:    authorID,
+		ReportCount: c.ReportCount,
+		Resolution:  c.Resolution.String,
+	}
+	if c.ClaimedBy.Valid {
+		resp.ClaimedBy = &c.ClaimedBy.UUID
+	}
+	if c.ClaimedAt.Valid {
+		resp.ClaimedAt = &c.ClaimedAt.Time
+	}
+	if c.ResolvedBy.Valid {
+		resp.ResolvedBy = &c.ResolvedBy.UUID
+	}
+	if c.ResolvedAt.Valid {
+		resp.ResolvedAt = &c.ResolvedAt.Time
+	}
+	return resp
+}
+
+func moderationActionResponse(a database.ModerationAction) ModerationAction {
+	resp := ModerationAction{
+		ID:          a.ID,
+		CreatedAt:   a.CreatedAt,
+		ModeratorID: a.ModeratorID,
+		Action:      a.Action,
+		Note:        a.Note,
+	}
+	if a.CaseID.Valid {
+		resp.CaseID = &a.CaseID.UUID
+	}
+	if a.ChirpID.Valid {
+		resp.ChirpID = &a.ChirpID.UUID
+	}
+	if a.TargetUserID.Valid {
+		resp.TargetUserID = &a.TargetUserID.UUID
+	}
+	return resp
+}
+
+// handleListModerationCases godoc
+// @Summary      List open moderation cases
+// @Description  The oldest 100 unresolved cases. Pass unclaimed=true to leave out cases another moderator is working on.
+// @Tags         moderation
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token of a moderator"
+// @Param        unclaimed      query   bool    false  "Only unclaimed cases"
+// @Success      200  {array}   ModerationCase
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Not a moderator"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/moderation/cases [get]
+func (cfg *apiConfig) handleListModerationCases(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+	rows, err := cfg.dbQueries.ListOpenModerationCases(r.Context(), r.URL.Query().Get("unclaimed") == "true")
+	if err != nil {
+		log.Printf("ListOpenModerationCases error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't list cases")
+		return
+	}
+	cases := make([]ModerationCase, len(rows))
+	for i, row := range rows {
+		cases[i] = moderationCaseResponse(row.ModerationCase, row.Body, row.UserID)
+	}
+	respondWithJSON(w, http.StatusOK, cases)
+}
+
+// handleGetModerationCase godoc
+// @Summary      Get a moderation case
+// @Description  A case with its reports and the moderator actions taken on it.
+// @Tags         moderation
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token of a moderator"
+// @Param        caseID         path    string  true  "Case ID"
+// @Success      200  {object}  ModerationCase
+// @Failure      400  {object}  ErrorResponse "Invalid case ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Not a moderator"
+// @Failure      404  {object}  ErrorResponse "Case not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/moderation/cases/{caseID} [get]
+func (cfg *apiConfig) handleGetModerationCase(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+	caseID, err := uuid.Parse(r.PathValue("caseID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid case ID")
+		return
+	}
+	resp, err := cfg.moderationCase(r.Context(), caseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("moderationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get case")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// moderationCase loads a case with its reports and actions.
+func (cfg *apiConfig) moderationCase(ctx context.Context, caseID uuid.UUID) (ModerationCase, error) {
+	row, err := cfg.dbQueries.GetModerationCase(ctx, caseID)
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	reports, err := cfg.dbQueries.GetModerationCaseReports(ctx, caseID)
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	actions, err := cfg.dbQueries.ListModerationActions(ctx, database.ListModerationActionsParams{
+		CaseID:          uuid.NullUUID{UUID: caseID, Valid: true},
+		BeforeCreatedAt: firstPage.CreatedAt,
+		BeforeID:        firstPage.ID,
+		PageLimit:       maxPageLimit,
+	})
+	if err != nil {
+		return ModerationCase{}, err
+	}
+	resp := moderationCaseResponse(row.ModerationCase, row.Body, row.UserID)
+	resp.Reports = make([]Report, len(reports))
+	for i, report := range reports {
+		resp.Reports[i] = Report{
+			ID:         report.ID,
+			CreatedAt:  report.CreatedAt,
+			ReporterID: report.ReporterID,
+			Reason:     report.Reason,
+			Comment:    report.Comment,
+		}
+	}
+	resp.Actions = make([]ModerationAction, len(actions))
+	for i, action := range actions {
+		resp.Actions[i] = moderationActionResponse(action)
+	}
+	return resp, nil
+}
+
+// handleClaimModerationCase godoc
+// @Summary      Claim a moderation case
+// @Description  Assigns an open case to the calling moderator so others know it is being handled. A case must be claimed before it can be resolved. Claiming a case you already hold has no effect.
+// @Tags         moderation
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token of a moderator"
+// @Param        caseID         path    string  true  "Case ID"
+// @Success      200  {object}  ModerationCase
+// @Failure      400  {object}  ErrorResponse "Invalid case ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Not a moderator, or the case is about your own chirp"
+// @Failure      404  {object}  ErrorResponse "Case not found"
+// @Failure      409  {object}  ErrorResponse "Case is resolved or claimed by another moderator"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/moderation/cases/{caseID}/claim [post]
+func (cfg *apiConfig) handleClaimModerationCase(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := cfg.requireModerator(w, r)
+	if !ok {
+		return
+	}
+	caseID, err := uuid.Parse(r.PathValue("caseID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid case ID")
+		return
+	}
+
+	ctx := r.Context()
+	row, err := cfg.dbQueries.GetModerationCase(ctx, caseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetModerationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim case")
+		return
+	}
+	if row.UserID == moderatorID {
+		respondWithError(w, http.StatusForbidden, "You can't moderate your own chirp")
+		return
+	}
+	if row.ModerationCase.ClaimedBy.Valid && row.ModerationCase.ClaimedBy.UUID == moderatorID && !row.ModerationCase.ResolvedAt.Valid {
+		respondWithJSON(w, http.StatusOK, moderationCaseResponse(row.ModerationCase, row.Body, row.UserID))
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim case")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	claimed, err := qtx.ClaimModerationCase(ctx, database.ClaimModerationCaseParams{
+		ModeratorID: uuid.NullUUID{UUID: moderatorID, Valid: true},
+		ID:          caseID,
+	})
+	if err != nil {
+		log.Printf("ClaimModerationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim case")
+		return
+	}
+	if claimed == 0 {
+		respondWithError(w, http.StatusConflict, "Case is resolved or claimed by another moderator")
+		return
+	}
+	if err := qtx.CreateModerationAction(ctx, database.CreateModerationActionParams{
+		ModeratorID:  moderatorID,
+		CaseID:       uuid.NullUUID{UUID: caseID, Valid: true},
+		Action:       moderationClaim,
+		ChirpID:      uuid.NullUUID{UUID: row.ModerationCase.ChirpID, Valid: true},
+		TargetUserID: uuid.NullUUID{UUID: row.UserID, Valid: true},
+	}); err != nil {
+		log.Printf("CreateModerationAction error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim case")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't claim case")
+		return
+	}
+
+	resp, err := cfg.moderationCase(ctx, caseID)
+	if err != nil {
+		log.Printf("moderationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load case")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleResolveModerationCase godoc
+// @Summary      Resolve a moderation case
+// @Description  Closes a case the calling moderator has claimed. hide_chirp hides the reported chirp from everyone but its author; suspend_author stops the author from logging in or posting, signs them out at once (their access tokens stop working and their WebSocket and event stream connections close) and hides all their chirps; dismiss takes no action. The action and note are recorded in the audit log.
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string              true  "Bearer JWT token of a moderator"
+// @Param        caseID         path    string              true  "Case ID"
+// @Param        resolution     body    resolveCaseRequest  true  "Action and reason"
+// @Success      200  {object}  ModerationCase
+// @Failure      400  {object}  ErrorResponse "Invalid case ID, action or note"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Not a moderator"
+// @Failure      404  {object}  ErrorResponse "Case not found"
+// @Failure      409  {object}  ErrorResponse "Case is resolved or not claimed by the caller"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/moderation/cases/{caseID}/resolve [post]
+func (cfg *apiConfig) handleResolveModerationCase(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := cfg.requireModerator(w, r)
+	if !ok {
+		return
+	}
+	caseID, err := uuid.Parse(r.PathValue("caseID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid case ID")
+		return
+	}
+	var params resolveCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	switch params.Action {
+	case moderationHideChirp, moderationSuspendAuthor, moderationDismiss:
+	default:
+		respondWithError(w, http.StatusBadRequest, "Action must be hide_chirp, suspend_author or dismiss")
+		return
+	}
+	params.Note = strings.TrimSpace(params.Note)
+	if params.Note == "" {
+		respondWithError(w, http.StatusBadRequest, "A note is required")
+		return
+	}
+	if textlength.Graphemes(params.Note) > maxModerationNoteLength {
+		respondWithError(w, http.StatusBadRequest, "Note is too long")
+		return
+	}
+
+	ctx := r.Context()
+	row, err := cfg.dbQueries.GetModerationCase(ctx, caseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Case not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetModerationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve case")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve case")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	resolved, err := qtx.ResolveModerationCase(ctx, database.ResolveModerationCaseParams{
+		ModeratorID: uuid.NullUUID{UUID: moderatorID, Valid: true},
+		Resolution:  sql.NullString{String: params.Action, Valid: true},
+		ID:          caseID,
+	})
+	if err != nil {
+		log.Printf("ResolveModerationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve case")
+		return
+	}
+	if resolved == 0 {
+		respondWithError(w, http.StatusConflict, "Case is resolved or not claimed by you")
+		return
+	}
+	switch params.Action {
+	case moderationHideChirp:
+		err = qtx.HideChirp(ctx, row.ModerationCase.ChirpID)
+	case moderationSuspendAuthor:
+		if err = qtx.SuspendUser(ctx, row.UserID); err == nil {
+			err = qtx.RevokeUserRefreshTokens(ctx, row.UserID)
+		}
+		if err == nil {
+			err = endSessions(ctx, qtx, row.UserID)
+		}
+	}
+	if err != nil {
+		log.Printf("%s error: %v", params.Action, err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve case")
+		return
+	}
+	if err := qtx.CreateModerationAction(ctx, database.CreateModerationActionParams{
+		ModeratorID:  moderatorID,
+		CaseID:       uuid.NullUUID{UUID: caseID, Valid: true},
+		Action:       params.Action,
+		ChirpID:      uuid.NullUUID{UUID: row.ModerationCase.ChirpID, Valid: true},
+		TargetUserID: uuid.NullUUID{UUID: row.UserID, Valid: true},
+		Note:         params.Note,
+	}); err != nil {
+		log.Printf("CreateModerationAction error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve case")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve case")
+		return
+	}
+
+	resp, err := cfg.moderationCase(ctx, caseID)
+	if err != nil {
+		log.Printf("moderationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load case")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleListModerationActions godoc
+// @Summary      Moderation audit log
+// @Description  Every action moderators have taken, newest first, with who took it and why. Pass next_cursor back as cursor to get the following page.
+// @Tags         moderation
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token of a moderator"
+// @Param        case_id        query   string  false  "Only actions on this case"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  ModerationActionPage
+// @Failure      400  {object}  ErrorResponse "Invalid case ID or pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      403  {object}  ErrorResponse "Not a moderator"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/moderation/actions [get]
+func (cfg *apiConfig) handleListModerationActions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var caseID uuid.NullUUID
+	if s := r.URL.Query().Get("case_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid case ID")
+			return
+		}
+		caseID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	actions, err := cfg.dbQueries.ListModerationActions(r.Context(), database.ListModerationActionsParams{
+		CaseID:          caseID,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("ListModerationActions error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't list actions")
+		return
+	}
+	page := ModerationActionPage{Actions: make([]ModerationAction, len(actions))}
+	for i, action := range actions {
+		page.Actions[i] = moderationActionResponse(action)
+	}
+	if len(actions) > 0 {
+		last := actions[len(actions)-1]
+		page.NextCursor = nextCursor(len(actions), limit, last.CreatedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,616 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+const (
+	notificationMention = "mention"
+	notificationQuote   = "quote"
+	notificationRechirp = "rechirp"
+	notificationFollow  = "follow"
+	// notificationExportReady has no actors; it tells a user their data
+	// export can be downloaded.
+	notificationExportReady = "export_ready"
+)
+
+// notificationActorsShown is how many of a grouped notification's actors
+// are returned, most recent first.
+const notificationActorsShown = 3
+
+// Notification tells a user that others mentioned, quoted, rechirped or
+// followed them, or that their data export is ready. Similar events are
+// grouped while the notification is unread.
+type Notification struct {
+	ID   uuid.UUID `json:"id"`
+	Type string    `json:"type" enums:"mention,quote,rechirp,follow,export_ready"`
+	// ChirpID is the mentioning chirp, or the caller's chirp that was quoted
+	// or rechirped. Omitted for follows.
+	ChirpID *uuid.UUID `json:"chirp_id,omitempty"`
+	// ExportID is the data export that is ready to download
+	ExportID *uuid.UUID `json:"export_id,omitempty"`
+	// Actors are the most recent users behind the notification, up to 3
+	Actors []Profile `json:"actors"`
+	// ActorCount is how many users the notification groups
+	ActorCount int `json:"actor_count"`
+	// Summary describes the notification, e.g. "@alice and 4 others
+	// rechirped your chirp"
+	Summary   string    `json:"summary"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the latest event joined the notification
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPage is one page of notifications, most recently updated first
+type NotificationPage struct {
+	UnreadCount   int64          `json:"unread_count"`
+	Notifications []Notification `json:"notifications"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+}
+
+// NotificationPreferences says which notification types a user receives
+type NotificationPreferences struct {
+	Mention bool `json:"mention"`
+	Quote   bool `json:"quote"`
+	Rechirp bool `json:"rechirp"`
+	Follow  bool `json:"follow"`
+}
+
+// notificationPreferencesRequest changes notification preferences. Omitted
+// types are left as they are.
+type notificationPreferencesRequest struct {
+	Mention *bool `json:"mention,omitempty"`
+	Quote   *bool `json:"quote,omitempty"`
+	Rechirp *bool `json:"rechirp,omitempty"`
+	Follow  *bool `json:"follow,omitempty"`
+}
+
+// handleGetNotifications godoc
+// @Summary      List notifications
+// @Description  The authenticated user's notifications, most recently updated first, with the total number of unread ones. Notifications about deleted chirps are left out.
+// @Tags         notifications
+// @Produce      json
+// @Param        Authorization  header  string  true   "Bearer JWT token"
+// @Param        unread         query   bool    false  "Only unread notifications"
+// @Param        limit          query   int     false  "Page size (1-100, default 20)"
+// @Param        cursor         query   string  false  "Cursor from a previous page"
+// @Success      200  {object}  NotificationPage
+// @Failure      400  {object}  ErrorResponse "Invalid pagination parameters"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/notifications [get]
+func (cfg *apiConfig) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	limit, cursor, err := parsePage(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := r.Context()
+	unreadCount, err := cfg.dbQueries.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		log.Printf("CountUnreadNotifications error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch notifications")
+		return
+	}
+	notifications, err := cfg.dbQueries.ListNotifications(ctx, database.ListNotificationsParams{
+		UserID:          userID,
+		UnreadOnly:      r.URL.Query().Get("unread") == "true",
+		BeforeUpdatedAt: cursor.CreatedAt,
+		BeforeID:        cursor.ID,
+		PageLimit:       limit,
+	})
+	if err != nil {
+		log.Printf("ListNotifications error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch notifications")
+		return
+	}
+
+	page := NotificationPage{UnreadCount: unreadCount}
+	page.Notifications, err = cfg.notificationResponses(ctx, userID, notifications)
+	if err != nil {
+		log.Printf("GetNotificationActors error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch notifications")
+		return
+	}
+	if len(notifications) > 0 {
+		last := notifications[len(notifications)-1]
+		page.NextCursor = nextCursor(len(notifications), limit, last.UpdatedAt, last.ID)
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handleMarkNotificationRead godoc
+// @Summary      Mark a notification as read
+// @Description  Marks one of the authenticated user's notifications as read. Later events of the same kind start a new notification.
+// @Tags         notifications
+// @Param        Authorization   header  string  true  "Bearer JWT token"
+// @Param        notificationID  path    string  true  "Notification ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid notification ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Notification not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/notifications/{notificationID}/read [post]
+func (cfg *apiConfig) handleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	notificationID, err := uuid.Parse(r.PathValue("notificationID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+	marked, err := cfg.dbQueries.MarkNotificationRead(r.Context(), database.MarkNotificationReadParams{
+		ID:     notificationID,
+		UserID: userID,
+	})
+	if err != nil {
+		log.Printf("MarkNotificationRead error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark notification as read")
+		return
+	}
+	if marked == 0 {
+		respondWithError(w, http.StatusNotFound, "Notification not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMarkAllNotificationsRead godoc
+// @Summary      Mark all notifications as read
+// @Description  Marks all of the authenticated user's notifications as read
+// @Tags         notifications
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      204  "No Content"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/notifications/read-all [post]
+func (cfg *apiConfig) handleMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	if err := cfg.dbQueries.MarkAllNotificationsRead(r.Context(), userID); err != nil {
+		log.Printf("MarkAllNotificationsRead error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark notifications as read")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetNotificationPreferences godoc
+// @Summary      Get notification preferences
+// @Description  Which notification types the authenticated user receives. All types are on by default.
+// @Tags         notifications
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      200  {object}  NotificationPreferences
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/notifications/preferences [get]
+func (cfg *apiConfig) handleGetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	prefs, err := cfg.notificationPreferences(r.Context(), userID)
+	if err != nil {
+		log.Printf("GetNotificationPreferences error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load notification preferences")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prefs)
+}
+
+// handleUpdateNotificationPreferences godoc
+// @Summary      Update notification preferences
+// @Description  Turns notification types on or off for the authenticated user. Types left out of the body keep their setting. Turning a type off stops new notifications of that type; existing ones stay.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                          true  "Bearer JWT token"
+// @Param        preferences    body    notificationPreferencesRequest  true  "Types to change"
+// @Success      200  {object}  NotificationPreferences
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/notifications/preferences [put]
+func (cfg *apiConfig) handleUpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	var params notificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	ctx := r.Context()
+	for kind, enabled := range map[string]*bool{
+		notificationMention: params.Mention,
+		notificationQuote:   params.Quote,
+		notificationRechirp: params.Rechirp,
+		notificationFollow:  params.Follow,
+	} {
+		if enabled == nil {
+			continue
+		}
+		if err := cfg.dbQueries.SetNotificationPreference(ctx, database.SetNotificationPreferenceParams{
+			UserID:  userID,
+			Type:    kind,
+			Enabled: *enabled,
+		}); err != nil {
+			log.Printf("SetNotificationPreference error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update notification preferences")
+			return
+		}
+	}
+
+	prefs, err := cfg.notificationPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("GetNotificationPreferences error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load notification preferences")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, prefs)
+}
+
+// notificationPreferences returns the user's preferences, with types they
+// never changed turned on.
+func (cfg *apiConfig) notificationPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreferences, error) {
+	rows, err := cfg.dbQueries.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return NotificationPreferences{}, err
+	}
+	prefs := NotificationPreferences{Mention: true, Quote: true, Rechirp: true, Follow: true}
+	for _, row := range rows {
+		switch row.Type {
+		case notificationMention:
+			prefs.Mention = row.Enabled
+		case notificationQuote:
+			prefs.Quote = row.Enabled
+		case notificationRechirp:
+			prefs.Rechirp = row.Enabled
+		case notificationFollow:
+			prefs.Follow = row.Enabled
+		}
+	}
+	return prefs, nil
+}
+
+// notificationResponses converts a user's notifications into API
+// notifications, loading the recent actors of all of them in one query.
+func (cfg *apiConfig) notificationResponses(ctx context.Context, userID uuid.UUID, notifications []database.Notification) ([]Notification, error) {
+	ids := make([]uuid.UUID, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.ID
+	}
+	actorRows, err := cfg.dbQueries.GetNotificationActors(ctx, database.GetNotificationActorsParams{
+		NotificationIds: ids,
+		PerNotification: notificationActorsShown,
+		UserID:          userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	actors := make(map[uuid.UUID][]Profile, len(notifications))
+	for _, a := range actorRows {
+		actors[a.NotificationID] = append(actors[a.NotificationID], Profile{
+			ID:          a.ID,
+			Username:    a.Username.String,
+			CreatedAt:   a.CreatedAt,
+			IsChirpyRed: a.IsChirpyRed,
+		})
+	}
+
+	resp := make([]Notification, 0, len(notifications))
+	for _, n := range notifications {
+		notification := Notification{
+			ID:         n.ID,
+			Type:       n.Type,
+			Actors:     actors[n.ID],
+			ActorCount: int(n.ActorCount),
+			Read:       n.ReadAt.Valid,
+			CreatedAt:  n.CreatedAt,
+			UpdatedAt:  n.UpdatedAt,
+		}
+		if notification.Actors == nil {
+			notification.Actors = []Profile{}
+		}
+		if n.ChirpID.Valid {
+			notification.ChirpID = &n.ChirpID.UUID
+		}
+		if n.ExportID.Valid {
+			notification.ExportID = &n.ExportID.UUID
+		}
+		notification.Summary = notificationSummary(n.Type, notification.Actors, notification.ActorCount)
+		resp = append(resp, notification)
+	}
+	return resp, nil
+}
+
+// notificationSummary describes a notification from its most recent actors
+// and how many actors it groups.
+func notificationSummary(kind string, actors []Profile, count int) string {
+	if kind == notificationExportReady {
+		return "Your data export is ready to download"
+	}
+	var verb string
+	switch kind {
+	case notificationMention:
+		verb = "mentioned you"
+	case notificationQuote:
+		verb = "quoted your chirp"
+	case notificationRechirp:
+		verb = "rechirped your chirp"
+	case notificationFollow:
+		verb = "followed you"
+	}
+	name := func(p Profile) string {
+		if p.Username == "" {
+			return "Someone"
+		}
+		return "@" + p.Username
+	}
+
+	who := "Someone"
+	if len(actors) > 0 {
+		who = name(actors[0])
+	}
+	switch {
+	case count <= 1:
+	case count == 2 && len(actors) == 2:
+		who += " and " + name(actors[1])
+	case count == 2:
+		who += " and 1 other"
+	default:
+		who += fmt.Sprintf(" and %d others", count-1)
+	}
+	return who + " " + verb
+}
+
+// notificationEvent is something that happened to a user that they should
+// be told about.
+type notificationEvent struct {
+	kind      string
+	recipient uuid.UUID
+	actor     uuid.UUID
+	// chirpID is the chirp the notification is about, if any
+	chirpID uuid.NullUUID
+	// sourceChirpID is the actor's chirp behind the event, if any
+	sourceChirpID uuid.NullUUID
+}
+
+// groupKey identifies the events that are grouped into one notification.
+func (e notificationEvent) groupKey() string {
+	if !e.chirpID.Valid {
+		return e.kind
+	}
+	return e.kind + ":" + e.chirpID.UUID.String()
+}
+
+// notifier turns domain events into notifications. It is called after the
+// event's transaction commits, and failures are logged rather than failing
+// the request that caused the event. Events are dropped when the recipient
+// is the actor, either has blocked the other, the recipient has muted the
+// actor, or the recipient has turned the notification type off.
+type notifier struct {
+	db      *sql.DB
+	queries *database.Queries
+}
+
+func newNotifier(db *sql.DB, queries *database.Queries) *notifier {
+	return &notifier{db: db, queries: queries}
+}
+
+// chirpPublished notifies the users a newly published chirp mentions, and
+// the author of the chirp it quotes or rechirps.
+func (n *notifier) chirpPublished(ctx context.Context, chirp database.Chirp) {
+	if err := n.notifyChirp(ctx, chirp); err != nil {
+		log.Printf("notifying about chirp %s: %v", chirp.ID, err)
+	}
+}
+
+// followed notifies a user that someone started following them.
+func (n *notifier) followed(ctx context.Context, followerID, followeeID uuid.UUID) {
+	if err := n.notify(ctx, notificationEvent{
+		kind:      notificationFollow,
+		recipient: followeeID,
+		actor:     followerID,
+	}); err != nil {
+		log.Printf("notifying about follow of %s: %v", followeeID, err)
+	}
+}
+
+// exportReady tells a user their data export can be downloaded. It isn't
+// subject to notification preferences.
+func (n *notifier) exportReady(ctx context.Context, userID, exportID uuid.UUID) {
+	if err := n.notifyExport(ctx, userID, exportID); err != nil {
+		log.Printf("notifying about export %s: %v", exportID, err)
+	}
+}
+
+func (n *notifier) notifyExport(ctx context.Context, userID, exportID uuid.UUID) error {
+	tx, err := n.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := n.queries.WithTx(tx)
+	notificationID := uuid.New()
+	if err := qtx.CreateExportNotification(ctx, database.CreateExportNotificationParams{
+		ID:       notificationID,
+		UserID:   userID,
+		ExportID: uuid.NullUUID{UUID: exportID, Valid: true},
+		GroupKey: notificationExportReady + ":" + exportID.String(),
+	}); err != nil {
+		return fmt.Errorf("CreateExportNotification: %w", err)
+	}
+	if err := publishRealtimeEvent(ctx, qtx, realtimeEvent{
+		Type:   realtimeNotification,
+		UserID: userID,
+		ID:     notificationID,
+	}); err != nil {
+		return fmt.Errorf("PublishRealtimeEvent: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (n *notifier) notifyChirp(ctx context.Context, chirp database.Chirp) error {
+	source := uuid.NullUUID{UUID: chirp.ID, Valid: true}
+	if chirp.RechirpOf.Valid {
+		original, err := n.queries.GetChirp(ctx, database.GetChirpParams{ID: chirp.RechirpOf.UUID, ViewerID: chirp.UserID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("GetChirp: %w", err)
+		}
+		return n.notify(ctx, notificationEvent{
+			kind:          notificationRechirp,
+			recipient:     original.UserID,
+			actor:         chirp.UserID,
+			chirpID:       uuid.NullUUID{UUID: original.ID, Valid: true},
+			sourceChirpID: source,
+		})
+	}
+
+	if chirp.QuoteOf.Valid {
+		quoted, err := n.queries.GetChirp(ctx, database.GetChirpParams{ID: chirp.QuoteOf.UUID, ViewerID: chirp.UserID})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("GetChirp: %w", err)
+		}
+		if err == nil {
+			// The quoted author is only told about quotes they can see.
+			_, err := n.queries.GetChirp(ctx, database.GetChirpParams{ID: chirp.ID, ViewerID: quoted.UserID})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("GetChirp: %w", err)
+			}
+			if err == nil {
+				if err := n.notify(ctx, notificationEvent{
+					kind:          notificationQuote,
+					recipient:     quoted.UserID,
+					actor:         chirp.UserID,
+					chirpID:       uuid.NullUUID{UUID: quoted.ID, Valid: true},
+					sourceChirpID: source,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	mentioned, err := n.queries.GetMentionedUserIDs(ctx, chirp.ID)
+	if err != nil {
+		return fmt.Errorf("GetMentionedUserIDs: %w", err)
+	}
+	for _, userID := range mentioned {
+		if err := n.notify(ctx, notificationEvent{
+			kind:          notificationMention,
+			recipient:     userID,
+			actor:         chirp.UserID,
+			chirpID:       source,
+			sourceChirpID: source,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify records an event, adding its actor to the recipient's unread
+// notification of the same group or starting a new one.
+func (n *notifier) notify(ctx context.Context, e notificationEvent) error {
+	tx, err := n.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := n.queries.WithTx(tx)
+	notificationID, err := qtx.UpsertNotification(ctx, database.UpsertNotificationParams{
+		ID:       uuid.New(),
+		UserID:   e.recipient,
+		Type:     e.kind,
+		ChirpID:  e.chirpID,
+		GroupKey: e.groupKey(),
+		ActorID:  e.actor,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The recipient doesn't want to hear about this.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("UpsertNotification: %w", err)
+	}
+	if err := qtx.AddNotificationActor(ctx, database.AddNotificationActorParams{
+		NotificationID: notificationID,
+		ActorID:        e.actor,
+		SourceChirpID:  e.sourceChirpID,
+	}); err != nil {
+		return fmt.Errorf("AddNotificationActor: %w", err)
+	}
+	if err := publishRealtimeEvent(ctx, qtx, realtimeEvent{
+		Type:   realtimeNotification,
+		UserID: e.recipient,
+		ID:     notificationID,
+	}); err != nil {
+		return fmt.Errorf("PublishRealtimeEvent: %w", err)
+	}
+	return tx.Commit()
+}
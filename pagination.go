@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageCursor marks a position in a list ordered by (created_at, id)
+// descending. The next page holds the items strictly before it.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// firstPage is the cursor that sorts after every item, so a query using it
+// returns the newest items.
+var firstPage = pageCursor{
+	CreatedAt: time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC),
+	ID:        uuid.Max,
+}
+
+// encode returns the opaque form of the cursor handed to clients.
+func (c pageCursor) encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	return pageCursor{CreatedAt: t, ID: parsedID}, nil
+}
+
+// parsePage reads the `limit` and `cursor` query parameters.
+func parsePage(r *http.Request) (int32, pageCursor, error) {
+	limit := defaultPageLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return 0, pageCursor{}, errors.New("limit must be between 1 and 100")
+		}
+		limit = n
+	}
+	cursor := firstPage
+	if s := r.URL.Query().Get("cursor"); s != "" {
+		c, err := decodeCursor(s)
+		if err != nil {
+			return 0, pageCursor{}, err
+		}
+		cursor = c
+	}
+	return int32(limit), cursor, nil
+}
+
+// nextCursor returns the cursor for the page after one that ended with the
+// given item, or "" when the page wasn't full and there is nothing more.
+func nextCursor(count int, limit int32, createdAt time.Time, id uuid.UUID) string {
+	if count < int(limit) {
+		return ""
+	}
+	return pageCursor{CreatedAt: createdAt, ID: id}.encode()
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll is a poll attached to a chirp
+// @Description A poll. Tallies are live while the poll is open and frozen once it closes.
+type Poll struct {
+	Options  []PollOption `json:"options"`
+	Multiple bool         `json:"multiple"`
+	ClosesAt time.Time    `json:"closes_at"`
+	Closed   bool         `json:"closed"`
+	// VoterCount is the number of users who voted; with multiple choice it
+	// can be lower than the sum of the option votes
+	VoterCount int32 `json:"voter_count"`
+}
+
+// PollOption is one of a poll's choices
+type PollOption struct {
+	Text  string `json:"text"`
+	Votes int32  `json:"votes"`
+}
+
+// pollRequest describes a poll to attach to a new chirp
+type pollRequest struct {
+	// Options are the 2-4 choices, up to 25 characters each
+	Options []string `json:"options"`
+	// Multiple allows voters to pick more than one option
+	Multiple bool `json:"multiple"`
+	// ClosesAt is when voting ends: between 5 minutes and 7 days after the chirp is published
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// pollVoteRequest is the body for voting in a poll
+type pollVoteRequest struct {
+	// Choices are the zero-based positions of the chosen options
+	Choices []int32 `json:"choices"`
+}
+
+// validatePoll checks a poll for a chirp published at publishAt.
+func validatePoll(p pollRequest, publishAt time.Time) error {
+	if len(p.Options) < minPollOptions || len(p.Options) > maxPollOptions {
+		return fmt.Errorf("A poll needs %d to %d options", minPollOptions, maxPollOptions)
+	}
+	seen := make(map[string]bool, len(p.Options))
+	for _, option := range p.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || textlength.Graphemes(option) > maxPollOptionLength {
+			return fmt.Errorf("Poll options must be 1 to %d characters", maxPollOptionLength)
+		}
+		if seen[strings.ToLower(option)] {
+			return errors.New("Poll options must be different")
+		}
+		seen[strings.ToLower(option)] = true
+	}
+	duration := p.ClosesAt.Sub(publishAt)
+	if duration < minPollDuration || duration > maxPollDuration {
+		return errors.New("Polls must close between 5 minutes and 7 days after the chirp is published")
+	}
+	return nil
+}
+
+// savePoll stores a validated poll for a new chirp.
+func savePoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, p pollRequest) error {
+	if err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:  chirpID,
+		Multiple: p.Multiple,
+		ClosesAt: p.ClosesAt.UTC(),
+	}); err != nil {
+		return err
+	}
+	for i, option := range p.Options {
+		if err := q.CreatePollOption(ctx, database.CreatePollOptionParams{
+			ChirpID:  chirpID,
+			Position: int32(i),
+			Text:     strings.TrimSpace(option),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPolls returns the polls of the given chirps, keyed by chirp ID.
+func (cfg *apiConfig) loadPolls(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]*Poll, error) {
+	polls, err := cfg.dbQueries.GetPollsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return nil, nil
+	}
+	options, err := cfg.dbQueries.GetPollOptionsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := make(map[uuid.UUID]*Poll, len(polls))
+	for _, p := range polls {
+		result[p.ChirpID] = &Poll{
+			Options:    []PollOption{},
+			Multiple:   p.Multiple,
+			ClosesAt:   p.ClosesAt,
+			Closed:     !now.Before(p.ClosesAt),
+			VoterCount: p.VoterCount,
+		}
+	}
+	for _, o := range options {
+		if poll, ok := result[o.ChirpID]; ok {
+			poll.Options = append(poll.Options, PollOption{Text: o.Text, Votes: o.VoteCount})
+		}
+	}
+	return result, nil
+}
+
+// handleVotePoll godoc
+// @Summary      Vote in a poll
+// @Description  Casts the authenticated user's vote in a chirp's poll. Each user votes once; single-choice polls take exactly one choice. Votes are rejected once the poll has closed.
+// @Tags         chirps
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string           true  "Bearer JWT token"
+// @Param        chirpID        path    string           true  "Chirp ID"
+// @Param        vote           body    pollVoteRequest  true  "Chosen options"
+// @Success      200  {object}  Chirp
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID or choices"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Chirp or poll not found"
+// @Failure      409  {object}  ErrorResponse "Already voted or poll closed"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/{chirpID}/poll/votes [post]
+func (cfg *apiConfig) handleVotePoll(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	var params pollVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+
+	ctx := r.Context()
+	chirp, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: chirpID, ViewerID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	poll, err := cfg.dbQueries.GetPoll(ctx, chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp has no poll")
+		return
+	}
+	if err != nil {
+		log.Printf("GetPoll error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	if !time.Now().Before(poll.ClosesAt) {
+		respondWithError(w, http.StatusConflict, "Poll is closed")
+		return
+	}
+	options, err := cfg.dbQueries.GetPollOptionsForChirps(ctx, []uuid.UUID{chirpID})
+	if err != nil {
+		log.Printf("GetPollOptionsForChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	if len(params.Choices) == 0 || (!poll.Multiple && len(params.Choices) > 1) {
+		respondWithError(w, http.StatusBadRequest, "Choose one option, or several if the poll allows it")
+		return
+	}
+	chosen := make(map[int32]bool, len(params.Choices))
+	for _, c := range params.Choices {
+		if c < 0 || int(c) >= len(options) || chosen[c] {
+			respondWithError(w, http.StatusBadRequest, "Invalid choice")
+			return
+		}
+		chosen[c] = true
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	// The ballot is only inserted while the poll is open, and its primary
+	// key stops a second vote by the same user.
+	inserted, err := qtx.CreatePollBallot(ctx, database.CreatePollBallotParams{
+		UserID:  userID,
+		Choices: params.Choices,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		log.Printf("CreatePollBallot error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	if inserted == 0 {
+		if !time.Now().Before(poll.ClosesAt) {
+			respondWithError(w, http.StatusConflict, "Poll is closed")
+			return
+		}
+		respondWithError(w, http.StatusConflict, "Already voted")
+		return
+	}
+	if err := qtx.CountPollVotes(ctx, database.CountPollVotesParams{ChirpID: chirpID, Choices: params.Choices}); err != nil {
+		log.Printf("CountPollVotes error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	if err := qtx.CountPollVoter(ctx, chirpID); err != nil {
+		log.Printf("CountPollVoter error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't vote")
+		return
+	}
+
+	resp, err := cfg.chirpResponse(ctx, userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/odilmode/http/internal/database"
+)
+
+const (
+	// realtimeChannel is the Postgres channel realtime events are announced
+	// on. The name is also in the PublishRealtimeEvent query.
+	realtimeChannel = "realtime_events"
+	// realtimePingInterval is how often the listener's connection is checked.
+	realtimePingInterval     = time.Minute
+	realtimeSubscriberBuffer = 64
+)
+
+const (
+	realtimeNotification = "notification"
+	realtimeMessage      = "message"
+	realtimeTyping       = "typing"
+	// realtimeSessionEnded closes the user's connections when their account
+	// is deleted or suspended.
+	realtimeSessionEnded = "session_ended"
+)
+
+// realtimeEvent is something WebSocket clients are told about as it
+// happens. Events are sent through Postgres so clients connected to any
+// instance hear about them, and only carry IDs: each receiver loads what it
+// sends, as its user sees it.
+type realtimeEvent struct {
+	Type string `json:"type"`
+	// UserID is the notification's recipient, the user who sent the
+	// message or is typing, or the user whose session ended.
+	UserID         uuid.UUID `json:"user_id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	// ID is the notification or message.
+	ID uuid.UUID `json:"id"`
+}
+
+// key is what subscribers watch to receive the event.
+func (e realtimeEvent) key() string {
+	switch e.Type {
+	case realtimeNotification:
+		return notificationsKey(e.UserID)
+	case realtimeSessionEnded:
+		return sessionKey(e.UserID)
+	}
+	return conversationKey(e.ConversationID)
+}
+
+func notificationsKey(userID uuid.UUID) string {
+	return "notifications:" + userID.String()
+}
+
+func conversationKey(conversationID uuid.UUID) string {
+	return "conversation:" + conversationID.String()
+}
+
+func sessionKey(userID uuid.UUID) string {
+	return "session:" + userID.String()
+}
+
+// endSessions closes userID's WebSocket and event stream connections on
+// every instance once the transaction q belongs to commits.
+func endSessions(ctx context.Context, q *database.Queries, userID uuid.UUID) error {
+	return publishRealtimeEvent(ctx, q, realtimeEvent{Type: realtimeSessionEnded, UserID: userID})
+}
+
+// publishRealtimeEvent announces e. Postgres holds the announcement until
+// the transaction q belongs to commits, and drops it on rollback.
+func publishRealtimeEvent(ctx context.Context, q *database.Queries, e realtimeEvent) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return q.PublishRealtimeEvent(ctx, string(payload))
+}
+
+type realtimeSubscriber struct {
+	events chan realtimeEvent
+	// keys are guarded by the hub's mutex.
+	keys map[string]struct{}
+}
+
+// realtimeHub delivers realtime events to the subscribers on this instance
+// watching their keys. Events are fire and forget: one announced while the
+// listener is reconnecting is lost, and clients catch up through the REST
+// API when they reconnect.
+type realtimeHub struct {
+	dbURL string
+
+	mu       sync.Mutex
+	watchers map[string]map[*realtimeSubscriber]struct{}
+}
+
+func newRealtimeHub(dbURL string) *realtimeHub {
+	return &realtimeHub{
+		dbURL:    dbURL,
+		watchers: make(map[string]map[*realtimeSubscriber]struct{}),
+	}
+}
+
+// start listens for realtime events until ctx is cancelled.
+func (h *realtimeHub) start(ctx context.Context) {
+	listener := pq.NewListener(h.dbURL, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("realtime listener: %v", err)
+		}
+	})
+	go func() {
+		if err := listener.Listen(realtimeChannel); err != nil {
+			log.Printf("listening on %s: %v", realtimeChannel, err)
+		}
+	}()
+	go func() {
+		defer listener.Close()
+		ping := time.NewTicker(realtimePingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-listener.Notify:
+				if n == nil {
+					continue
+				}
+				var e realtimeEvent
+				if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+					log.Printf("decoding realtime event: %v", err)
+					continue
+				}
+				h.dispatch(e)
+			case <-ping.C:
+				listener.Ping()
+			}
+		}
+	}()
+}
+
+// dispatch hands e to the subscribers watching its key. A subscriber whose
+// buffer is full is dropped rather than holding up the rest.
+func (h *realtimeHub) dispatch(e realtimeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.watchers[e.key()] {
+		select {
+		case sub.events <- e:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *realtimeHub) subscribe() *realtimeSubscriber {
+	return &realtimeSubscriber{
+		events: make(chan realtimeEvent, realtimeSubscriberBuffer),
+		keys:   make(map[string]struct{}),
+	}
+}
+
+// watch starts delivering events with key to sub. Nothing happens if sub
+// has been dropped; its closed channel tells its owner.
+func (h *realtimeHub) watch(sub *realtimeSubscriber, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub.keys == nil {
+		return
+	}
+	if h.watchers[key] == nil {
+		h.watchers[key] = make(map[*realtimeSubscriber]struct{})
+	}
+	h.watchers[key][sub] = struct{}{}
+	sub.keys[key] = struct{}{}
+}
+
+func (h *realtimeHub) unwatch(sub *realtimeSubscriber, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub.keys == nil {
+		return
+	}
+	delete(sub.keys, key)
+	delete(h.watchers[key], sub)
+	if len(h.watchers[key]) == 0 {
+		delete(h.watchers, key)
+	}
+}
+
+func (h *realtimeHub) unsubscribe(sub *realtimeSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub.keys != nil {
+		h.remove(sub)
+	}
+}
+
+// remove stops all deliveries to sub and closes its channel. h.mu must be
+// held.
+func (h *realtimeHub) remove(sub *realtimeSubscriber) {
+	for key := range sub.keys {
+		delete(h.watchers[key], sub)
+		if len(h.watchers[key]) == 0 {
+			delete(h.watchers, key)
+		}
+	}
+	sub.keys = nil
+	close(sub.events)
+}
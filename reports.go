@@ -0,0 +1,210 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+// maxReportCommentLength is the longest comment a reporter can add, in
+// characters.
+const maxReportCommentLength = 1000
+
+// reportReasons are the reason codes a chirp can be reported for.
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+// reportRequest is the body for reporting a chirp
+type reportRequest struct {
+	Reason string `json:"reason" enums:"spam,harassment,hate,violence,sexual,misinformation,other"`
+	// Comment optionally explains the report, up to 1000 characters
+	Comment string `json:"comment,omitempty"`
+}
+
+// handleReportChirp godoc
+// @Summary      Report a chirp
+// @Description  Reports a chirp to the moderators. Reports of the same chirp are grouped into one moderation case; reporting a chirp again while its case is open has no effect.
+// @Tags         chirps
+// @Accept       json
+// @Param        Authorization  header  string         true  "Bearer JWT token"
+// @Param        chirpID        path    string         true  "Chirp ID"
+// @Param        report         body    reportRequest  true  "Reason for the report"
+// @Success      202  "Accepted"
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID or reason, or own chirp"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Chirp not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/{chirpID}/reports [post]
+func (cfg *apiConfig) handleReportChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	var params reportRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+	if !reportReasons[params.Reason] {
+		respondWithError(w, http.StatusBadRequest, "Invalid reason")
+		return
+	}
+	if textlength.Graphemes(params.Comment) > maxReportCommentLength {
+		respondWithError(w, http.StatusBadRequest, "Comment is too long")
+		return
+	}
+
+	ctx := r.Context()
+	chirp, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: chirpID, ViewerID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't report chirp")
+		return
+	}
+	if chirp.UserID == userID {
+		respondWithError(w, http.StatusBadRequest, "You can't report your own chirp")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't report chirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	caseID, err := qtx.OpenModerationCase(ctx, chirpID)
+	if err != nil {
+		log.Printf("OpenModerationCase error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't report chirp")
+		return
+	}
+	inserted, err := qtx.CreateReport(ctx, database.CreateReportParams{
+		CaseID:     caseID,
+		ReporterID: userID,
+		Reason:     params.Reason,
+		Comment:    params.Comment,
+	})
+	if err != nil {
+		log.Printf("CreateReport error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't report chirp")
+		return
+	}
+	// A repeated report by the same user leaves the case as it was.
+	if inserted > 0 {
+		if err := qtx.CountModerationCaseReport(ctx, caseID); err != nil {
+			log.Printf("CountModerationCaseReport error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't report chirp")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't report chirp")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleAddModerator godoc
+// @Summary      Make a user a moderator
+// @Description  Gives a user access to the moderation queue. Making a moderator a moderator again has no effect.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Param        userID         path    string  true  "User ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "User not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderators/{userID} [put]
+func (cfg *apiConfig) handleAddModerator(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	ctx := r.Context()
+	if _, err := cfg.dbQueries.GetUserByID(ctx, userID); errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		log.Printf("GetUserByID error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't add moderator")
+		return
+	}
+	if err := cfg.dbQueries.AddModerator(ctx, userID); err != nil {
+		log.Printf("AddModerator error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't add moderator")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoveModerator godoc
+// @Summary      Remove a moderator
+// @Description  Takes away a user's access to the moderation queue. Cases they have claimed stay claimed by them.
+// @Tags         admin
+// @Param        Authorization  header  string  true  "ApiKey <ADMIN_API_KEY>"
+// @Param        userID         path    string  true  "User ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid user ID"
+// @Failure      401  {object}  ErrorResponse "Invalid or missing API key"
+// @Failure      403  {object}  ErrorResponse "Admin API is disabled"
+// @Failure      404  {object}  ErrorResponse "Not a moderator"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/moderators/{userID} [delete]
+func (cfg *apiConfig) handleRemoveModerator(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	removed, err := cfg.dbQueries.RemoveModerator(r.Context(), userID)
+	if err != nil {
+		log.Printf("RemoveModerator error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove moderator")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "Not a moderator")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -16,7 +16,9 @@ func (cfg *apiConfig) resetMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// deleting users from database
+	// deleting users from database. Unlike DELETE /api/users this is a hard
+	// delete: the endpoint exists to wipe development databases, and
+	// soft-deleted users would keep their emails taken.
 	err := cfg.dbQueries.DeleteAllUsers(r.Context())
 	if err != nil {
 		log.Printf("Error deleting all users: %s", err)
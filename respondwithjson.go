@@ -2,15 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
-	"slices"
 	"time"
 	"github.com/google/uuid"
 	"github.com/odilmode/http/internal/auth"
-	"github.com/odilmode/http/internal/database"
-
 )
 
 // Chirp represents a microblog post (chirp) returned in responses
@@ -21,6 +18,26 @@ type Chirp struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	Body      string    `json:"body"`
 	UserID    uuid.UUID `json:"user_id"`
+	// Visibility is who can see the chirp: public, unlisted, followers or mentioned
+	Visibility string `json:"visibility" example:"public"`
+	// RechirpOf is the ID of the chirp this chirp reposts, if it is a rechirp
+	RechirpOf *uuid.UUID `json:"rechirp_of,omitempty"`
+	// QuoteOf is the ID of the chirp this chirp quotes, if it is a quote-chirp
+	QuoteOf *uuid.UUID `json:"quote_of,omitempty"`
+	// RechirpedChirp is the reposted chirp, omitted once the original is deleted
+	RechirpedChirp *Chirp `json:"rechirped_chirp,omitempty"`
+	// QuotedChirp is the quoted chirp, omitted once the original is deleted
+	QuotedChirp *Chirp `json:"quoted_chirp,omitempty"`
+	// RechirpCount is the number of times this chirp has been rechirped
+	RechirpCount int64 `json:"rechirp_count"`
+	// Entities lists the hashtags and mentions in Body
+	Entities *ChirpEntities `json:"entities"`
+	// Attachments are the images attached to the chirp, in display order
+	Attachments []Attachment `json:"attachments"`
+	// PublishAt is when a scheduled chirp will be published; omitted once it is
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Poll is the chirp's poll, if it has one
+	Poll *Poll `json:"poll,omitempty"`
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) error {
@@ -40,8 +57,18 @@ func respondWithError(w http.ResponseWriter, code int, msg string) error {
 // requestBody represents the JSON request body for creating a chirp
 type requestBody struct {
 	// Body is the text content of the chirp
-	// max length: 140 characters
+	// max length: 140 characters, 280 for Chirpy Red members; see GET /api/chirps/length
 	Body string `json:"body"`
+	// QuoteOf optionally references the chirp being quoted
+	QuoteOf *uuid.UUID `json:"quote_of,omitempty"`
+	// MediaIDs optionally attaches up to 4 uploaded media items
+	MediaIDs []uuid.UUID `json:"media_ids,omitempty"`
+	// PublishAt optionally schedules the chirp to be published at a future time
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Poll optionally attaches a poll to the chirp
+	Poll *pollRequest `json:"poll,omitempty"`
+	// Visibility is public (default), unlisted, followers or mentioned
+	Visibility string `json:"visibility,omitempty" example:"public"`
 }
 // responseBody represents the JSON response body after creating a chirp
 type responseBody struct {
@@ -50,14 +77,15 @@ type responseBody struct {
 }
 // handleChirps creates a new chirp
 // @Summary      Create a new chirp
-// @Description  Authenticated endpoint to create a chirp with max length 140 characters. Filters bad words.
+// @Description  Authenticated endpoint to create a chirp with max length 140 characters (280 for Chirpy Red members). Length is counted in user-perceived characters and links count as 23; see GET /api/chirps/length. Text is checked against the moderation rules: matches may be masked, flagged for review or cause the chirp to be rejected. Set quote_of to quote another chirp. Hashtags and @mentions are extracted into entities. Up to 4 uploaded media items can be attached with media_ids. Set publish_at to schedule the chirp; it stays hidden from everyone but the author until then. Set poll to attach a poll with 2-4 options. Set visibility to limit who can see the chirp: unlisted chirps are left out of the public listings, followers chirps are shown only to the author's followers and mentioned chirps only to the users they mention.
 // @Tags         chirps
 // @Accept       json
 // @Produce      json
 // @Param        chirp  body requestBody true "Chirp body"
 // @Success      201  {object}  Chirp
-// @Failure      400  {object}  ErrorResponse "Invalid author_id"
+// @Failure      400  {object}  ErrorResponse "Invalid request or chirp contains prohibited content"
 // @Failure      401  {object}  map[string]string  "Unauthorized - missing or invalid JWT"
+// @Failure      403  {object}  ErrorResponse "Account suspended"
 // @Failure      500  {object}  map[string]string  "Internal server error - failed to create chirp"
 // @Security     BearerAuth
 // @Router       /api/chirps [post]
@@ -68,7 +96,7 @@ func (cfg *apiConfig) handleChirps(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT")
 		return
 	}
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	userID, err := cfg.validateJWT(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, " Couldn't validate JWT")
 		return
@@ -79,34 +107,56 @@ func (cfg *apiConfig) handleChirps(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(params.Body) > 140 {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long")
+	user, err := cfg.dbQueries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "User not found")
 		return
 	}
 
-	cleanedText := wordreplace(params.Body)
-	now := time.Now().UTC()
-	chirpParams := database.CreateChirpParams{
-    		ID:        uuid.New(),
-    		CreatedAt: now,
-    		UpdatedAt: now,
-    		Body:      cleanedText,    // sanitized string
-   		UserID:    userID,  // from request
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp")
+		return
+	}
+	defer tx.Rollback()
+	chirp, err := cfg.createChirp(r.Context(), cfg.dbQueries.WithTx(tx), user, chirpInput{
+		Body:       params.Body,
+		QuoteOf:    params.QuoteOf,
+		MediaIDs:   params.MediaIDs,
+		PublishAt:  params.PublishAt,
+		Poll:       params.Poll,
+		Visibility: params.Visibility,
+	})
+	var invalid *invalidChirpError
+	if errors.As(err, &invalid) {
+		respondWithError(w, http.StatusBadRequest, invalid.Error())
+		return
+	}
+	if errors.Is(err, errAuthorSuspended) {
+		respondWithError(w, http.StatusForbidden, "Account suspended")
+		return
 	}
-	chirp, err := cfg.dbQueries.CreateChirp(r.Context(), chirpParams)
 	if err != nil {
-		fmt.Println("CreateChirp DB error:", err)
+		fmt.Println("createChirp DB error:", err)
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp")
 		return
 	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp")
+		return
+	}
+	if chirp.Published {
+		cfg.timelines.chirpCreated(r.Context(), chirp)
+		cfg.notifications.chirpPublished(r.Context(), chirp)
+		cfg.federation.chirpPublished(r.Context(), chirp)
+	}
 
-	respondWithJSON(w, http.StatusCreated, Chirp{
-		ID: chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body: chirp.Body,
-		UserID: chirp.UserID,
-	})
+	responseChirp, err := cfg.chirpResponse(r.Context(), userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, responseChirp)
 }
 // ErrorResponse represents an error response message
 // swagger:model ErrorResponse
@@ -114,14 +164,3 @@ type ErrorResponse struct {
     // Error message describing what went wrong
     Error string `json:"error"`
 }
-func wordreplace(sentence string) string {
-	badWords := []string{"kerfuffle","sharbert","fornax"}
-
-	words := strings.Split(sentence, " ")
-	for i, word := range words {
-		if slices.Contains(badWords, strings.ToLower(word)) {
-			words[i] = "****"
-		}
-	}
-	return strings.Join(words, " ")
-}
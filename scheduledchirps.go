@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/moderation"
+	"github.com/odilmode/http/internal/textlength"
+)
+
+const (
+	// maxScheduleAhead is how far in the future a chirp can be scheduled.
+	maxScheduleAhead = 365 * 24 * time.Hour
+	// publishInterval is how often the publisher looks for due chirps, and
+	// so roughly how late a scheduled chirp can be.
+	publishInterval  = 15 * time.Second
+	publishBatchSize = 100
+)
+
+func validatePublishAt(publishAt time.Time) error {
+	now := time.Now()
+	if !publishAt.After(now) {
+		return errors.New("publish_at must be in the future")
+	}
+	if publishAt.After(now.Add(maxScheduleAhead)) {
+		return errors.New("publish_at can be at most a year ahead")
+	}
+	return nil
+}
+
+// chirpPublisher publishes scheduled chirps once they are due. State lives
+// in the database, so chirps that came due while the server was down are
+// published on the next start. Each batch is claimed with FOR UPDATE SKIP
+// LOCKED, so several instances can run publishers without publishing a chirp
+// twice.
+type chirpPublisher struct {
+	queries       *database.Queries
+	timelines     *timelineFanout
+	notifications *notifier
+	federation    *federation
+}
+
+func newChirpPublisher(queries *database.Queries, timelines *timelineFanout, notifications *notifier, federation *federation) *chirpPublisher {
+	return &chirpPublisher{queries: queries, timelines: timelines, notifications: notifications, federation: federation}
+}
+
+// start publishes due chirps until ctx is cancelled.
+func (p *chirpPublisher) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(publishInterval)
+		defer ticker.Stop()
+		for {
+			p.publishDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *chirpPublisher) publishDue(ctx context.Context) {
+	for {
+		chirps, err := p.queries.PublishDueChirps(ctx, publishBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("PublishDueChirps error: %v", err)
+			}
+			return
+		}
+		for _, chirp := range chirps {
+			p.timelines.chirpCreated(ctx, chirp)
+			p.notifications.chirpPublished(ctx, chirp)
+			p.federation.chirpPublished(ctx, chirp)
+		}
+		if len(chirps) < publishBatchSize {
+			return
+		}
+	}
+}
+
+// updateScheduledChirpRequest is the body for editing a scheduled chirp.
+// Omitted fields are left unchanged.
+type updateScheduledChirpRequest struct {
+	Body      *string    `json:"body"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// handleGetScheduledChirps godoc
+// @Summary      List scheduled chirps
+// @Description  Lists the caller's chirps that haven't been published yet, soonest first
+// @Tags         chirps
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Success      200  {array}   Chirp
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/scheduled [get]
+func (cfg *apiConfig) handleGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirps, err := cfg.dbQueries.GetScheduledChirps(r.Context(), userID)
+	if err != nil {
+		log.Printf("GetScheduledChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get scheduled chirps")
+		return
+	}
+	resp, err := cfg.chirpResponses(r.Context(), userID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirps")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleUpdateScheduledChirp godoc
+// @Summary      Edit a scheduled chirp
+// @Description  Changes the body and/or publish time of one of the caller's scheduled chirps. The new body goes through the same length and moderation checks as a new chirp.
+// @Tags         chirps
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header  string                       true  "Bearer JWT token"
+// @Param        chirp_id       query   string                       true  "Scheduled chirp ID"
+// @Param        chirp          body    updateScheduledChirpRequest  true  "Fields to change"
+// @Success      200  {object}  Chirp
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID, body or publish time"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Scheduled chirp not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/scheduled [put]
+func (cfg *apiConfig) handleUpdateScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.URL.Query().Get("chirp_id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	var params updateScheduledChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request")
+		return
+	}
+
+	ctx := r.Context()
+	current, err := cfg.dbQueries.GetScheduledChirp(ctx, database.GetScheduledChirpParams{ID: chirpID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetScheduledChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+		return
+	}
+
+	update := database.UpdateScheduledChirpParams{
+		ID:        chirpID,
+		UserID:    userID,
+		Body:      current.Body,
+		PublishAt: current.PublishAt,
+	}
+	if params.PublishAt != nil {
+		if err := validatePublishAt(*params.PublishAt); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		poll, err := cfg.dbQueries.GetPoll(ctx, chirpID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("GetPoll error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+			return
+		}
+		if err == nil && poll.ClosesAt.Sub(*params.PublishAt) < minPollDuration {
+			respondWithError(w, http.StatusBadRequest, "The chirp's poll would close before or right after it is published")
+			return
+		}
+		update.PublishAt = sql.NullTime{Time: params.PublishAt.UTC(), Valid: true}
+	}
+	var moderated *moderation.Result
+	if params.Body != nil {
+		user, err := cfg.dbQueries.GetUserByID(ctx, userID)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "User not found")
+			return
+		}
+		if textlength.Count(*params.Body) > chirpLengthLimit(user) {
+			respondWithError(w, http.StatusBadRequest, "Chirp is too long")
+			return
+		}
+		result := cfg.moderator.check(*params.Body)
+		if result.Rejected {
+			respondWithError(w, http.StatusBadRequest, "Chirp contains prohibited content")
+			return
+		}
+		moderated = &result
+		update.Body = result.Text
+	}
+
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	chirp, err := qtx.UpdateScheduledChirp(ctx, update)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Published between the lookup and the update.
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("UpdateScheduledChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+		return
+	}
+	if moderated != nil {
+		if err := replaceChirpEntities(ctx, qtx, chirp); err != nil {
+			log.Printf("replaceChirpEntities error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+			return
+		}
+		// Flags the old body raised no longer match what moderators would
+		// review.
+		if err := qtx.DeletePendingModerationFlags(ctx, chirp.ID); err != nil {
+			log.Printf("DeletePendingModerationFlags error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+			return
+		}
+		if err := saveModerationFlags(ctx, qtx, chirp.ID, *moderated); err != nil {
+			log.Printf("saveModerationFlags error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp")
+		return
+	}
+
+	resp, err := cfg.chirpResponse(ctx, userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleDeleteScheduledChirp godoc
+// @Summary      Cancel a scheduled chirp
+// @Description  Deletes one of the caller's scheduled chirps before it is published
+// @Tags         chirps
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        chirp_id       query   string  true  "Scheduled chirp ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Scheduled chirp not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/scheduled [delete]
+func (cfg *apiConfig) handleDeleteScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.URL.Query().Get("chirp_id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	ctx := r.Context()
+	mediaKeys, err := cfg.chirpMediaKeys(ctx, chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp")
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteScheduledChirp(ctx, database.DeleteScheduledChirpParams{ID: chirpID, UserID: userID})
+	if err != nil {
+		log.Printf("DeleteScheduledChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found")
+		return
+	}
+	deleteMediaFiles(ctx, cfg.media, mediaKeys)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// replaceChirpEntities re-extracts hashtags and mentions after a chirp's
+// body changed.
+func replaceChirpEntities(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	if err := q.DeleteChirpHashtags(ctx, chirp.ID); err != nil {
+		return fmt.Errorf("clearing hashtags: %w", err)
+	}
+	if err := q.DeleteChirpMentions(ctx, chirp.ID); err != nil {
+		return fmt.Errorf("clearing mentions: %w", err)
+	}
+	return saveChirpEntities(ctx, q, chirp)
+}
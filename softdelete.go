@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/storage"
+)
+
+const (
+	// defaultRestoreWindow is how long deleted chirps and accounts are kept
+	// when RESTORE_WINDOW isn't set.
+	defaultRestoreWindow = 30 * 24 * time.Hour
+	purgeInterval        = time.Hour
+	purgeBatchSize       = 100
+)
+
+// handleRestoreChirp godoc
+// @Summary      Restore a deleted chirp
+// @Description  Undoes DELETE /api/chirps/{chirpID} for one of the caller's chirps, along with the rechirps that were deleted with it. Only possible within the restore window.
+// @Tags         chirps
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer JWT token"
+// @Param        chirpID        path    string  true  "Chirp ID"
+// @Success      200  {object}  Chirp
+// @Failure      400  {object}  ErrorResponse "Invalid chirp ID"
+// @Failure      401  {object}  ErrorResponse "Unauthorized or invalid token"
+// @Failure      404  {object}  ErrorResponse "Deleted chirp not found"
+// @Failure      409  {object}  ErrorResponse "The rechirped chirp is gone or was rechirped again"
+// @Failure      410  {object}  ErrorResponse "Restore window has passed"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/chirps/{chirpID}/restore [post]
+func (cfg *apiConfig) handleRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return
+	}
+
+	userID, err := cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+		return
+	}
+	ctx := r.Context()
+	deleted, err := cfg.dbQueries.GetDeletedChirp(ctx, database.GetDeletedChirpParams{ID: chirpID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Deleted chirp not found")
+		return
+	}
+	if err != nil {
+		log.Printf("GetDeletedChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp")
+		return
+	}
+	if time.Since(deleted.DeletedAt.Time) > cfg.restoreWindow {
+		respondWithError(w, http.StatusGone, "Restore window has passed")
+		return
+	}
+	if deleted.RechirpOf.Valid {
+		if _, err := cfg.dbQueries.GetChirp(ctx, database.GetChirpParams{ID: deleted.RechirpOf.UUID, ViewerID: userID}); err != nil {
+			respondWithError(w, http.StatusConflict, "The rechirped chirp has been deleted")
+			return
+		}
+	}
+
+	restored, err := cfg.dbQueries.RestoreChirp(ctx, database.RestoreChirpParams{
+		ID:        chirpID,
+		DeletedAt: deleted.DeletedAt,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Chirp already rechirped")
+		return
+	}
+	if err != nil {
+		log.Printf("RestoreChirp error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp")
+		return
+	}
+	var chirp database.Chirp
+	authors := make([]uuid.UUID, 0, len(restored))
+	for _, c := range restored {
+		if c.ID == chirpID {
+			chirp = c
+		}
+		authors = append(authors, c.UserID)
+	}
+	cfg.invalidateRestoredTimelines(ctx, authors)
+	if chirp.ID != chirpID {
+		// Purged or restored by a concurrent request.
+		respondWithError(w, http.StatusNotFound, "Deleted chirp not found")
+		return
+	}
+
+	resp, err := cfg.chirpResponse(ctx, userID, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// invalidateRestoredTimelines is called after restoring chirps by authors.
+// The chirps were dropped from materialized timelines on delete, so the
+// timelines of the authors' followers fall back to fan-out-on-read until
+// rebuilt.
+func (cfg *apiConfig) invalidateRestoredTimelines(ctx context.Context, authors []uuid.UUID) {
+	invalidated := map[uuid.UUID]bool{}
+	for _, authorID := range authors {
+		if invalidated[authorID] {
+			continue
+		}
+		invalidated[authorID] = true
+		if err := cfg.dbQueries.InvalidateFollowerTimelines(ctx, authorID); err != nil {
+			log.Printf("InvalidateFollowerTimelines error: %v", err)
+		}
+	}
+}
+
+// purger permanently removes chirps and accounts once their restore window
+// has passed, along with their media files and data exports.
+type purger struct {
+	queries *database.Queries
+	store   storage.Storage
+	window  time.Duration
+}
+
+func newPurger(queries *database.Queries, store storage.Storage, window time.Duration) *purger {
+	return &purger{queries: queries, store: store, window: window}
+}
+
+// start purges expired rows until ctx is cancelled.
+func (p *purger) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			p.purge(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *purger) purge(ctx context.Context) {
+	cutoff := sql.NullTime{Time: time.Now().UTC().Add(-p.window), Valid: true}
+	for {
+		ids, err := p.queries.GetPurgeableChirps(ctx, database.GetPurgeableChirpsParams{DeletedAt: cutoff, Limit: purgeBatchSize})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("GetPurgeableChirps error: %v", err)
+			}
+			return
+		}
+		for _, id := range ids {
+			media, err := p.queries.GetMediaForChirps(ctx, []uuid.UUID{id})
+			if err != nil {
+				log.Printf("GetMediaForChirps error: %v", err)
+				return
+			}
+			if err := p.purgeRow(ctx, media, func() error { return p.queries.PurgeChirp(ctx, id) }); err != nil {
+				log.Printf("purge error: %v", err)
+				return
+			}
+		}
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+	for {
+		ids, err := p.queries.GetPurgeableUsers(ctx, database.GetPurgeableUsersParams{DeletedAt: cutoff, Limit: purgeBatchSize})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("GetPurgeableUsers error: %v", err)
+			}
+			return
+		}
+		for _, id := range ids {
+			media, err := p.queries.GetMediaByUser(ctx, id)
+			if err != nil {
+				log.Printf("GetMediaByUser error: %v", err)
+				return
+			}
+			// Data export archives are also deleted by cascade.
+			exports, err := p.queries.GetDataExportKeysByUser(ctx, id)
+			if err != nil {
+				log.Printf("GetDataExportKeysByUser error: %v", err)
+				return
+			}
+			if err := p.purgeRow(ctx, media, func() error { return p.queries.PurgeUser(ctx, id) }); err != nil {
+				log.Printf("purge error: %v", err)
+				return
+			}
+			deleteMediaFiles(ctx, p.store, exports)
+		}
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+}
+
+// purgeRow deletes a row with del and then the files of the media that went
+// with it. The keys are read first because the media rows are deleted by
+// cascade.
+func (p *purger) purgeRow(ctx context.Context, media []database.Medium, del func() error) error {
+	keys, err := storedMediaKeys(ctx, p.queries, media)
+	if err != nil {
+		return fmt.Errorf("listing media files: %w", err)
+	}
+	if err := del(); err != nil {
+		return err
+	}
+	deleteMediaFiles(ctx, p.store, keys)
+	return nil
+}
@@ -0,0 +1,79 @@
+-- name: CreateActorKey :exec
+INSERT INTO actor_keys (user_id, created_at, public_key_pem, private_key_pem)
+VALUES ($1, NOW(), $2, $3)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: GetActorKey :one
+SELECT * FROM actor_keys
+WHERE user_id = $1;
+
+-- name: UpsertRemoteActor :one
+INSERT INTO remote_actors (id, key_id, public_key_pem, inbox, shared_inbox, username, fetched_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (id) DO UPDATE
+SET key_id = EXCLUDED.key_id,
+	public_key_pem = EXCLUDED.public_key_pem,
+	inbox = EXCLUDED.inbox,
+	shared_inbox = EXCLUDED.shared_inbox,
+	username = EXCLUDED.username,
+	fetched_at = EXCLUDED.fetched_at
+RETURNING *;
+
+-- name: GetRemoteActorByKeyID :one
+SELECT * FROM remote_actors
+WHERE key_id = $1;
+
+-- name: DeleteRemoteActor :exec
+DELETE FROM remote_actors
+WHERE id = $1;
+
+-- name: CreateRemoteFollow :exec
+INSERT INTO remote_follows (user_id, actor_id, activity_id, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (user_id, actor_id) DO UPDATE
+SET activity_id = EXCLUDED.activity_id;
+
+-- name: DeleteRemoteFollow :exec
+DELETE FROM remote_follows
+WHERE user_id = $1 AND actor_id = $2;
+
+-- name: CountRemoteFollowers :one
+SELECT COUNT(*) FROM remote_follows
+WHERE user_id = $1;
+
+-- name: EnqueueDelivery :exec
+INSERT INTO ap_deliveries (id, created_at, user_id, inbox, activity, next_attempt_at)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, NOW());
+
+-- name: EnqueueFollowerDeliveries :exec
+INSERT INTO ap_deliveries (id, created_at, user_id, inbox, activity, next_attempt_at)
+SELECT gen_random_uuid(), NOW(), @user_id::uuid, inboxes.inbox, @activity::text, NOW()
+FROM (
+	SELECT DISTINCT remote_actors.shared_inbox AS inbox
+	FROM remote_follows
+	JOIN remote_actors ON remote_actors.id = remote_follows.actor_id
+	WHERE remote_follows.user_id = @user_id::uuid
+) inboxes;
+
+-- name: ClaimDueDeliveries :many
+UPDATE ap_deliveries
+SET next_attempt_at = NOW() + make_interval(secs => @lease_seconds::float8)
+WHERE id IN (
+	SELECT due.id FROM ap_deliveries due
+	WHERE due.next_attempt_at <= NOW()
+	ORDER BY due.next_attempt_at
+	LIMIT @batch_size
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RetryDelivery :exec
+UPDATE ap_deliveries
+SET attempts = attempts + 1,
+	next_attempt_at = NOW() + make_interval(secs => @delay_seconds::float8),
+	last_error = @last_error
+WHERE id = @id;
+
+-- name: DeleteDelivery :exec
+DELETE FROM ap_deliveries
+WHERE id = $1;
@@ -0,0 +1,45 @@
+-- name: CreateBlock :execrows
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = @user_id AND followee_id = @other_id)
+	OR (follower_id = @other_id AND followee_id = @user_id);
+
+-- name: GetBlocks :many
+SELECT users.id, users.username, users.created_at, users.is_chirpy_red, blocks.created_at AS blocked_at
+FROM blocks
+JOIN users ON users.id = blocks.blocked_id
+WHERE blocks.blocker_id = @user_id
+	AND users.deleted_at IS NULL
+	AND (blocks.created_at, users.id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY blocks.created_at DESC, users.id DESC
+LIMIT @page_limit;
+
+-- name: UsersBlocked :one
+SELECT users_blocked(@user_id::uuid, @other_id::uuid) AS blocked;
+
+-- name: CreateMute :execrows
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :execrows
+DELETE FROM mutes
+WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: GetMutes :many
+SELECT users.id, users.username, users.created_at, users.is_chirpy_red, mutes.created_at AS muted_at
+FROM mutes
+JOIN users ON users.id = mutes.muted_id
+WHERE mutes.muter_id = @user_id
+	AND users.deleted_at IS NULL
+	AND (mutes.created_at, users.id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY mutes.created_at DESC, users.id DESC
+LIMIT @page_limit;
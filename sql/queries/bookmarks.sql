@@ -0,0 +1,20 @@
+-- name: CreateBookmark :exec
+INSERT INTO bookmarks (user_id, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBookmark :exec
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetBookmarks :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = @user_id
+	AND chirps.published
+	AND chirps.deleted_at IS NULL
+	AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @user_id)
+	AND (bookmarks.created_at, bookmarks.chirp_id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY bookmarks.created_at DESC, bookmarks.chirp_id DESC
+LIMIT @page_limit;
@@ -0,0 +1,33 @@
+-- name: GetLatestChirpEventID :one
+SELECT COALESCE(MAX(id), 0)::bigint AS id FROM chirp_events;
+
+-- name: GetChirpEventsAfter :many
+SELECT * FROM chirp_events
+WHERE id > @after_id OR id = ANY(@missing_ids::bigint[])
+ORDER BY id
+LIMIT @page_limit;
+
+-- name: DeleteOldChirpEvents :exec
+DELETE FROM chirp_events
+WHERE created_at < NOW() - make_interval(secs => @max_age_seconds::float8);
+
+-- name: GetStreamChirp :one
+SELECT * FROM chirps
+WHERE id = @id
+	AND published
+	AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
+	AND (NOT @listed_only::boolean OR (
+		(visibility <> 'unlisted' OR user_id = @viewer_id::uuid)
+		AND NOT user_muted(@viewer_id::uuid, user_id)
+	));
+
+-- name: GetDeletedStreamChirpVisibility :one
+SELECT visibility FROM chirps
+WHERE id = @id
+	AND published
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
+	AND (NOT @listed_only::boolean OR (
+		(visibility <> 'unlisted' OR user_id = @viewer_id::uuid)
+		AND NOT user_muted(@viewer_id::uuid, user_id)
+	));
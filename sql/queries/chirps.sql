@@ -1,32 +1,113 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, quote_of, publish_at, published, visibility)
 VALUES (
 	$1,
 	$2,
 	$3,
 	$4,
-	$5
+	$5,
+	$6,
+	sqlc.narg('publish_at'),
+	sqlc.narg('publish_at') IS NULL,
+	$8
 )
 RETURNING *;
 
 -- name: GetAllChirps :many
 SELECT *
 FROM chirps
+WHERE published AND deleted_at IS NULL
+	AND (visibility <> 'unlisted' OR user_id = @viewer_id::uuid)
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
+	AND NOT user_muted(@viewer_id::uuid, user_id)
 ORDER BY created_at ASC;
 
 
 -- name: GetChirp :one
 SELECT *
 FROM chirps
-WHERE id = $1;
+WHERE id = $1 AND published AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid);
 
 
--- name: DeleteChirp :exec
+-- name: SoftDeleteChirp :many
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE (id = @id OR rechirp_of = @id) AND deleted_at IS NULL
+RETURNING id;
+
+-- name: GetDeletedChirp :one
+SELECT * FROM chirps
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL;
+
+-- name: RestoreChirp :many
+UPDATE chirps
+SET deleted_at = NULL
+WHERE (id = @id OR rechirp_of = @id) AND deleted_at = @deleted_at
+RETURNING *;
+
+-- name: SoftDeleteUserChirps :many
+UPDATE chirps
+SET deleted_at = @deleted_at
+WHERE (user_id = @user_id OR rechirp_of IN (SELECT id FROM chirps WHERE user_id = @user_id))
+	AND deleted_at IS NULL
+RETURNING id;
+
+-- name: RestoreUserChirps :many
+UPDATE chirps
+SET deleted_at = NULL
+WHERE (user_id = @user_id OR rechirp_of IN (SELECT id FROM chirps WHERE user_id = @user_id))
+	AND deleted_at = @deleted_at
+RETURNING user_id;
+
+-- name: GetPurgeableChirps :many
+SELECT id FROM chirps
+WHERE deleted_at < $1
+ORDER BY deleted_at
+LIMIT $2;
+
+-- name: PurgeChirp :exec
 DELETE FROM chirps
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NOT NULL;
 
 -- name: GetChirpsByAuthor :many
 SELECT * FROM chirps
-WHERE user_id = $1
+WHERE user_id = $1 AND published AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
 ORDER BY created_at DESC;
 
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(@ids::uuid[]) AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid);
+
+-- name: GetScheduledChirps :many
+SELECT * FROM chirps
+WHERE user_id = $1 AND NOT published AND deleted_at IS NULL
+ORDER BY publish_at, id;
+
+-- name: GetScheduledChirp :one
+SELECT * FROM chirps
+WHERE id = $1 AND user_id = $2 AND NOT published AND deleted_at IS NULL;
+
+-- name: UpdateScheduledChirp :one
+UPDATE chirps
+SET body = $3, publish_at = $4, updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND NOT published AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND NOT published AND deleted_at IS NULL;
+
+-- name: PublishDueChirps :many
+UPDATE chirps
+SET published = TRUE, created_at = NOW(), updated_at = NOW()
+WHERE id IN (
+	SELECT due.id FROM chirps due
+	WHERE NOT due.published AND due.deleted_at IS NULL AND due.publish_at <= NOW()
+	ORDER BY due.publish_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
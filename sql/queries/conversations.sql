@@ -0,0 +1,122 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (id, created_at, updated_at)
+VALUES ($1, NOW(), NOW())
+RETURNING *;
+
+-- name: AddConversationParticipant :exec
+INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+VALUES ($1, $2, NOW());
+
+-- name: CountUsersByIDs :one
+SELECT COUNT(*) FROM users
+WHERE id = ANY(@ids::uuid[]) AND deleted_at IS NULL;
+
+-- name: UserBlocksAny :one
+SELECT EXISTS (
+	SELECT 1 FROM unnest(@other_ids::uuid[]) AS others(id)
+	WHERE users_blocked(others.id, @user_id::uuid)
+);
+
+-- name: ListConversations :many
+SELECT sqlc.embed(conversations), (
+	SELECT COUNT(*) FROM messages
+	WHERE messages.conversation_id = conversations.id
+		AND messages.sender_id <> @user_id
+		AND (conversation_participants.last_read_at IS NULL OR messages.created_at > conversation_participants.last_read_at)
+		AND NOT users_blocked(messages.sender_id, @user_id)
+) AS unread_count
+FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversation_participants.user_id = @user_id
+	AND conversation_participants.left_at IS NULL
+	AND (conversations.updated_at, conversations.id) < (@before_updated_at::timestamp, @before_id::uuid)
+ORDER BY conversations.updated_at DESC, conversations.id DESC
+LIMIT @page_limit;
+
+-- name: GetConversation :one
+SELECT sqlc.embed(conversations), (
+	SELECT COUNT(*) FROM messages
+	WHERE messages.conversation_id = conversations.id
+		AND messages.sender_id <> @user_id
+		AND (conversation_participants.last_read_at IS NULL OR messages.created_at > conversation_participants.last_read_at)
+		AND NOT users_blocked(messages.sender_id, @user_id)
+) AS unread_count
+FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversations.id = @id
+	AND conversation_participants.user_id = @user_id
+	AND conversation_participants.left_at IS NULL;
+
+-- name: GetConversationParticipants :many
+SELECT conversation_participants.conversation_id, conversation_participants.user_id, users.username,
+	conversation_participants.joined_at, conversation_participants.last_read_message_id, conversation_participants.last_read_at
+FROM conversation_participants
+JOIN users ON users.id = conversation_participants.user_id
+WHERE conversation_participants.conversation_id = ANY(@conversation_ids::uuid[])
+	AND conversation_participants.left_at IS NULL
+ORDER BY conversation_participants.conversation_id, conversation_participants.joined_at, conversation_participants.user_id;
+
+-- name: IsConversationParticipant :one
+SELECT EXISTS (
+	SELECT 1 FROM conversation_participants
+	WHERE conversation_id = $1 AND user_id = $2 AND left_at IS NULL
+);
+
+-- name: ConversationHasBlock :one
+SELECT EXISTS (
+	SELECT 1 FROM conversation_participants
+	WHERE conversation_id = @conversation_id
+		AND user_id <> @user_id
+		AND left_at IS NULL
+		AND users_blocked(user_id, @user_id)
+);
+
+-- name: ConversationEventVisible :one
+SELECT EXISTS (
+	SELECT 1 FROM conversation_participants
+	WHERE conversation_id = @conversation_id
+		AND user_id = @viewer_id
+		AND left_at IS NULL
+		AND NOT users_blocked(@actor_id, @viewer_id)
+);
+
+-- name: LeaveConversation :execrows
+UPDATE conversation_participants
+SET left_at = NOW()
+WHERE conversation_id = $1 AND user_id = $2 AND left_at IS NULL;
+
+-- name: CreateMessage :one
+INSERT INTO messages (id, created_at, conversation_id, sender_id, body)
+VALUES ($1, NOW(), $2, $3, $4)
+RETURNING *;
+
+-- name: TouchConversation :exec
+UPDATE conversations
+SET updated_at = $2
+WHERE id = $1;
+
+-- name: GetMessage :one
+SELECT * FROM messages
+WHERE id = $1 AND conversation_id = $2;
+
+-- name: GetMessages :many
+SELECT * FROM messages
+WHERE conversation_id = @conversation_id
+	AND NOT users_blocked(sender_id, @viewer_id)
+	AND (created_at, id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: GetLatestMessages :many
+SELECT DISTINCT ON (conversation_id) * FROM messages
+WHERE conversation_id = ANY(@conversation_ids::uuid[])
+	AND NOT users_blocked(sender_id, @viewer_id::uuid)
+ORDER BY conversation_id, created_at DESC, id DESC;
+
+-- name: MarkConversationRead :execrows
+UPDATE conversation_participants
+SET last_read_message_id = @message_id, last_read_at = @read_at
+WHERE conversation_id = @conversation_id
+	AND user_id = @user_id
+	AND left_at IS NULL
+	AND (last_read_at IS NULL OR last_read_at < @read_at);
@@ -9,7 +9,8 @@ FROM users
 JOIN refresh_tokens ON refresh_tokens.user_id = users.id
 WHERE refresh_tokens.token = $1
 	AND refresh_tokens.expires_at > now()
-	AND refresh_tokens.revoked_at is NULL;
+	AND refresh_tokens.revoked_at is NULL
+	AND users.deleted_at IS NULL;
 
 
 -- name: RevokeRefreshToken :exec
@@ -17,3 +18,10 @@ UPDATE refresh_tokens
 SET revoked_at = now(),
 updated_at = now()
 WHERE token = $1;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = now(),
+updated_at = now()
+WHERE user_id = $1
+	AND revoked_at IS NULL;
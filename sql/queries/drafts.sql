@@ -0,0 +1,28 @@
+-- name: CreateDraft :one
+INSERT INTO drafts (id, user_id, created_at, updated_at, body, quote_of, media_ids, visibility)
+VALUES ($1, $2, NOW(), NOW(), $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetDrafts :many
+SELECT * FROM drafts
+WHERE user_id = $1
+ORDER BY updated_at DESC;
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $3, quote_of = $4, media_ids = $5, visibility = $6, updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2;
+
+-- name: TakeDraft :one
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2
+RETURNING *;
@@ -0,0 +1,81 @@
+-- name: LockDataExportRequests :exec
+SELECT pg_advisory_xact_lock(hashtextextended('data_exports:' || @user_id::uuid::text, 0));
+
+-- name: CreateDataExport :one
+INSERT INTO data_exports (id, created_at, updated_at, user_id)
+SELECT gen_random_uuid(), NOW(), NOW(), @user_id::uuid
+WHERE NOT EXISTS (
+	SELECT 1 FROM data_exports
+	WHERE user_id = @user_id::uuid
+		AND status <> 'failed'
+		AND created_at > NOW() - make_interval(secs => @interval_seconds::float8)
+)
+RETURNING *;
+
+-- name: GetLatestDataExport :one
+SELECT * FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetReadyDataExport :one
+SELECT * FROM data_exports
+WHERE id = $1 AND status = 'ready' AND expires_at > NOW()
+	AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL);
+
+-- name: ClaimDataExports :many
+UPDATE data_exports
+SET status = 'processing', updated_at = NOW()
+WHERE id IN (
+	SELECT due.id FROM data_exports due
+	WHERE due.status = 'pending'
+		OR (due.status = 'processing' AND due.updated_at < NOW() - make_interval(secs => @timeout_seconds::float8))
+	ORDER BY due.created_at
+	LIMIT @batch_size
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready',
+	storage_key = @storage_key,
+	size_bytes = @size_bytes,
+	expires_at = NOW() + make_interval(secs => @ttl_seconds::float8),
+	updated_at = NOW()
+WHERE id = @id;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetExpiredDataExports :many
+SELECT * FROM data_exports
+WHERE expires_at < NOW()
+	OR (status = 'failed' AND updated_at < NOW() - make_interval(secs => @failed_ttl_seconds::float8))
+ORDER BY created_at
+LIMIT @batch_size;
+
+-- name: DeleteDataExport :exec
+DELETE FROM data_exports
+WHERE id = $1;
+
+-- name: GetDataExportKeysByUser :many
+SELECT storage_key::text FROM data_exports
+WHERE user_id = $1 AND storage_key IS NOT NULL;
+
+-- name: GetChirpsForExport :many
+SELECT * FROM chirps
+WHERE user_id = $1
+ORDER BY created_at, id;
+
+-- name: GetSessionsForExport :many
+SELECT created_at, expires_at, revoked_at FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetModerationActionsForExport :many
+SELECT id, created_at, action, chirp_id, note FROM moderation_actions
+WHERE target_user_id = $1 AND action <> 'claim'
+ORDER BY created_at;
@@ -0,0 +1,28 @@
+-- name: GetUserFeedChirps :many
+SELECT * FROM chirps
+WHERE user_id = @user_id
+	AND published
+	AND deleted_at IS NULL
+	AND rechirp_of IS NULL
+	AND visibility = 'public'
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: GetHashtagFeedChirps :many
+SELECT sqlc.embed(chirps), users.username
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id IN (
+	SELECT chirp_hashtags.chirp_id
+	FROM chirp_hashtags
+	JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+	WHERE hashtags.tag = @tag
+)
+	AND chirps.published
+	AND chirps.deleted_at IS NULL
+	AND chirps.rechirp_of IS NULL
+	AND chirps.visibility = 'public'
+	AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @viewer_id::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT @page_limit;
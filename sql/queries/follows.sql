@@ -0,0 +1,83 @@
+-- name: CreateFollow :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollow :execrows
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: CountFollowers :one
+SELECT COUNT(*) FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = $1 AND users.deleted_at IS NULL;
+
+-- name: CountFollowing :one
+SELECT COUNT(*) FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = $1 AND users.deleted_at IS NULL;
+
+-- name: GetFollowers :many
+SELECT users.id, users.username, users.created_at, users.is_chirpy_red, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = @user_id
+	AND users.deleted_at IS NULL
+	AND (follows.created_at, users.id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT @page_limit;
+
+-- name: GetFollowing :many
+SELECT users.id, users.username, users.created_at, users.is_chirpy_red, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = @user_id
+	AND users.deleted_at IS NULL
+	AND (follows.created_at, users.id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT @page_limit;
+
+-- name: GetHomeTimeline :many
+SELECT * FROM chirps
+WHERE (
+	user_id = @user_id
+	OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = @user_id)
+)
+	AND published
+	AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @user_id)
+	AND NOT user_muted(@user_id, user_id)
+	AND (created_at, id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: GetTimelineChirp :one
+SELECT * FROM chirps
+WHERE id = @id
+	AND (
+		user_id = @viewer_id
+		OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = @viewer_id)
+	)
+	AND published
+	AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @viewer_id)
+	AND NOT user_muted(@viewer_id, user_id);
+
+-- name: WasTimelineChirp :one
+SELECT EXISTS (
+	SELECT 1 FROM chirps
+	WHERE id = @id
+		AND (
+			user_id = @viewer_id
+			OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = @viewer_id)
+		)
+		AND published
+		AND chirp_visible(id, user_id, visibility, @viewer_id)
+		AND NOT user_muted(@viewer_id, user_id)
+);
+
+-- name: IsFollowing :one
+SELECT EXISTS (
+	SELECT 1 FROM follows
+	WHERE follower_id = $1 AND followee_id = $2
+);
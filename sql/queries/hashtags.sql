@@ -0,0 +1,35 @@
+-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, tag, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING *;
+
+-- name: CreateChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, start_offset, end_offset)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetHashtagEntities :many
+SELECT chirp_hashtags.chirp_id, hashtags.tag, chirp_hashtags.start_offset, chirp_hashtags.end_offset
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE chirp_hashtags.chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_hashtags.chirp_id, chirp_hashtags.start_offset;
+
+-- name: GetChirpsByHashtag :many
+SELECT * FROM chirps
+WHERE id IN (
+	SELECT chirp_hashtags.chirp_id
+	FROM chirp_hashtags
+	JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+	WHERE hashtags.tag = $1
+)
+	AND published
+	AND deleted_at IS NULL
+	AND (visibility <> 'unlisted' OR user_id = @viewer_id::uuid)
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
+	AND NOT user_muted(@viewer_id::uuid, user_id)
+ORDER BY created_at DESC;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
@@ -0,0 +1,83 @@
+-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, storage_key, content_type, size_bytes, width, height, alt_text, status, upload_key)
+VALUES ($1, NOW(), $2, $3, $4, $5, $6, $7, $8, 'pending', $9)
+RETURNING *;
+
+-- name: GetMedia :one
+SELECT * FROM media
+WHERE id = $1
+	AND NOT EXISTS (
+		SELECT 1 FROM chirps
+		WHERE chirps.id = media.chirp_id
+			AND (chirps.deleted_at IS NOT NULL OR NOT chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @viewer_id::uuid))
+	);
+
+-- name: AttachMedia :execrows
+UPDATE media
+SET chirp_id = @chirp_id, position = @position
+WHERE id = @id
+	AND user_id = @user_id
+	AND chirp_id IS NULL;
+
+-- name: GetMediaForChirps :many
+SELECT * FROM media
+WHERE chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: ClaimMediaForProcessing :one
+UPDATE media
+SET status = 'processing', claimed_at = NOW()
+WHERE id = (
+	SELECT claimable.id FROM media claimable
+	WHERE claimable.id = @id
+		AND (claimable.status = 'pending'
+			OR (claimable.status = 'processing'
+				AND (claimable.claimed_at IS NULL
+					OR claimable.claimed_at < NOW() - make_interval(secs => @lease_seconds::float8))))
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteMediaProcessing :exec
+UPDATE media
+SET status = 'ready',
+	storage_key = $2,
+	content_type = $3,
+	size_bytes = $4,
+	width = $5,
+	height = $6,
+	blurhash = $7,
+	upload_key = '',
+	processed_at = NOW()
+WHERE id = $1;
+
+-- name: FailMediaProcessing :exec
+UPDATE media
+SET status = 'failed', upload_key = '', processed_at = NOW()
+WHERE id = $1;
+
+-- name: ListUnprocessedMedia :many
+SELECT id FROM media
+WHERE status IN ('pending', 'processing')
+	AND created_at < $1
+ORDER BY created_at
+LIMIT 100;
+
+-- name: UpsertMediaVariant :exec
+INSERT INTO media_variants (media_id, name, storage_key, content_type, size_bytes, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (media_id, name) DO UPDATE
+SET storage_key = EXCLUDED.storage_key,
+	content_type = EXCLUDED.content_type,
+	size_bytes = EXCLUDED.size_bytes,
+	width = EXCLUDED.width,
+	height = EXCLUDED.height;
+
+-- name: GetMediaVariants :many
+SELECT * FROM media_variants
+WHERE media_id = ANY(@media_ids::uuid[])
+ORDER BY media_id, width;
+
+-- name: GetMediaByUser :many
+SELECT * FROM media
+WHERE user_id = $1;
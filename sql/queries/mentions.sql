@@ -0,0 +1,31 @@
+-- name: CreateMention :exec
+INSERT INTO mentions (chirp_id, user_id, start_offset, end_offset)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetMentionEntities :many
+SELECT mentions.chirp_id, mentions.user_id, users.username, mentions.start_offset, mentions.end_offset
+FROM mentions
+JOIN users ON users.id = mentions.user_id
+WHERE mentions.chirp_id = ANY(@chirp_ids::uuid[])
+	AND users.deleted_at IS NULL
+ORDER BY mentions.chirp_id, mentions.start_offset;
+
+-- name: GetChirpsMentioningUser :many
+SELECT * FROM chirps
+WHERE id IN (
+	SELECT chirp_id FROM mentions
+	WHERE user_id = $1
+)
+	AND published
+	AND deleted_at IS NULL
+	AND chirp_visible(id, user_id, visibility, @viewer_id::uuid)
+	AND NOT user_muted(@viewer_id::uuid, user_id)
+ORDER BY created_at DESC;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM mentions
+WHERE chirp_id = $1;
+
+-- name: GetMentionedUserIDs :many
+SELECT DISTINCT user_id FROM mentions
+WHERE chirp_id = $1;
@@ -0,0 +1,63 @@
+-- name: ListModerationRules :many
+SELECT * FROM moderation_rules
+ORDER BY position, created_at;
+
+-- name: ListModerationWords :many
+SELECT rule_id, word FROM moderation_words
+ORDER BY rule_id, word;
+
+-- name: GetModerationRule :one
+SELECT * FROM moderation_rules
+WHERE id = $1;
+
+-- name: CreateModerationRule :one
+INSERT INTO moderation_rules (id, created_at, updated_at, position, kind, action, pattern, description, enabled)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdateModerationRule :one
+UPDATE moderation_rules
+SET position = COALESCE(sqlc.narg('position'), position),
+	action = COALESCE(sqlc.narg('action'), action),
+	pattern = COALESCE(sqlc.narg('pattern'), pattern),
+	description = COALESCE(sqlc.narg('description'), description),
+	enabled = COALESCE(sqlc.narg('enabled'), enabled),
+	updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteModerationRule :execrows
+DELETE FROM moderation_rules
+WHERE id = $1;
+
+-- name: AddModerationWords :exec
+INSERT INTO moderation_words (rule_id, word, created_at)
+SELECT @rule_id, unnest(@words::text[]), NOW()
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteModerationWord :execrows
+DELETE FROM moderation_words
+WHERE rule_id = $1 AND word = $2;
+
+-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags (id, created_at, chirp_id, rule_id, matched_text)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3);
+
+-- name: DeletePendingModerationFlags :exec
+DELETE FROM moderation_flags
+WHERE chirp_id = $1 AND reviewed_at IS NULL;
+
+-- name: ListPendingModerationFlags :many
+SELECT moderation_flags.id, moderation_flags.created_at, moderation_flags.chirp_id,
+	moderation_flags.rule_id, moderation_flags.matched_text, chirps.body, chirps.user_id
+FROM moderation_flags
+JOIN chirps ON chirps.id = moderation_flags.chirp_id
+WHERE moderation_flags.reviewed_at IS NULL
+	AND chirps.deleted_at IS NULL
+ORDER BY moderation_flags.created_at
+LIMIT 100;
+
+-- name: ResolveModerationFlag :execrows
+UPDATE moderation_flags
+SET reviewed_at = NOW()
+WHERE id = $1 AND reviewed_at IS NULL;
@@ -0,0 +1,84 @@
+-- name: UpsertNotification :one
+INSERT INTO notifications (id, created_at, updated_at, user_id, type, chirp_id, group_key)
+SELECT @id::uuid, NOW(), NOW(), @user_id::uuid, @type::text, sqlc.narg(chirp_id)::uuid, @group_key::text
+WHERE @user_id::uuid <> @actor_id::uuid
+	AND NOT users_blocked(@user_id::uuid, @actor_id::uuid)
+	AND NOT user_muted(@user_id::uuid, @actor_id::uuid)
+	AND NOT EXISTS (
+		SELECT 1 FROM notification_preferences
+		WHERE notification_preferences.user_id = @user_id::uuid
+			AND notification_preferences.type = @type::text
+			AND NOT notification_preferences.enabled
+	)
+ON CONFLICT (user_id, group_key) WHERE read_at IS NULL
+DO UPDATE SET updated_at = NOW()
+RETURNING id;
+
+-- name: CreateExportNotification :exec
+INSERT INTO notifications (id, created_at, updated_at, user_id, type, export_id, group_key)
+VALUES ($1, NOW(), NOW(), $2, 'export_ready', $3, $4);
+
+-- name: AddNotificationActor :exec
+WITH added AS (
+	INSERT INTO notification_actors (notification_id, actor_id, source_chirp_id, created_at)
+	VALUES (@notification_id, @actor_id, @source_chirp_id, NOW())
+	ON CONFLICT DO NOTHING
+	RETURNING notification_id
+)
+UPDATE notifications
+SET actor_count = actor_count + 1
+WHERE id IN (SELECT notification_id FROM added);
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE user_id = @user_id
+	AND (NOT @unread_only::boolean OR read_at IS NULL)
+	AND (chirp_id IS NULL OR chirp_id IN (SELECT id FROM chirps WHERE deleted_at IS NULL))
+	AND (updated_at, id) < (@before_updated_at::timestamp, @before_id::uuid)
+ORDER BY updated_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: GetNotification :one
+SELECT * FROM notifications
+WHERE id = $1
+	AND user_id = $2
+	AND (chirp_id IS NULL OR chirp_id IN (SELECT id FROM chirps WHERE deleted_at IS NULL));
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1
+	AND read_at IS NULL
+	AND (chirp_id IS NULL OR chirp_id IN (SELECT id FROM chirps WHERE deleted_at IS NULL));
+
+-- name: GetNotificationActors :many
+SELECT recent.notification_id, users.id, users.username, users.created_at, users.is_chirpy_red
+FROM (
+	SELECT notification_actors.notification_id, notification_actors.actor_id, notification_actors.created_at,
+		row_number() OVER (PARTITION BY notification_actors.notification_id ORDER BY notification_actors.created_at DESC) AS position
+	FROM notification_actors
+	WHERE notification_actors.notification_id = ANY(@notification_ids::uuid[])
+) AS recent
+JOIN users ON users.id = recent.actor_id
+WHERE recent.position <= @per_notification::bigint
+	AND users.deleted_at IS NULL
+	AND NOT users_blocked(users.id, @user_id::uuid)
+ORDER BY recent.notification_id, recent.created_at DESC;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: GetNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: SetNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, type, enabled)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled;
@@ -0,0 +1,37 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, multiple, closes_at)
+VALUES ($1, $2, $3);
+
+-- name: CreatePollOption :exec
+INSERT INTO poll_options (chirp_id, position, text)
+VALUES ($1, $2, $3);
+
+-- name: GetPoll :one
+SELECT * FROM polls
+WHERE chirp_id = $1;
+
+-- name: GetPollsForChirps :many
+SELECT * FROM polls
+WHERE chirp_id = ANY(@chirp_ids::uuid[]);
+
+-- name: GetPollOptionsForChirps :many
+SELECT * FROM poll_options
+WHERE chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: CreatePollBallot :execrows
+INSERT INTO poll_ballots (chirp_id, user_id, choices, created_at)
+SELECT polls.chirp_id, @user_id, @choices::integer[], NOW()
+FROM polls
+WHERE polls.chirp_id = @chirp_id AND polls.closes_at > NOW()
+ON CONFLICT DO NOTHING;
+
+-- name: CountPollVotes :exec
+UPDATE poll_options
+SET vote_count = vote_count + 1
+WHERE chirp_id = @chirp_id AND position = ANY(@choices::integer[]);
+
+-- name: CountPollVoter :exec
+UPDATE polls
+SET voter_count = voter_count + 1
+WHERE chirp_id = $1;
@@ -0,0 +1,2 @@
+-- name: PublishRealtimeEvent :exec
+SELECT pg_notify('realtime_events', @payload::text);
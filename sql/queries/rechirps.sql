@@ -0,0 +1,25 @@
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of, visibility)
+VALUES (
+	$1,
+	$2,
+	$3,
+	'',
+	$4,
+	$5,
+	$6
+)
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: SoftDeleteRechirp :many
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE user_id = $1 AND rechirp_of = $2 AND deleted_at IS NULL
+RETURNING id;
+
+-- name: CountRechirps :many
+SELECT rechirp_of, COUNT(*) AS count
+FROM chirps
+WHERE rechirp_of = ANY(@ids::uuid[]) AND deleted_at IS NULL
+GROUP BY rechirp_of;
@@ -0,0 +1,93 @@
+-- name: IsModerator :one
+SELECT EXISTS (
+	SELECT 1 FROM moderators
+	WHERE user_id = $1
+);
+
+-- name: AddModerator :exec
+INSERT INTO moderators (user_id, created_at)
+VALUES ($1, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveModerator :execrows
+DELETE FROM moderators
+WHERE user_id = $1;
+
+-- name: OpenModerationCase :one
+INSERT INTO moderation_cases (id, created_at, updated_at, chirp_id)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1)
+ON CONFLICT (chirp_id) WHERE resolved_at IS NULL
+DO UPDATE SET updated_at = NOW()
+RETURNING id;
+
+-- name: CreateReport :execrows
+INSERT INTO reports (id, created_at, case_id, reporter_id, reason, comment)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4)
+ON CONFLICT (case_id, reporter_id) DO NOTHING;
+
+-- name: CountModerationCaseReport :exec
+UPDATE moderation_cases
+SET report_count = report_count + 1
+WHERE id = $1;
+
+-- name: ListOpenModerationCases :many
+SELECT sqlc.embed(moderation_cases), chirps.body, chirps.user_id
+FROM moderation_cases
+JOIN chirps ON chirps.id = moderation_cases.chirp_id
+WHERE moderation_cases.resolved_at IS NULL
+	AND chirps.deleted_at IS NULL
+	AND (NOT @unclaimed::boolean OR moderation_cases.claimed_by IS NULL)
+ORDER BY moderation_cases.created_at
+LIMIT 100;
+
+-- name: GetModerationCase :one
+SELECT sqlc.embed(moderation_cases), chirps.body, chirps.user_id
+FROM moderation_cases
+JOIN chirps ON chirps.id = moderation_cases.chirp_id
+WHERE moderation_cases.id = $1;
+
+-- name: GetModerationCaseReports :many
+SELECT * FROM reports
+WHERE case_id = $1
+ORDER BY created_at;
+
+-- name: ClaimModerationCase :execrows
+UPDATE moderation_cases
+SET claimed_by = @moderator_id, claimed_at = NOW(), updated_at = NOW()
+WHERE id = @id
+	AND resolved_at IS NULL
+	AND (claimed_by IS NULL OR claimed_by = @moderator_id);
+
+-- name: ResolveModerationCase :execrows
+UPDATE moderation_cases
+SET resolved_by = @moderator_id, resolved_at = NOW(), resolution = @resolution, updated_at = NOW()
+WHERE id = @id
+	AND resolved_at IS NULL
+	AND claimed_by = @moderator_id;
+
+-- name: HideChirp :exec
+INSERT INTO hidden_chirps (chirp_id, hidden_at)
+VALUES ($1, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: SuspendUser :exec
+INSERT INTO suspended_users (user_id, suspended_at)
+VALUES ($1, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: IsUserSuspended :one
+SELECT EXISTS (
+	SELECT 1 FROM suspended_users
+	WHERE user_id = $1
+);
+
+-- name: CreateModerationAction :exec
+INSERT INTO moderation_actions (id, created_at, moderator_id, case_id, action, chirp_id, target_user_id, note)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5, $6);
+
+-- name: ListModerationActions :many
+SELECT * FROM moderation_actions
+WHERE (sqlc.narg('case_id')::uuid IS NULL OR case_id = sqlc.narg('case_id'))
+	AND (created_at, id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit;
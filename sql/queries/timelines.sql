@@ -0,0 +1,103 @@
+-- name: GetTimeline :one
+SELECT * FROM timelines
+WHERE user_id = $1;
+
+-- name: MarkTimelineBuilt :exec
+INSERT INTO timelines (user_id, built_at)
+VALUES ($1, NOW())
+ON CONFLICT (user_id) DO UPDATE SET built_at = EXCLUDED.built_at;
+
+-- name: InvalidateTimeline :exec
+DELETE FROM timelines
+WHERE user_id = $1;
+
+-- name: InvalidateFollowerTimelines :exec
+DELETE FROM timelines
+WHERE user_id = @author_id
+	OR user_id IN (SELECT follower_id FROM follows WHERE followee_id = @author_id);
+
+-- name: ClearTimelineEntries :exec
+DELETE FROM timeline_entries
+WHERE user_id = $1;
+
+-- name: FillTimeline :exec
+INSERT INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT @user_id::uuid, chirps.id, chirps.user_id, chirps.created_at
+FROM chirps
+WHERE (
+	chirps.user_id = @user_id
+	OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = @user_id)
+)
+	AND chirps.published
+	AND chirps.deleted_at IS NULL
+	AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @user_id)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT @max_entries
+ON CONFLICT DO NOTHING;
+
+-- name: FanOutChirp :exec
+INSERT INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT timelines.user_id, @chirp_id::uuid, @author_id::uuid, @created_at::timestamp
+FROM timelines
+WHERE timelines.user_id = @author_id
+	OR (@include_followers::boolean AND timelines.user_id IN (
+		SELECT follower_id FROM follows WHERE followee_id = @author_id
+	))
+FOR SHARE OF timelines
+ON CONFLICT DO NOTHING;
+
+-- name: GetFollowerCount :one
+SELECT COALESCE(
+	(SELECT followers FROM follower_counts WHERE user_id = $1),
+	0
+)::bigint AS followers;
+
+-- name: GetOverfullTimelines :many
+SELECT user_id FROM timeline_entries
+GROUP BY user_id
+HAVING COUNT(*) > @max_entries::bigint
+LIMIT @batch_size;
+
+-- name: TrimTimeline :exec
+DELETE FROM timeline_entries
+WHERE timeline_entries.user_id = @user_id
+	AND (timeline_entries.created_at, timeline_entries.chirp_id) <= (
+		SELECT newer.created_at, newer.chirp_id
+		FROM timeline_entries newer
+		WHERE newer.user_id = @user_id
+		ORDER BY newer.created_at DESC, newer.chirp_id DESC
+		OFFSET @max_entries LIMIT 1
+	);
+
+-- name: GetCachedTimeline :many
+SELECT chirps.*
+FROM timeline_entries
+JOIN chirps ON chirps.id = timeline_entries.chirp_id
+WHERE timeline_entries.user_id = @user_id
+	AND chirps.deleted_at IS NULL
+	AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @user_id)
+	AND NOT user_muted(@user_id, chirps.user_id)
+	AND (timeline_entries.created_at, timeline_entries.chirp_id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY timeline_entries.created_at DESC, timeline_entries.chirp_id DESC
+LIMIT @page_limit;
+
+-- name: GetPopularAuthorChirps :many
+SELECT * FROM chirps
+WHERE chirps.user_id IN (
+	SELECT follows.followee_id
+	FROM follows
+	JOIN follower_counts ON follower_counts.user_id = follows.followee_id
+	WHERE follows.follower_id = @user_id
+		AND follower_counts.followers > @min_followers
+)
+	AND chirps.published
+	AND chirps.deleted_at IS NULL
+	AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @user_id)
+	AND NOT user_muted(@user_id, chirps.user_id)
+	AND (chirps.created_at, chirps.id) < (@before_created_at::timestamp, @before_id::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT @page_limit;
+
+-- name: DeleteTimelineEntriesForChirps :exec
+DELETE FROM timeline_entries
+WHERE chirp_id = ANY(@chirp_ids::uuid[]);
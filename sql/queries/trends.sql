@@ -0,0 +1,74 @@
+-- name: GetHashtagUsage :many
+SELECT hashtags.id, hashtags.tag,
+	array_agg(EXTRACT(EPOCH FROM NOW() - chirps.created_at))::float8[] AS ages,
+	COUNT(DISTINCT chirps.user_id) AS authors
+FROM (SELECT DISTINCT chirp_id, hashtag_id FROM chirp_hashtags) AS tagged
+JOIN chirps ON chirps.id = tagged.chirp_id
+JOIN hashtags ON hashtags.id = tagged.hashtag_id
+WHERE chirps.created_at > NOW() - make_interval(secs => @window_seconds::float8)
+	AND chirps.published
+	AND chirps.deleted_at IS NULL
+	AND chirps.visibility = 'public'
+	AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, NULL)
+GROUP BY hashtags.id, hashtags.tag;
+
+-- name: GetHashtagBaselineUses :many
+SELECT tagged.hashtag_id, COUNT(*) AS uses
+FROM (
+	SELECT DISTINCT chirp_id, hashtag_id FROM chirp_hashtags
+	WHERE hashtag_id = ANY(@hashtag_ids::uuid[])
+) AS tagged
+JOIN chirps ON chirps.id = tagged.chirp_id
+WHERE chirps.created_at > NOW() - make_interval(secs => @baseline_seconds::float8)
+	AND chirps.created_at <= NOW() - make_interval(secs => @window_seconds::float8)
+	AND chirps.published
+	AND chirps.deleted_at IS NULL
+	AND chirps.visibility = 'public'
+GROUP BY tagged.hashtag_id;
+
+-- name: CreateTrendSnapshot :exec
+INSERT INTO trend_snapshots (id, window_name, computed_at)
+VALUES ($1, $2, NOW());
+
+-- name: CreateTrendEntry :exec
+INSERT INTO trend_entries (snapshot_id, rank, hashtag_id, score, uses, authors)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: DeleteOldTrendSnapshots :exec
+DELETE FROM trend_snapshots
+WHERE computed_at < NOW() - make_interval(secs => @max_age_seconds::float8);
+
+-- name: GetLatestTrendSnapshot :one
+SELECT * FROM trend_snapshots
+WHERE window_name = $1
+ORDER BY computed_at DESC
+LIMIT 1;
+
+-- name: GetTrendEntries :many
+SELECT trend_entries.rank, trend_entries.hashtag_id, hashtags.tag, trend_entries.score, trend_entries.uses, trend_entries.authors
+FROM trend_entries
+JOIN hashtags ON hashtags.id = trend_entries.hashtag_id
+WHERE trend_entries.snapshot_id = $1
+ORDER BY trend_entries.rank
+LIMIT $2;
+
+-- name: GetTrendSampleChirps :many
+SELECT hashtags.id AS hashtag_id, sqlc.embed(chirps)
+FROM hashtags
+JOIN LATERAL (
+	SELECT * FROM chirps
+	WHERE chirps.id IN (
+		SELECT chirp_id FROM chirp_hashtags
+		WHERE chirp_hashtags.hashtag_id = hashtags.id
+	)
+		AND chirps.rechirp_of IS NULL
+		AND chirps.published
+		AND chirps.deleted_at IS NULL
+		AND chirps.visibility = 'public'
+		AND chirp_visible(chirps.id, chirps.user_id, chirps.visibility, @viewer_id::uuid)
+		AND NOT user_muted(@viewer_id::uuid, chirps.user_id)
+	ORDER BY chirps.created_at DESC
+	LIMIT @per_tag::int
+) AS chirps ON TRUE
+WHERE hashtags.id = ANY(@hashtag_ids::uuid[])
+ORDER BY hashtags.id, chirps.created_at DESC;
@@ -1,29 +1,81 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, username)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
     $2,
-    FALSE
+    FALSE,
+    $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at;
 
 -- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at
 FROM users
-WHERE email = $1;
+WHERE email = $1 AND deleted_at IS NULL;
+
+-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at
+FROM users
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetDeletedUserByEmail :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at
+FROM users
+WHERE email = $1 AND deleted_at IS NOT NULL;
+
+-- name: GetUserByUsername :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at
+FROM users
+WHERE username = @username::text AND deleted_at IS NULL;
+
+-- name: GetUsersByUsernames :many
+SELECT id, username
+FROM users
+WHERE username = ANY(@usernames::text[])
+	AND deleted_at IS NULL
+	AND NOT users_blocked(id, @author_id::uuid);
 
 -- name: UpdateUser :one
 UPDATE users
-SET email = $2, hashed_password = $3, updated_at = NOW()
-WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
+SET email = $2, hashed_password = $3, username = COALESCE(sqlc.narg(username), username), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at;
 
 
 -- name: UpgradeUserToChirpyRed :exec
 UPDATE users
 SET is_chirpy_red = TRUE
-WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, deleted_at;
+
+-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING deleted_at;
+
+-- name: RestoreUser :execrows
+UPDATE users
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at = $2;
+
+-- name: GetPurgeableUsers :many
+SELECT id FROM users
+WHERE deleted_at < $1
+ORDER BY deleted_at
+LIMIT $2;
+
+-- name: PurgeUser :exec
+DELETE FROM users
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: IsUserActive :one
+SELECT EXISTS (
+	SELECT 1 FROM users
+	WHERE users.id = $1
+		AND users.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM suspended_users WHERE suspended_users.user_id = users.id)
+);
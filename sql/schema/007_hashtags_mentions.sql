@@ -0,0 +1,45 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN username TEXT UNIQUE;
+
+CREATE TABLE hashtags (
+	id UUID PRIMARY KEY,
+	tag TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE chirp_hashtags (
+	chirp_id UUID NOT NULL,
+	hashtag_id UUID NOT NULL,
+	start_offset INTEGER NOT NULL,
+	end_offset INTEGER NOT NULL,
+	PRIMARY KEY (chirp_id, start_offset),
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (hashtag_id) REFERENCES hashtags(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_hashtags_hashtag_id_idx ON chirp_hashtags (hashtag_id);
+
+CREATE TABLE mentions (
+	chirp_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	start_offset INTEGER NOT NULL,
+	end_offset INTEGER NOT NULL,
+	PRIMARY KEY (chirp_id, start_offset),
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX mentions_user_id_idx ON mentions (user_id);
+
+-- +goose Down
+DROP TABLE mentions;
+DROP TABLE chirp_hashtags;
+DROP TABLE hashtags;
+
+ALTER TABLE users
+DROP COLUMN username;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE follows (
+	follower_id UUID NOT NULL,
+	followee_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (follower_id, followee_id),
+	CHECK (follower_id <> followee_id),
+	FOREIGN KEY (follower_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (followee_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX follows_followee_id_idx ON follows (followee_id, created_at);
+
+CREATE INDEX chirps_user_id_created_at_idx ON chirps (user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX chirps_user_id_created_at_idx;
+DROP TABLE follows;
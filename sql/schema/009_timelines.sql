@@ -0,0 +1,29 @@
+-- +goose Up
+-- timelines records which users have a materialized home timeline. Only
+-- those users receive fan-out-on-write entries; everyone else is served by
+-- fan-out-on-read until their timeline is built.
+CREATE TABLE timelines (
+	user_id UUID PRIMARY KEY,
+	built_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE TABLE timeline_entries (
+	user_id UUID NOT NULL,
+	chirp_id UUID NOT NULL,
+	author_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, chirp_id),
+	FOREIGN KEY (user_id) REFERENCES timelines(user_id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX timeline_entries_user_id_created_at_idx
+ON timeline_entries (user_id, created_at DESC, chirp_id DESC);
+
+-- +goose Down
+DROP TABLE timeline_entries;
+DROP TABLE timelines;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE media (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	chirp_id UUID,
+	position INTEGER NOT NULL DEFAULT 0,
+	storage_key TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size_bytes BIGINT NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	alt_text TEXT NOT NULL DEFAULT '',
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX media_chirp_id_idx ON media (chirp_id, position);
+
+-- +goose Down
+DROP TABLE media;
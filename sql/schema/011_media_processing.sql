@@ -0,0 +1,35 @@
+-- +goose Up
+-- Uploads are stored under upload_key until the image pipeline has
+-- produced the published renditions. Existing media predates processing and
+-- is marked ready.
+ALTER TABLE media
+ADD COLUMN status TEXT NOT NULL DEFAULT 'ready',
+ADD COLUMN upload_key TEXT NOT NULL DEFAULT '',
+ADD COLUMN blurhash TEXT NOT NULL DEFAULT '',
+ADD COLUMN processed_at TIMESTAMP;
+
+CREATE INDEX media_status_idx ON media (status)
+WHERE status IN ('pending', 'processing');
+
+CREATE TABLE media_variants (
+	media_id UUID NOT NULL,
+	name TEXT NOT NULL,
+	storage_key TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size_bytes BIGINT NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	PRIMARY KEY (media_id, name),
+	FOREIGN KEY (media_id) REFERENCES media(id)
+		ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE media_variants;
+DROP INDEX media_status_idx;
+
+ALTER TABLE media
+DROP COLUMN processed_at,
+DROP COLUMN blurhash,
+DROP COLUMN upload_key,
+DROP COLUMN status;
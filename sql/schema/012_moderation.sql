@@ -0,0 +1,53 @@
+-- +goose Up
+CREATE TABLE moderation_rules (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	kind TEXT NOT NULL CHECK (kind IN ('words', 'regex')),
+	action TEXT NOT NULL CHECK (action IN ('mask', 'reject', 'flag')),
+	pattern TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	enabled BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE moderation_words (
+	rule_id UUID NOT NULL,
+	word TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (rule_id, word),
+	FOREIGN KEY (rule_id) REFERENCES moderation_rules(id)
+		ON DELETE CASCADE
+);
+
+-- Chirps that matched a flag rule and are waiting for review.
+CREATE TABLE moderation_flags (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	chirp_id UUID NOT NULL,
+	rule_id UUID,
+	matched_text TEXT NOT NULL,
+	reviewed_at TIMESTAMP,
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (rule_id) REFERENCES moderation_rules(id)
+		ON DELETE SET NULL
+);
+
+CREATE INDEX moderation_flags_pending_idx ON moderation_flags (created_at)
+WHERE reviewed_at IS NULL;
+
+-- The word list that used to be hardcoded.
+WITH default_rule AS (
+	INSERT INTO moderation_rules (id, created_at, updated_at, position, kind, action, description)
+	VALUES (gen_random_uuid(), NOW(), NOW(), 0, 'words', 'mask', 'Default profanity filter')
+	RETURNING id
+)
+INSERT INTO moderation_words (rule_id, word, created_at)
+SELECT id, word, NOW()
+FROM default_rule, unnest(ARRAY['kerfuffle', 'sharbert', 'fornax']) AS word;
+
+-- +goose Down
+DROP TABLE moderation_flags;
+DROP TABLE moderation_words;
+DROP TABLE moderation_rules;
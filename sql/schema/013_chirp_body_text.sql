@@ -0,0 +1,11 @@
+-- +goose Up
+-- Chirp length is enforced by the API in grapheme clusters with links
+-- weighted to a fixed length, which VARCHAR(140) can't express: a 140
+-- character chirp may hold many more code points, and Chirpy Red members
+-- get a higher limit.
+ALTER TABLE chirps
+ALTER COLUMN body TYPE TEXT;
+
+-- +goose Down
+ALTER TABLE chirps
+ALTER COLUMN body TYPE VARCHAR(140) USING left(body, 140);
@@ -0,0 +1,16 @@
+-- +goose Up
+-- A scheduled chirp is stored unpublished with the time it should go out.
+-- Until the publisher flips published, it is only visible to its author.
+ALTER TABLE chirps
+ADD COLUMN publish_at TIMESTAMP,
+ADD COLUMN published BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX chirps_scheduled_idx ON chirps (publish_at)
+WHERE NOT published;
+
+-- +goose Down
+DROP INDEX chirps_scheduled_idx;
+
+ALTER TABLE chirps
+DROP COLUMN published,
+DROP COLUMN publish_at;
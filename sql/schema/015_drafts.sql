@@ -0,0 +1,17 @@
+-- +goose Up
+-- Drafts are unfinished chirps. They aren't held to the chirp length limit
+-- and their quote and media references are only checked when published.
+CREATE TABLE drafts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    body TEXT NOT NULL,
+    quote_of UUID,
+    media_ids UUID[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX drafts_user_id_updated_at_idx ON drafts (user_id, updated_at DESC);
+
+-- +goose Down
+DROP TABLE drafts;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Deleted chirps and accounts are kept for a restore window and then purged
+-- by the server. Rows with deleted_at set are invisible to every read query.
+ALTER TABLE chirps
+ADD COLUMN deleted_at TIMESTAMP;
+
+ALTER TABLE users
+ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX chirps_deleted_at_idx ON chirps (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX users_deleted_at_idx ON users (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+-- A deleted rechirp mustn't stop the user rechirping the same chirp again.
+DROP INDEX chirps_user_rechirp_idx;
+
+CREATE UNIQUE INDEX chirps_user_rechirp_idx
+ON chirps (user_id, rechirp_of)
+WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DELETE FROM chirps WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX chirps_user_rechirp_idx;
+
+CREATE UNIQUE INDEX chirps_user_rechirp_idx
+ON chirps (user_id, rechirp_of)
+WHERE rechirp_of IS NOT NULL;
+
+DROP INDEX users_deleted_at_idx;
+DROP INDEX chirps_deleted_at_idx;
+
+ALTER TABLE users
+DROP COLUMN deleted_at;
+
+ALTER TABLE chirps
+DROP COLUMN deleted_at;
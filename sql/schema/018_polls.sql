@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE polls (
+	chirp_id UUID PRIMARY KEY,
+	multiple BOOLEAN NOT NULL,
+	closes_at TIMESTAMP NOT NULL,
+	voter_count INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE
+);
+
+-- Tallies are kept as counters rather than counted from ballots, so closed
+-- polls keep their results when voters' accounts are purged.
+CREATE TABLE poll_options (
+	chirp_id UUID NOT NULL,
+	position INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	vote_count INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (chirp_id, position),
+	FOREIGN KEY (chirp_id) REFERENCES polls(chirp_id)
+		ON DELETE CASCADE
+);
+
+-- One ballot per user and poll; choices holds the option positions.
+CREATE TABLE poll_ballots (
+	chirp_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	choices INTEGER[] NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (chirp_id, user_id),
+	FOREIGN KEY (chirp_id) REFERENCES polls(chirp_id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE poll_ballots;
+DROP TABLE poll_options;
+DROP TABLE polls;
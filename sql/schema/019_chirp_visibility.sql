@@ -0,0 +1,41 @@
+-- +goose Up
+-- public: anyone, listed everywhere. unlisted: anyone with the link or on
+-- the author's profile, but left out of the global and hashtag listings.
+-- followers: the author's followers. mentioned: only the users mentioned.
+-- The author and mentioned users can always see a chirp.
+ALTER TABLE chirps
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+	CHECK (visibility IN ('public', 'unlisted', 'followers', 'mentioned'));
+
+ALTER TABLE drafts
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+	CHECK (visibility IN ('public', 'unlisted', 'followers', 'mentioned'));
+
+-- chirp_visible reports whether viewer_id may see a chirp. Anonymous
+-- viewers are passed as the nil UUID, which matches no user.
+-- +goose StatementBegin
+CREATE FUNCTION chirp_visible(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT visibility IN ('public', 'unlisted')
+		OR author_id = viewer_id
+		OR (visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM follows
+			WHERE follows.follower_id = viewer_id AND follows.followee_id = author_id
+		))
+		OR EXISTS (
+			SELECT 1 FROM mentions
+			WHERE mentions.chirp_id = chirp_visible.chirp_id AND mentions.user_id = viewer_id
+		);
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION chirp_visible(UUID, UUID, TEXT, UUID);
+
+ALTER TABLE drafts
+DROP COLUMN visibility;
+
+ALTER TABLE chirps
+DROP COLUMN visibility;
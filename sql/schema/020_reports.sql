@@ -0,0 +1,137 @@
+-- +goose Up
+CREATE TABLE moderators (
+	user_id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+-- Reports against a chirp are grouped into one case while it is unresolved;
+-- reports that come in after it is resolved open a new case.
+CREATE TABLE moderation_cases (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	chirp_id UUID NOT NULL,
+	report_count INTEGER NOT NULL DEFAULT 0,
+	claimed_by UUID,
+	claimed_at TIMESTAMP,
+	resolved_by UUID,
+	resolved_at TIMESTAMP,
+	resolution TEXT CHECK (resolution IN ('hide_chirp', 'suspend_author', 'dismiss')),
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (claimed_by) REFERENCES users(id)
+		ON DELETE SET NULL,
+	FOREIGN KEY (resolved_by) REFERENCES users(id)
+		ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX moderation_cases_open_idx ON moderation_cases (chirp_id)
+WHERE resolved_at IS NULL;
+
+CREATE INDEX moderation_cases_queue_idx ON moderation_cases (created_at)
+WHERE resolved_at IS NULL;
+
+-- A user can report a chirp once per case.
+CREATE TABLE reports (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	case_id UUID NOT NULL,
+	reporter_id UUID NOT NULL,
+	reason TEXT NOT NULL
+		CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+	comment TEXT NOT NULL DEFAULT '',
+	UNIQUE (case_id, reporter_id),
+	FOREIGN KEY (case_id) REFERENCES moderation_cases(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (reporter_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE TABLE hidden_chirps (
+	chirp_id UUID PRIMARY KEY,
+	hidden_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE
+);
+
+CREATE TABLE suspended_users (
+	user_id UUID PRIMARY KEY,
+	suspended_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+-- The moderation audit log. It has no foreign keys so entries survive the
+-- moderator, case, chirp or user they refer to being deleted.
+CREATE TABLE moderation_actions (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	moderator_id UUID NOT NULL,
+	case_id UUID,
+	action TEXT NOT NULL CHECK (action IN ('claim', 'hide_chirp', 'suspend_author', 'dismiss')),
+	chirp_id UUID,
+	target_user_id UUID,
+	note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX moderation_actions_created_idx ON moderation_actions (created_at DESC, id DESC);
+
+-- Hidden chirps and chirps by suspended users are only visible to their
+-- author.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT author_id = viewer_id
+		OR (
+			NOT EXISTS (
+				SELECT 1 FROM hidden_chirps
+				WHERE hidden_chirps.chirp_id = chirp_visible.chirp_id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM suspended_users
+				WHERE suspended_users.user_id = author_id
+			)
+			AND (
+				visibility IN ('public', 'unlisted')
+				OR (visibility = 'followers' AND EXISTS (
+					SELECT 1 FROM follows
+					WHERE follows.follower_id = viewer_id AND follows.followee_id = author_id
+				))
+				OR EXISTS (
+					SELECT 1 FROM mentions
+					WHERE mentions.chirp_id = chirp_visible.chirp_id AND mentions.user_id = viewer_id
+				)
+			)
+		);
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT visibility IN ('public', 'unlisted')
+		OR author_id = viewer_id
+		OR (visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM follows
+			WHERE follows.follower_id = viewer_id AND follows.followee_id = author_id
+		))
+		OR EXISTS (
+			SELECT 1 FROM mentions
+			WHERE mentions.chirp_id = chirp_visible.chirp_id AND mentions.user_id = viewer_id
+		);
+$$;
+-- +goose StatementEnd
+
+DROP TABLE moderation_actions;
+DROP TABLE suspended_users;
+DROP TABLE hidden_chirps;
+DROP TABLE reports;
+DROP TABLE moderation_cases;
+DROP TABLE moderators;
@@ -0,0 +1,121 @@
+-- +goose Up
+CREATE TABLE blocks (
+	blocker_id UUID NOT NULL,
+	blocked_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (blocker_id, blocked_id),
+	CHECK (blocker_id <> blocked_id),
+	FOREIGN KEY (blocker_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (blocked_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+
+CREATE TABLE mutes (
+	muter_id UUID NOT NULL,
+	muted_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (muter_id, muted_id),
+	CHECK (muter_id <> muted_id),
+	FOREIGN KEY (muter_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (muted_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+-- users_blocked reports whether either user has blocked the other.
+-- +goose StatementBegin
+CREATE FUNCTION users_blocked(a UUID, b UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT EXISTS (
+		SELECT 1 FROM blocks
+		WHERE (blocker_id = a AND blocked_id = b)
+			OR (blocker_id = b AND blocked_id = a)
+	);
+$$;
+-- +goose StatementEnd
+
+-- user_muted reports whether muter_id has muted muted_id.
+-- +goose StatementBegin
+CREATE FUNCTION user_muted(muter_id UUID, muted_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT EXISTS (
+		SELECT 1 FROM mutes
+		WHERE mutes.muter_id = user_muted.muter_id AND mutes.muted_id = user_muted.muted_id
+	);
+$$;
+-- +goose StatementEnd
+
+-- Chirps are hidden between users when either has blocked the other.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT author_id = viewer_id
+		OR (
+			NOT EXISTS (
+				SELECT 1 FROM hidden_chirps
+				WHERE hidden_chirps.chirp_id = chirp_visible.chirp_id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM suspended_users
+				WHERE suspended_users.user_id = author_id
+			)
+			AND NOT users_blocked(author_id, viewer_id)
+			AND (
+				visibility IN ('public', 'unlisted')
+				OR (visibility = 'followers' AND EXISTS (
+					SELECT 1 FROM follows
+					WHERE follows.follower_id = viewer_id AND follows.followee_id = author_id
+				))
+				OR EXISTS (
+					SELECT 1 FROM mentions
+					WHERE mentions.chirp_id = chirp_visible.chirp_id AND mentions.user_id = viewer_id
+				)
+			)
+		);
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+	SELECT author_id = viewer_id
+		OR (
+			NOT EXISTS (
+				SELECT 1 FROM hidden_chirps
+				WHERE hidden_chirps.chirp_id = chirp_visible.chirp_id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM suspended_users
+				WHERE suspended_users.user_id = author_id
+			)
+			AND (
+				visibility IN ('public', 'unlisted')
+				OR (visibility = 'followers' AND EXISTS (
+					SELECT 1 FROM follows
+					WHERE follows.follower_id = viewer_id AND follows.followee_id = author_id
+				))
+				OR EXISTS (
+					SELECT 1 FROM mentions
+					WHERE mentions.chirp_id = chirp_visible.chirp_id AND mentions.user_id = viewer_id
+				)
+			)
+		);
+$$;
+-- +goose StatementEnd
+
+DROP FUNCTION user_muted(UUID, UUID);
+DROP FUNCTION users_blocked(UUID, UUID);
+DROP TABLE mutes;
+DROP TABLE blocks;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Each run of the trends job stores one snapshot per window; the API serves
+-- the latest.
+CREATE TABLE trend_snapshots (
+	id UUID PRIMARY KEY,
+	window_name TEXT NOT NULL,
+	computed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX trend_snapshots_window_name_computed_at_idx ON trend_snapshots (window_name, computed_at DESC);
+
+CREATE TABLE trend_entries (
+	snapshot_id UUID NOT NULL,
+	rank INTEGER NOT NULL,
+	hashtag_id UUID NOT NULL,
+	score DOUBLE PRECISION NOT NULL,
+	uses INTEGER NOT NULL,
+	authors INTEGER NOT NULL,
+	PRIMARY KEY (snapshot_id, rank),
+	FOREIGN KEY (snapshot_id) REFERENCES trend_snapshots(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (hashtag_id) REFERENCES hashtags(id)
+		ON DELETE CASCADE
+);
+
+-- Usage is counted by when chirps were created.
+CREATE INDEX chirps_created_at_idx ON chirps (created_at);
+
+-- +goose Down
+DROP INDEX chirps_created_at_idx;
+DROP TABLE trend_entries;
+DROP TABLE trend_snapshots;
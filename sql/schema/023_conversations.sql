@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE conversations (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	-- updated_at is when the last message was sent, so conversations can be
+	-- listed by activity.
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE conversation_participants (
+	conversation_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	joined_at TIMESTAMP NOT NULL,
+	left_at TIMESTAMP,
+	-- The newest message the participant has read, and when it was sent.
+	last_read_message_id UUID,
+	last_read_at TIMESTAMP,
+	PRIMARY KEY (conversation_id, user_id),
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX conversation_participants_user_id_idx ON conversation_participants (user_id)
+	WHERE left_at IS NULL;
+
+CREATE TABLE messages (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	conversation_id UUID NOT NULL,
+	sender_id UUID NOT NULL,
+	body TEXT NOT NULL,
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (sender_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX messages_conversation_id_created_at_idx ON messages (conversation_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP TABLE messages;
+DROP TABLE conversation_participants;
+DROP TABLE conversations;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Similar notifications are grouped: while a notification is unread, new
+-- events with the same group_key add their actor to it instead of creating
+-- another one.
+CREATE TABLE notifications (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('mention', 'quote', 'rechirp', 'follow')),
+	chirp_id UUID,
+	group_key TEXT NOT NULL,
+	actor_count INTEGER NOT NULL DEFAULT 0,
+	read_at TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX notifications_unread_group_idx ON notifications (user_id, group_key)
+	WHERE read_at IS NULL;
+CREATE INDEX notifications_user_id_updated_at_idx ON notifications (user_id, updated_at DESC, id DESC);
+
+CREATE TABLE notification_actors (
+	notification_id UUID NOT NULL,
+	actor_id UUID NOT NULL,
+	-- source_chirp_id is the actor's chirp behind the event, e.g. the quote.
+	source_chirp_id UUID,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (notification_id, actor_id),
+	FOREIGN KEY (notification_id) REFERENCES notifications(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (actor_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (source_chirp_id) REFERENCES chirps(id)
+		ON DELETE CASCADE
+);
+
+-- Notification types are on unless a row turns them off.
+CREATE TABLE notification_preferences (
+	user_id UUID NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('mention', 'quote', 'rechirp', 'follow')),
+	enabled BOOLEAN NOT NULL,
+	PRIMARY KEY (user_id, type),
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE notification_preferences;
+DROP TABLE notification_actors;
+DROP TABLE notifications;
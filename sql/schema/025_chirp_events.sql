@@ -0,0 +1,60 @@
+-- +goose Up
+-- chirp_events logs chirps appearing and disappearing so streaming clients
+-- can resume from the last event they saw. Rows have no foreign keys so they
+-- outlive purged chirps.
+CREATE TABLE chirp_events (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	type TEXT NOT NULL CHECK (type IN ('created', 'deleted')),
+	chirp_id UUID NOT NULL,
+	user_id UUID NOT NULL
+);
+
+CREATE INDEX chirp_events_created_at_idx ON chirp_events (created_at);
+
+-- A chirp is created when it is inserted published, when a scheduled chirp
+-- is published or when a deleted chirp is restored, and deleted when it is
+-- soft-deleted. Every event is announced on the chirp_events channel with its
+-- ID; NOTIFY is delivered when the transaction commits.
+-- +goose StatementBegin
+CREATE FUNCTION record_chirp_event()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+	kind TEXT;
+	event_id BIGINT;
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		IF NEW.published AND NEW.deleted_at IS NULL THEN
+			kind := 'created';
+		END IF;
+	ELSIF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+		IF OLD.published THEN
+			kind := 'deleted';
+		END IF;
+	ELSIF NEW.published AND NEW.deleted_at IS NULL
+		AND (NOT OLD.published OR OLD.deleted_at IS NOT NULL) THEN
+		kind := 'created';
+	END IF;
+	IF kind IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	INSERT INTO chirp_events (type, chirp_id, user_id)
+	VALUES (kind, NEW.id, NEW.user_id)
+	RETURNING id INTO event_id;
+	PERFORM pg_notify('chirp_events', event_id::text);
+	RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER chirps_record_event
+AFTER INSERT OR UPDATE OF published, deleted_at ON chirps
+FOR EACH ROW EXECUTE FUNCTION record_chirp_event();
+
+-- +goose Down
+DROP TRIGGER chirps_record_event ON chirps;
+DROP FUNCTION record_chirp_event();
+DROP TABLE chirp_events;
@@ -0,0 +1,61 @@
+-- +goose Up
+-- actor_keys holds the key pair each local user signs ActivityPub requests
+-- with. Keys are made the first time a user's actor is needed.
+CREATE TABLE actor_keys (
+	user_id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	public_key_pem TEXT NOT NULL,
+	private_key_pem TEXT NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+-- remote_actors caches the actors of other servers that have contacted us,
+-- keyed by their actor URI.
+CREATE TABLE remote_actors (
+	id TEXT PRIMARY KEY,
+	key_id TEXT NOT NULL UNIQUE,
+	public_key_pem TEXT NOT NULL,
+	inbox TEXT NOT NULL,
+	shared_inbox TEXT NOT NULL,
+	username TEXT NOT NULL,
+	fetched_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE remote_follows (
+	user_id UUID NOT NULL,
+	actor_id TEXT NOT NULL,
+	-- activity_id is the Follow activity, which the Accept refers to.
+	activity_id TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, actor_id),
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE,
+	FOREIGN KEY (actor_id) REFERENCES remote_actors(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX remote_follows_actor_id_idx ON remote_follows (actor_id);
+
+-- ap_deliveries is the queue of activities waiting to be posted to remote
+-- inboxes. A failed delivery is retried at next_attempt_at.
+CREATE TABLE ap_deliveries (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	inbox TEXT NOT NULL,
+	activity TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_error TEXT,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX ap_deliveries_next_attempt_at_idx ON ap_deliveries (next_attempt_at);
+
+-- +goose Down
+DROP TABLE ap_deliveries;
+DROP TABLE remote_follows;
+DROP TABLE remote_actors;
+DROP TABLE actor_keys;
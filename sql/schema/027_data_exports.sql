@@ -0,0 +1,43 @@
+-- +goose Up
+-- data_exports tracks archives of a user's data. An archive is built in the
+-- background, kept in media storage under storage_key until expires_at, and
+-- then deleted with its row.
+CREATE TABLE data_exports (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending'
+		CHECK (status IN ('pending', 'processing', 'ready', 'failed')),
+	storage_key TEXT,
+	size_bytes BIGINT,
+	expires_at TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+CREATE INDEX data_exports_user_id_created_at_idx ON data_exports (user_id, created_at DESC);
+CREATE INDEX data_exports_unfinished_idx ON data_exports (created_at)
+	WHERE status IN ('pending', 'processing');
+
+-- Users are told when their archive is ready.
+ALTER TABLE notifications
+ADD COLUMN export_id UUID REFERENCES data_exports(id) ON DELETE CASCADE;
+
+ALTER TABLE notifications
+DROP CONSTRAINT notifications_type_check,
+ADD CONSTRAINT notifications_type_check
+	CHECK (type IN ('mention', 'quote', 'rechirp', 'follow', 'export_ready'));
+
+-- +goose Down
+DELETE FROM notifications WHERE type = 'export_ready';
+
+ALTER TABLE notifications
+DROP CONSTRAINT notifications_type_check,
+ADD CONSTRAINT notifications_type_check
+	CHECK (type IN ('mention', 'quote', 'rechirp', 'follow'));
+
+ALTER TABLE notifications
+DROP COLUMN export_id;
+
+DROP TABLE data_exports;
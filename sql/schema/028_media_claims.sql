@@ -0,0 +1,9 @@
+-- +goose Up
+-- claimed_at is when a worker took an upload for processing. Another worker
+-- only takes over a processing upload once the claim has gone stale.
+ALTER TABLE media
+ADD COLUMN claimed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE media
+DROP COLUMN claimed_at;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- follower_counts keeps each user's follower count up to date, so reading a
+-- home timeline can tell which followed authors are too popular to fan out
+-- to without counting their followers every time.
+CREATE TABLE follower_counts (
+	user_id UUID PRIMARY KEY,
+	followers BIGINT NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id)
+		ON DELETE CASCADE
+);
+
+INSERT INTO follower_counts (user_id, followers)
+SELECT followee_id, COUNT(*) FROM follows
+GROUP BY followee_id;
+
+-- +goose StatementBegin
+CREATE FUNCTION count_followers()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		INSERT INTO follower_counts (user_id, followers)
+		VALUES (NEW.followee_id, 1)
+		ON CONFLICT (user_id) DO UPDATE
+		SET followers = follower_counts.followers + 1;
+	ELSE
+		UPDATE follower_counts
+		SET followers = followers - 1
+		WHERE user_id = OLD.followee_id;
+	END IF;
+	RETURN NULL;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER follows_count_followers
+AFTER INSERT OR DELETE ON follows
+FOR EACH ROW EXECUTE FUNCTION count_followers();
+
+-- +goose Down
+DROP TRIGGER follows_count_followers ON follows;
+DROP FUNCTION count_followers();
+DROP TABLE follower_counts;
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/odilmode/http/internal/database"
+)
+
+const (
+	chirpEventDeleted = "deleted"
+	// chirpEventsChannel is the Postgres channel new chirp events are
+	// announced on.
+	chirpEventsChannel = "chirp_events"
+	// streamCatchUpInterval is how often the stream checks for events when
+	// no notification arrives, in case one was lost while reconnecting.
+	streamCatchUpInterval = 30 * time.Second
+	// streamHeartbeatInterval is how often an idle connection gets a comment
+	// so proxies don't close it.
+	streamHeartbeatInterval = 15 * time.Second
+	// streamEventRetention is how long events are kept for clients resuming
+	// with Last-Event-ID.
+	streamEventRetention = 24 * time.Hour
+	// streamGapTimeout is how long a skipped event ID is waited for. IDs are
+	// taken when a transaction inserts the event, so a later ID can commit
+	// first; one that never appears belonged to a rolled back transaction.
+	streamGapTimeout = time.Minute
+	// streamMaxGap is the largest run of skipped IDs that is waited for.
+	streamMaxGap           = 1000
+	streamBatchSize        = 100
+	streamSubscriberBuffer = 64
+	streamRetryMillis      = 3000
+)
+
+// ChirpDeletedEvent is the data of a chirp_deleted stream event
+type ChirpDeletedEvent struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// handleStreamChirps godoc
+// @Summary      Stream new chirps
+// @Description  A Server-Sent Events stream of chirps as they are published and deleted. Each chirp_created event carries the chirp, and each chirp_deleted event carries its ID. Event IDs increase, and a client that reconnects with Last-Event-ID is sent the events it missed from the last 24 hours. A comment is sent every 15 seconds while the stream is idle. Without author_id the stream carries every listed chirp the caller can see, leaving out muted authors; with author_id it carries that user's chirps, including unlisted ones. Authentication is optional; followers-only and mentioned-only chirps are only sent to users who can see them, and a chirp_deleted event is only sent to users who could have been sent the chirp. An authenticated stream ends when the caller's account is deleted or suspended.
+// @Tags         chirps
+// @Produce      text/event-stream
+// @Param        Authorization  header  string  false  "Bearer JWT token"
+// @Param        Last-Event-ID  header  int     false  "ID of the last event received, to resume the stream"
+// @Param        author_id      query   string  false  "Only stream this user's chirps"
+// @Success      200  {string}  string  "Stream of chirp_created and chirp_deleted events"
+// @Failure      400  {object}  ErrorResponse "Invalid author_id or Last-Event-ID"
+// @Failure      401  {object}  ErrorResponse "Invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/stream/chirps [get]
+func (cfg *apiConfig) handleStreamChirps(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	viewerID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
+	var authorID uuid.NullUUID
+	if s := r.URL.Query().Get("author_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid author_id")
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	var lastEventID int64
+	if s := r.Header.Get("Last-Event-ID"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+		lastEventID = id
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "Streaming unsupported")
+		return
+	}
+
+	// Subscribe before replaying so nothing published during the replay is
+	// missed; events the replay already sent are skipped below.
+	sub := cfg.stream.subscribe()
+	defer cfg.stream.unsubscribe(sub)
+	// An authenticated stream ends when the viewer's account is deleted or
+	// suspended; reconnecting then gets a 401.
+	var sessionEnded <-chan realtimeEvent
+	if viewerID != uuid.Nil {
+		session := cfg.realtime.subscribe()
+		defer cfg.realtime.unsubscribe(session)
+		cfg.realtime.watch(session, sessionKey(viewerID))
+		sessionEnded = session.events
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	flusher.Flush()
+
+	replayedTo := int64(0)
+	if lastEventID > 0 {
+		replayedTo = lastEventID
+		for {
+			events, err := cfg.dbQueries.GetChirpEventsAfter(ctx, database.GetChirpEventsAfterParams{
+				AfterID:   replayedTo,
+				PageLimit: streamBatchSize,
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("GetChirpEventsAfter error: %v", err)
+				}
+				return
+			}
+			for _, e := range events {
+				if err := cfg.writeStreamEvent(ctx, w, viewerID, authorID, &streamEvent{ChirpEvent: e}); err != nil {
+					return
+				}
+				replayedTo = e.ID
+			}
+			flusher.Flush()
+			if len(events) < streamBatchSize {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.events:
+			if !ok {
+				// The client fell too far behind and was dropped; it
+				// resumes from its last event when it reconnects.
+				return
+			}
+			if e.ID <= replayedTo {
+				continue
+			}
+			if err := cfg.writeStreamEvent(ctx, w, viewerID, authorID, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-sessionEnded:
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeStreamEvent writes e to the stream if the viewer should see it. Only
+// write errors are returned; a chirp that fails to load is logged and
+// skipped.
+func (cfg *apiConfig) writeStreamEvent(ctx context.Context, w io.Writer, viewerID uuid.UUID, authorID uuid.NullUUID, e *streamEvent) error {
+	if authorID.Valid && e.UserID != authorID.UUID {
+		return nil
+	}
+	if e.Type == chirpEventDeleted {
+		visible, err := cfg.streamDeletionVisible(ctx, viewerID, !authorID.Valid, e)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("checking deleted chirp %s: %v", e.ChirpID, err)
+			}
+			return nil
+		}
+		if !visible {
+			return nil
+		}
+		return writeStreamFrame(w, e.ID, "chirp_deleted", ChirpDeletedEvent{ID: e.ChirpID})
+	}
+	chirp, err := cfg.streamChirp(ctx, viewerID, !authorID.Valid, e)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("rendering streamed chirp %s: %v", e.ChirpID, err)
+		}
+		return nil
+	}
+	if chirp == nil {
+		return nil
+	}
+	return writeStreamFrame(w, e.ID, "chirp_created", chirp)
+}
+
+// streamChirp returns the chirp e created as viewerID sees it, or nil if
+// they can't see it now. listedOnly leaves out unlisted chirps by others
+// and chirps by muted authors. Anonymous viewers all see the same thing, so
+// their chirp is rendered once per event.
+func (cfg *apiConfig) streamChirp(ctx context.Context, viewerID uuid.UUID, listedOnly bool, e *streamEvent) (*Chirp, error) {
+	if viewerID != uuid.Nil {
+		return cfg.renderStreamChirp(ctx, viewerID, listedOnly, e.ChirpID)
+	}
+	e.once.Do(func() {
+		// Other subscribers share the result, so it mustn't fail because
+		// this one disconnected.
+		e.anonymous, e.anonymousErr = cfg.renderStreamChirp(context.WithoutCancel(ctx), uuid.Nil, false, e.ChirpID)
+	})
+	if e.anonymousErr != nil {
+		return nil, e.anonymousErr
+	}
+	if e.anonymous == nil || (listedOnly && e.anonymous.Visibility == visibilityUnlisted) {
+		return nil, nil
+	}
+	return e.anonymous, nil
+}
+
+// streamDeletionVisible reports whether viewerID could have been sent the
+// chirp e deleted, so deletions don't reveal chirps a viewer never saw. The
+// check runs against the soft-deleted row with the same rules as
+// streamChirp.
+func (cfg *apiConfig) streamDeletionVisible(ctx context.Context, viewerID uuid.UUID, listedOnly bool, e *streamEvent) (bool, error) {
+	if viewerID != uuid.Nil {
+		_, err := cfg.dbQueries.GetDeletedStreamChirpVisibility(ctx, database.GetDeletedStreamChirpVisibilityParams{
+			ID:         e.ChirpID,
+			ViewerID:   viewerID,
+			ListedOnly: listedOnly,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("GetDeletedStreamChirpVisibility: %w", err)
+		}
+		return true, nil
+	}
+	e.once.Do(func() {
+		visibility, err := cfg.dbQueries.GetDeletedStreamChirpVisibility(context.WithoutCancel(ctx), database.GetDeletedStreamChirpVisibilityParams{
+			ID:       e.ChirpID,
+			ViewerID: uuid.Nil,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		if err != nil {
+			e.anonymousErr = fmt.Errorf("GetDeletedStreamChirpVisibility: %w", err)
+			return
+		}
+		e.anonymousVisibility = visibility
+	})
+	if e.anonymousErr != nil {
+		return false, e.anonymousErr
+	}
+	if e.anonymousVisibility == "" || (listedOnly && e.anonymousVisibility == visibilityUnlisted) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (cfg *apiConfig) renderStreamChirp(ctx context.Context, viewerID uuid.UUID, listedOnly bool, chirpID uuid.UUID) (*Chirp, error) {
+	row, err := cfg.dbQueries.GetStreamChirp(ctx, database.GetStreamChirpParams{
+		ID:         chirpID,
+		ViewerID:   viewerID,
+		ListedOnly: listedOnly,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetStreamChirp: %w", err)
+	}
+	chirp, err := cfg.chirpResponse(ctx, viewerID, row)
+	if err != nil {
+		return nil, err
+	}
+	return &chirp, nil
+}
+
+func writeStreamFrame(w io.Writer, id int64, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload)
+	return err
+}
+
+// streamEvent is a chirp event as delivered to subscribers.
+type streamEvent struct {
+	database.ChirpEvent
+	once      sync.Once
+	anonymous *Chirp
+	// anonymousVisibility is the visibility of a deleted chirp anonymous
+	// viewers could see, or empty if they couldn't.
+	anonymousVisibility string
+	anonymousErr        error
+}
+
+type chirpSubscriber struct {
+	events chan *streamEvent
+}
+
+// chirpStream delivers chirp events to the streams open on this instance.
+// A database trigger records every chirp published or deleted and notifies
+// chirpEventsChannel, so events reach every instance whichever one handled
+// the change. Events are read from the table in ID order; the notification
+// only says there is something to read.
+type chirpStream struct {
+	queries *database.Queries
+	dbURL   string
+
+	mu          sync.Mutex
+	subscribers map[*chirpSubscriber]struct{}
+
+	// The fields below are only used by the run goroutine.
+	started bool
+	// lastID is the highest event ID delivered.
+	lastID int64
+	// gaps are skipped IDs below lastID that may still commit, with when
+	// they were skipped.
+	gaps map[int64]time.Time
+}
+
+func newChirpStream(queries *database.Queries, dbURL string) *chirpStream {
+	return &chirpStream{
+		queries:     queries,
+		dbURL:       dbURL,
+		subscribers: make(map[*chirpSubscriber]struct{}),
+		gaps:        make(map[int64]time.Time),
+	}
+}
+
+// start listens for chirp events until ctx is cancelled.
+func (s *chirpStream) start(ctx context.Context) {
+	listener := pq.NewListener(s.dbURL, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("chirp stream listener: %v", err)
+		}
+	})
+	go func() {
+		// Listen waits for a connection, retrying until one is made.
+		if err := listener.Listen(chirpEventsChannel); err != nil {
+			log.Printf("listening on %s: %v", chirpEventsChannel, err)
+		}
+	}()
+	go s.run(ctx, listener)
+}
+
+func (s *chirpStream) run(ctx context.Context, listener *pq.Listener) {
+	defer listener.Close()
+	catchUp := time.NewTicker(streamCatchUpInterval)
+	defer catchUp.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+	s.catchUp(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.Notify:
+			// A nil notification means the listener reconnected, which
+			// may have lost some; catching up covers both.
+			s.catchUp(ctx)
+		case <-catchUp.C:
+			listener.Ping()
+			s.catchUp(ctx)
+		case <-cleanup.C:
+			if err := s.queries.DeleteOldChirpEvents(ctx, streamEventRetention.Seconds()); err != nil && ctx.Err() == nil {
+				log.Printf("DeleteOldChirpEvents error: %v", err)
+			}
+		}
+	}
+}
+
+// catchUp delivers the events recorded since the last one delivered, and
+// any skipped earlier that have since committed.
+func (s *chirpStream) catchUp(ctx context.Context) {
+	if !s.started {
+		// Streams start with events recorded after the server did; older
+		// ones are only sent to clients resuming with Last-Event-ID.
+		id, err := s.queries.GetLatestChirpEventID(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("GetLatestChirpEventID error: %v", err)
+			}
+			return
+		}
+		s.lastID = id
+		s.started = true
+	}
+	for {
+		now := time.Now()
+		var missing []int64
+		for id, skipped := range s.gaps {
+			if now.Sub(skipped) > streamGapTimeout {
+				delete(s.gaps, id)
+				continue
+			}
+			missing = append(missing, id)
+		}
+		events, err := s.queries.GetChirpEventsAfter(ctx, database.GetChirpEventsAfterParams{
+			AfterID:    s.lastID,
+			MissingIds: missing,
+			PageLimit:  streamBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("GetChirpEventsAfter error: %v", err)
+			}
+			return
+		}
+		for _, e := range events {
+			if e.ID > s.lastID {
+				if e.ID-s.lastID <= streamMaxGap {
+					for id := s.lastID + 1; id < e.ID; id++ {
+						s.gaps[id] = now
+					}
+				}
+				s.lastID = e.ID
+			} else {
+				delete(s.gaps, e.ID)
+			}
+			s.broadcast(&streamEvent{ChirpEvent: e})
+		}
+		if len(events) < streamBatchSize {
+			return
+		}
+	}
+}
+
+// broadcast hands e to every subscriber. A subscriber whose buffer is full
+// is dropped rather than holding up the rest.
+func (s *chirpStream) broadcast(e *streamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- e:
+		default:
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (s *chirpStream) subscribe() *chirpSubscriber {
+	sub := &chirpSubscriber{events: make(chan *streamEvent, streamSubscriberBuffer)}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	return sub
+}
+
+func (s *chirpStream) unsubscribe(sub *chirpSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+)
+
+const (
+	// timelineMaxEntries bounds how many chirps a materialized timeline keeps.
+	// Older pages fall back to fan-out-on-read.
+	timelineMaxEntries = 800
+	// fanoutFollowerLimit is the follower count above which an author's
+	// chirps are not written into follower timelines. Their chirps are merged
+	// in when the timeline is read instead.
+	fanoutFollowerLimit = 10000
+	fanoutQueueSize     = 1024
+	fanoutJobTimeout    = 30 * time.Second
+	// timelineTrimInterval is how often timelines that grew past
+	// timelineMaxEntries are trimmed. Fan-out doesn't trim, so that posting
+	// costs the same however many followers the author has.
+	timelineTrimInterval  = 10 * time.Minute
+	timelineTrimBatchSize = 100
+)
+
+type fanoutJobKind int
+
+const (
+	fanoutChirpCreated fanoutJobKind = iota
+	fanoutRebuild
+)
+
+type fanoutJob struct {
+	kind  fanoutJobKind
+	chirp database.Chirp
+	// userID is the owner of the timeline to rebuild
+	userID uuid.UUID
+}
+
+// timelineFanout keeps materialized home timelines up to date. Handlers
+// enqueue work and return immediately; a fixed pool of workers applies it.
+type timelineFanout struct {
+	db      *sql.DB
+	queries *database.Queries
+	jobs    chan fanoutJob
+}
+
+func newTimelineFanout(db *sql.DB, queries *database.Queries) *timelineFanout {
+	return &timelineFanout{
+		db:      db,
+		queries: queries,
+		jobs:    make(chan fanoutJob, fanoutQueueSize),
+	}
+}
+
+// start launches the workers and the trimmer. They exit when ctx is
+// cancelled.
+func (f *timelineFanout) start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-f.jobs:
+					f.run(ctx, job)
+				}
+			}
+		}()
+	}
+	go func() {
+		ticker := time.NewTicker(timelineTrimInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.trim(ctx)
+			}
+		}
+	}()
+}
+
+// chirpCreated writes a new chirp into the timelines of its author's
+// followers.
+func (f *timelineFanout) chirpCreated(ctx context.Context, chirp database.Chirp) {
+	f.enqueue(ctx, fanoutJob{kind: fanoutChirpCreated, chirp: chirp})
+}
+
+// rebuild recomputes a user's timeline, e.g. after they follow or unfollow
+// someone.
+func (f *timelineFanout) rebuild(ctx context.Context, userID uuid.UUID) {
+	f.enqueue(ctx, fanoutJob{kind: fanoutRebuild, userID: userID})
+}
+
+// rebuildTimelineIfBuilt rebuilds the user's timeline if it has been
+// materialized. Users without one keep using fan-out-on-read until their
+// next timeline request builds it.
+func (cfg *apiConfig) rebuildTimelineIfBuilt(ctx context.Context, userID uuid.UUID) {
+	if _, err := cfg.dbQueries.GetTimeline(ctx, userID); err != nil {
+		return
+	}
+	cfg.timelines.rebuild(ctx, userID)
+}
+
+// enqueue hands a job to the workers. When the queue is full the affected
+// timelines are invalidated instead, so readers fall back to fan-out-on-read
+// rather than seeing a timeline that silently misses chirps.
+func (f *timelineFanout) enqueue(ctx context.Context, job fanoutJob) {
+	select {
+	case f.jobs <- job:
+		return
+	default:
+	}
+
+	log.Printf("timeline fan-out queue full, invalidating timelines")
+	var err error
+	switch job.kind {
+	case fanoutChirpCreated:
+		err = f.queries.InvalidateFollowerTimelines(ctx, job.chirp.UserID)
+	case fanoutRebuild:
+		err = f.queries.InvalidateTimeline(ctx, job.userID)
+	}
+	if err != nil {
+		log.Printf("Couldn't invalidate timelines: %v", err)
+	}
+}
+
+func (f *timelineFanout) run(ctx context.Context, job fanoutJob) {
+	ctx, cancel := context.WithTimeout(ctx, fanoutJobTimeout)
+	defer cancel()
+
+	var err error
+	switch job.kind {
+	case fanoutChirpCreated:
+		err = f.fanOut(ctx, job.chirp)
+	case fanoutRebuild:
+		err = f.rebuildTimeline(ctx, job.userID)
+	}
+	if err != nil {
+		log.Printf("timeline fan-out error: %v", err)
+	}
+}
+
+func (f *timelineFanout) fanOut(ctx context.Context, chirp database.Chirp) error {
+	// The same count decides which authors the timeline merges in on read.
+	followers, err := f.queries.GetFollowerCount(ctx, chirp.UserID)
+	if err != nil {
+		return fmt.Errorf("counting followers of %s: %w", chirp.UserID, err)
+	}
+	if err := f.queries.FanOutChirp(ctx, database.FanOutChirpParams{
+		ChirpID:          chirp.ID,
+		AuthorID:         chirp.UserID,
+		CreatedAt:        chirp.CreatedAt,
+		IncludeFollowers: followers <= fanoutFollowerLimit,
+	}); err != nil {
+		return fmt.Errorf("fanning out chirp %s: %w", chirp.ID, err)
+	}
+	return nil
+}
+
+// rebuildTimeline replaces a user's timeline entries with their newest
+// chirps. Marking the timeline built locks its row, and fan-out takes a
+// share lock on the rows it writes to, so a rebuild and a fan-out into the
+// same timeline run one after the other and neither loses the other's
+// entries. Concurrent rebuilds of one timeline queue on the same lock.
+func (f *timelineFanout) rebuildTimeline(ctx context.Context, userID uuid.UUID) error {
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := f.queries.WithTx(tx)
+
+	_, err = qtx.GetTimeline(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("loading timeline of %s: %w", userID, err)
+	}
+	built := err == nil
+	if err := qtx.MarkTimelineBuilt(ctx, userID); err != nil {
+		return fmt.Errorf("marking timeline of %s: %w", userID, err)
+	}
+	if err := qtx.ClearTimelineEntries(ctx, userID); err != nil {
+		return fmt.Errorf("clearing timeline of %s: %w", userID, err)
+	}
+	if err := qtx.FillTimeline(ctx, database.FillTimelineParams{
+		UserID:     userID,
+		MaxEntries: timelineMaxEntries,
+	}); err != nil {
+		return fmt.Errorf("filling timeline of %s: %w", userID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if built {
+		return nil
+	}
+	// Fan-outs can't see or lock a timeline before it is first committed,
+	// so chirps published while it was being filled are picked up now.
+	if err := f.queries.FillTimeline(ctx, database.FillTimelineParams{
+		UserID:     userID,
+		MaxEntries: timelineMaxEntries,
+	}); err != nil {
+		return fmt.Errorf("filling timeline of %s: %w", userID, err)
+	}
+	return nil
+}
+
+// trim cuts timelines that have grown past timelineMaxEntries back to their
+// newest entries.
+func (f *timelineFanout) trim(ctx context.Context) {
+	for {
+		userIDs, err := f.queries.GetOverfullTimelines(ctx, database.GetOverfullTimelinesParams{
+			MaxEntries: timelineMaxEntries,
+			BatchSize:  timelineTrimBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("GetOverfullTimelines error: %v", err)
+			}
+			return
+		}
+		for _, userID := range userIDs {
+			if err := f.queries.TrimTimeline(ctx, database.TrimTimelineParams{
+				UserID:     userID,
+				MaxEntries: timelineMaxEntries,
+			}); err != nil {
+				log.Printf("TrimTimeline error: %v", err)
+				return
+			}
+		}
+		if len(userIDs) < timelineTrimBatchSize {
+			return
+		}
+	}
+}
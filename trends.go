@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/database"
+	"github.com/odilmode/http/internal/trends"
+)
+
+const (
+	// trendsInterval is how often trends are recomputed.
+	trendsInterval = 5 * time.Minute
+	// trendsBaseline is how far back before a window a tag's usual rate of
+	// use is measured.
+	trendsBaseline = 7 * 24 * time.Hour
+	// trendMinAuthors is the fewest distinct authors a tag needs to trend.
+	trendMinAuthors = 3
+	// trendSnapshotSize is how many tags each snapshot keeps.
+	trendSnapshotSize = 50
+	// trendSnapshotRetention is how long old snapshots are kept.
+	trendSnapshotRetention = 7 * 24 * time.Hour
+	trendSamplesPerTag     = 3
+	defaultTrendLimit      = 10
+)
+
+// Trend is a trending hashtag
+type Trend struct {
+	Rank int    `json:"rank"`
+	Tag  string `json:"tag"`
+	// Score is how far recent use exceeds the tag's usual rate
+	Score float64 `json:"score"`
+	// Uses is the number of chirps that used the tag in the window
+	Uses    int `json:"uses"`
+	Authors int `json:"authors"`
+	// SampleChirps are the newest public chirps using the tag
+	SampleChirps []Chirp `json:"sample_chirps"`
+}
+
+// TrendList is the latest trends for a window
+type TrendList struct {
+	Window string `json:"window" enums:"1h,24h"`
+	// ComputedAt is when the trends were computed; omitted until the first
+	// run
+	ComputedAt *time.Time `json:"computed_at,omitempty"`
+	Trends     []Trend    `json:"trends"`
+}
+
+// handleGetTrends godoc
+// @Summary      Trending hashtags
+// @Description  Hashtags being used faster than usual over a sliding window, best first, each with a few sample chirps. Trends are recomputed every 5 minutes. Authentication is optional; sample chirps honour the caller's blocks and mutes.
+// @Tags         hashtags
+// @Produce      json
+// @Param        Authorization  header  string  false  "Bearer JWT token"
+// @Param        window         query   string  false  "Window: 1h (default) or 24h"
+// @Param        limit          query   int     false  "Number of trends (1-50, default 10)"
+// @Success      200  {object}  TrendList
+// @Failure      400  {object}  ErrorResponse "Invalid window or limit"
+// @Failure      401  {object}  ErrorResponse "Invalid token"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /api/trends [get]
+func (cfg *apiConfig) handleGetTrends(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	viewerID, ok := cfg.viewerID(w, r)
+	if !ok {
+		return
+	}
+	windowName := r.URL.Query().Get("window")
+	if windowName == "" {
+		windowName = trends.Windows[0].Name
+	}
+	if _, ok := trends.Lookup(windowName); !ok {
+		respondWithError(w, http.StatusBadRequest, "window must be 1h or 24h")
+		return
+	}
+	limit := defaultTrendLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > trendSnapshotSize {
+			respondWithError(w, http.StatusBadRequest, "limit must be between 1 and 50")
+			return
+		}
+		limit = n
+	}
+
+	list := TrendList{Window: windowName, Trends: []Trend{}}
+	snapshot, err := cfg.dbQueries.GetLatestTrendSnapshot(ctx, windowName)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithJSON(w, http.StatusOK, list)
+		return
+	}
+	if err != nil {
+		log.Printf("GetLatestTrendSnapshot error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch trends")
+		return
+	}
+	list.ComputedAt = &snapshot.ComputedAt
+	entries, err := cfg.dbQueries.GetTrendEntries(ctx, database.GetTrendEntriesParams{
+		SnapshotID: snapshot.ID,
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		log.Printf("GetTrendEntries error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch trends")
+		return
+	}
+	if len(entries) == 0 {
+		respondWithJSON(w, http.StatusOK, list)
+		return
+	}
+
+	hashtagIDs := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		hashtagIDs[i] = e.HashtagID
+	}
+	samples, err := cfg.dbQueries.GetTrendSampleChirps(ctx, database.GetTrendSampleChirpsParams{
+		ViewerID:   viewerID,
+		PerTag:     trendSamplesPerTag,
+		HashtagIds: hashtagIDs,
+	})
+	if err != nil {
+		log.Printf("GetTrendSampleChirps error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch trends")
+		return
+	}
+	// Sample chirps for every tag are rendered in one batch.
+	chirps := make([]database.Chirp, len(samples))
+	for i, s := range samples {
+		chirps[i] = s.Chirp
+	}
+	rendered, err := cfg.chirpResponses(ctx, viewerID, chirps)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch trends")
+		return
+	}
+	byHashtag := make(map[uuid.UUID][]Chirp, len(entries))
+	for i, s := range samples {
+		byHashtag[s.HashtagID] = append(byHashtag[s.HashtagID], rendered[i])
+	}
+
+	for _, e := range entries {
+		sample := byHashtag[e.HashtagID]
+		if sample == nil {
+			sample = []Chirp{}
+		}
+		list.Trends = append(list.Trends, Trend{
+			Rank:         int(e.Rank),
+			Tag:          e.Tag,
+			Score:        e.Score,
+			Uses:         int(e.Uses),
+			Authors:      int(e.Authors),
+			SampleChirps: sample,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// trendsJob periodically ranks hashtags for every window and stores the
+// result as a snapshot. Only public chirps count, and hidden chirps and
+// suspended authors are left out. Each run writes new snapshots, so
+// several instances running the job only produce extra snapshots.
+type trendsJob struct {
+	db      *sql.DB
+	queries *database.Queries
+}
+
+func newTrendsJob(db *sql.DB, queries *database.Queries) *trendsJob {
+	return &trendsJob{db: db, queries: queries}
+}
+
+// start computes trends until ctx is cancelled.
+func (j *trendsJob) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(trendsInterval)
+		defer ticker.Stop()
+		for {
+			j.run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *trendsJob) run(ctx context.Context) {
+	for _, window := range trends.Windows {
+		if err := j.computeWindow(ctx, window); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("computing %s trends: %v", window.Name, err)
+			}
+			return
+		}
+	}
+	if err := j.queries.DeleteOldTrendSnapshots(ctx, trendSnapshotRetention.Seconds()); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("DeleteOldTrendSnapshots error: %v", err)
+		}
+	}
+}
+
+func (j *trendsJob) computeWindow(ctx context.Context, window trends.Window) error {
+	rows, err := j.queries.GetHashtagUsage(ctx, window.Length.Seconds())
+	if err != nil {
+		return fmt.Errorf("GetHashtagUsage: %w", err)
+	}
+	hashtagIDs := make([]uuid.UUID, len(rows))
+	tagIDs := make(map[string]uuid.UUID, len(rows))
+	for i, row := range rows {
+		hashtagIDs[i] = row.ID
+		tagIDs[row.Tag] = row.ID
+	}
+	baselines, err := j.queries.GetHashtagBaselineUses(ctx, database.GetHashtagBaselineUsesParams{
+		HashtagIds:      hashtagIDs,
+		BaselineSeconds: (trendsBaseline + window.Length).Seconds(),
+		WindowSeconds:   window.Length.Seconds(),
+	})
+	if err != nil {
+		return fmt.Errorf("GetHashtagBaselineUses: %w", err)
+	}
+	baselineUses := make(map[uuid.UUID]int, len(baselines))
+	for _, b := range baselines {
+		baselineUses[b.HashtagID] = int(b.Uses)
+	}
+
+	usage := make([]trends.Usage, len(rows))
+	for i, row := range rows {
+		ages := make([]time.Duration, len(row.Ages))
+		for k, seconds := range row.Ages {
+			ages[k] = time.Duration(seconds * float64(time.Second))
+		}
+		usage[i] = trends.Usage{
+			Tag:          row.Tag,
+			Ages:         ages,
+			Authors:      int(row.Authors),
+			BaselineUses: baselineUses[row.ID],
+		}
+	}
+	ranker := trends.Ranker{Window: window, Baseline: trendsBaseline, MinAuthors: trendMinAuthors}
+	ranked := ranker.Rank(usage, trendSnapshotSize)
+
+	tx, err := j.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := j.queries.WithTx(tx)
+	snapshotID := uuid.New()
+	if err := qtx.CreateTrendSnapshot(ctx, database.CreateTrendSnapshotParams{
+		ID:         snapshotID,
+		WindowName: window.Name,
+	}); err != nil {
+		return fmt.Errorf("CreateTrendSnapshot: %w", err)
+	}
+	for i, t := range ranked {
+		if err := qtx.CreateTrendEntry(ctx, database.CreateTrendEntryParams{
+			SnapshotID: snapshotID,
+			Rank:       int32(i + 1),
+			HashtagID:  tagIDs[t.Tag],
+			Score:      t.Score,
+			Uses:       int32(t.Uses),
+			Authors:    int32(t.Authors),
+		}); err != nil {
+			return fmt.Errorf("CreateTrendEntry: %w", err)
+		}
+	}
+	return tx.Commit()
+}
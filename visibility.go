@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+)
+
+// Chirp visibility levels. Unlisted chirps can be seen by anyone with the link
+// but are left out of GET /api/chirps and hashtag listings.
+const (
+	visibilityPublic    = "public"
+	visibilityUnlisted  = "unlisted"
+	visibilityFollowers = "followers"
+	visibilityMentioned = "mentioned"
+)
+
+// normalizeVisibility validates a requested visibility, defaulting to public.
+func normalizeVisibility(v string) (string, error) {
+	switch v {
+	case "":
+		return visibilityPublic, nil
+	case visibilityPublic, visibilityUnlisted, visibilityFollowers, visibilityMentioned:
+		return v, nil
+	}
+	return "", errors.New("Visibility must be public, unlisted, followers or mentioned")
+}
+
+// viewerID returns the user making r on endpoints where authentication is
+// optional, or uuid.Nil for an anonymous request. A request with an
+// Authorization header that doesn't validate gets a 401 rather than being
+// treated as anonymous, so a client with an expired token finds out instead
+// of silently seeing less. ok is false when the error response was written.
+func (cfg *apiConfig) viewerID(w http.ResponseWriter, r *http.Request) (userID uuid.UUID, ok bool) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.Nil, true
+	}
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+		return uuid.Nil, false
+	}
+	userID, err = cfg.validateJWT(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
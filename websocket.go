@@ -0,0 +1,522 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/google/uuid"
+	"github.com/odilmode/http/internal/auth"
+	"github.com/odilmode/http/internal/database"
+)
+
+const (
+	wsReadLimit    = 4096
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 30 * time.Second
+	// wsExpiryWarning is how long before the access token expires the client
+	// is asked for a new one.
+	wsExpiryWarning = time.Minute
+	// wsTypingInterval is the shortest gap between typing indicators a
+	// connection sends for one conversation; more frequent ones are dropped.
+	wsTypingInterval = 3 * time.Second
+	wsMaxTopics      = 100
+	// wsStatusTokenExpired closes connections whose access token expired
+	// without being replaced.
+	wsStatusTokenExpired websocket.StatusCode = 4001
+	// wsStatusSessionEnded closes connections of users whose account was
+	// deleted or suspended.
+	wsStatusSessionEnded websocket.StatusCode = 4003
+)
+
+const (
+	wsTopicTimeline           = "timeline"
+	wsTopicNotifications      = "notifications"
+	wsTopicConversationPrefix = "conversation:"
+)
+
+// errWSClosed ends a connection that has already been closed with a
+// status of its own.
+var errWSClosed = errors.New("websocket closed")
+
+// wsClientMessage is a message from a WebSocket client.
+type wsClientMessage struct {
+	// Type is subscribe, unsubscribe, typing or auth.
+	Type  string `json:"type"`
+	Topic string `json:"topic"`
+	// ConversationID is the conversation a typing indicator is for.
+	ConversationID uuid.UUID `json:"conversation_id"`
+	// Token is a new access token.
+	Token string `json:"token"`
+}
+
+// wsServerMessage is a message to a WebSocket client.
+type wsServerMessage struct {
+	// Type is authenticated, token_expiring, subscribed, unsubscribed,
+	// event or error.
+	Type  string `json:"type"`
+	Topic string `json:"topic,omitempty"`
+	Event string `json:"event,omitempty"`
+	Data  any    `json:"data,omitempty"`
+	// Request is the type of the client message an error is about.
+	Request   string     `json:"request,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// TypingEvent is the data of a typing event
+type TypingEvent struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	UserID         uuid.UUID `json:"user_id"`
+}
+
+// handleWebSocket godoc
+// @Summary      Real-time WebSocket
+// @Description  Upgrades to a WebSocket carrying JSON messages. Clients send {"type":"subscribe","topic":...} and {"type":"unsubscribe","topic":...} for the topics timeline (chirps created in and deleted from the home timeline), notifications, and conversation:{conversationID} (new messages and typing indicators in a conversation the user takes part in); {"type":"typing","conversation_id":...} to show they are typing; and {"type":"auth","token":...} with a new access token. The server sends {"type":"event","topic":...,"event":...,"data":...}, replies subscribed, unsubscribed, authenticated or error, and token_expiring a minute before the access token expires. A connection whose token expires is closed with status 4001, one whose account is deleted or suspended with status 4003, and one that falls too far behind is closed with status 1013; clients reconnect and catch up through the REST API.
+// @Tags         realtime
+// @Param        Authorization  header  string  false  "Bearer JWT token"
+// @Param        access_token   query   string  false  "Access token, for clients that can't set headers"
+// @Success      101  "Switching Protocols"
+// @Failure      401  {object}  ErrorResponse "Missing or invalid token"
+// @Router       /api/ws [get]
+func (cfg *apiConfig) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	accessToken := r.URL.Query().Get("access_token")
+	if accessToken == "" {
+		token, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Missing or Invalid Authorization header")
+			return
+		}
+		accessToken = token
+	}
+	userID, expiresAt, err := cfg.validateJWTExpiry(r.Context(), accessToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT")
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept has already written the response.
+		log.Printf("websocket.Accept error: %v", err)
+		return
+	}
+	conn.SetReadLimit(wsReadLimit)
+	c := &wsClient{
+		cfg:        cfg,
+		conn:       conn,
+		userID:     userID,
+		realtime:   cfg.realtime.subscribe(),
+		topics:     make(map[string]struct{}),
+		lastTyping: make(map[uuid.UUID]time.Time),
+	}
+	defer c.close()
+	cfg.realtime.watch(c.realtime, sessionKey(userID))
+	c.setExpiry(expiresAt)
+	c.run(r.Context())
+}
+
+// wsClient is one WebSocket connection. Everything but reading and pinging
+// happens on the goroutine running run, so its fields need no locking.
+type wsClient struct {
+	cfg    *apiConfig
+	conn   *websocket.Conn
+	userID uuid.UUID
+
+	expiresAt time.Time
+	warning   *time.Timer
+	expiry    *time.Timer
+
+	realtime *realtimeSubscriber
+	// chirps is set while the client is subscribed to the timeline.
+	chirps     *chirpSubscriber
+	topics     map[string]struct{}
+	lastTyping map[uuid.UUID]time.Time
+}
+
+func (c *wsClient) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	incoming := make(chan []byte)
+	go func() {
+		defer close(incoming)
+		for {
+			_, data, err := c.conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case incoming <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pingCtx, cancelPing := context.WithTimeout(ctx, wsWriteTimeout)
+				err := c.conn.Ping(pingCtx)
+				cancelPing()
+				if err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	if err := c.sendAuthenticated(ctx); err != nil {
+		return
+	}
+	for {
+		var chirpEvents <-chan *streamEvent
+		if c.chirps != nil {
+			chirpEvents = c.chirps.events
+		}
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case data, ok := <-incoming:
+			if !ok {
+				return
+			}
+			err = c.handleMessage(ctx, data)
+		case e, ok := <-chirpEvents:
+			if !ok {
+				c.conn.Close(websocket.StatusTryAgainLater, "Too far behind")
+				return
+			}
+			err = c.sendTimelineEvent(ctx, e)
+		case e, ok := <-c.realtime.events:
+			if !ok {
+				c.conn.Close(websocket.StatusTryAgainLater, "Too far behind")
+				return
+			}
+			err = c.sendRealtimeEvent(ctx, e)
+		case <-timerC(c.warning):
+			err = c.send(ctx, wsServerMessage{Type: "token_expiring", ExpiresAt: &c.expiresAt})
+		case <-timerC(c.expiry):
+			c.conn.Close(wsStatusTokenExpired, "Token expired")
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *wsClient) close() {
+	if c.chirps != nil {
+		c.cfg.stream.unsubscribe(c.chirps)
+	}
+	c.cfg.realtime.unsubscribe(c.realtime)
+	if c.warning != nil {
+		c.warning.Stop()
+		c.expiry.Stop()
+	}
+	c.conn.CloseNow()
+}
+
+// setExpiry schedules the warning and close for a token expiring at
+// expiresAt, replacing any for the previous token. Tokens without an expiry
+// never close the connection.
+func (c *wsClient) setExpiry(expiresAt time.Time) {
+	if c.warning != nil {
+		c.warning.Stop()
+		c.expiry.Stop()
+		c.warning, c.expiry = nil, nil
+	}
+	c.expiresAt = expiresAt
+	if expiresAt.IsZero() {
+		return
+	}
+	c.warning = time.NewTimer(time.Until(expiresAt.Add(-wsExpiryWarning)))
+	c.expiry = time.NewTimer(time.Until(expiresAt))
+}
+
+// timerC returns t's channel, or nil, which never delivers, if there is no
+// timer.
+func timerC(t *time.Timer) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
+// handleMessage acts on a message from the client. Problems with the
+// message are reported to the client; only a failure to write, or a
+// reason to close the connection, is returned.
+func (c *wsClient) handleMessage(ctx context.Context, data []byte) error {
+	var msg wsClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return c.sendError(ctx, "", "Couldn't decode message")
+	}
+	switch msg.Type {
+	case "subscribe":
+		return c.subscribe(ctx, msg.Topic)
+	case "unsubscribe":
+		return c.unsubscribe(ctx, msg.Topic)
+	case "typing":
+		return c.typing(ctx, msg.ConversationID)
+	case "auth":
+		return c.reauthenticate(ctx, msg.Token)
+	default:
+		return c.sendError(ctx, msg.Type, "Unknown message type")
+	}
+}
+
+func (c *wsClient) subscribe(ctx context.Context, topic string) error {
+	if _, ok := c.topics[topic]; ok {
+		return c.send(ctx, wsServerMessage{Type: "subscribed", Topic: topic})
+	}
+	if len(c.topics) >= wsMaxTopics {
+		return c.sendError(ctx, "subscribe", "Too many subscriptions")
+	}
+	switch {
+	case topic == wsTopicTimeline:
+		c.chirps = c.cfg.stream.subscribe()
+	case topic == wsTopicNotifications:
+		c.cfg.realtime.watch(c.realtime, notificationsKey(c.userID))
+	case strings.HasPrefix(topic, wsTopicConversationPrefix):
+		conversationID, err := uuid.Parse(strings.TrimPrefix(topic, wsTopicConversationPrefix))
+		if err != nil {
+			return c.sendError(ctx, "subscribe", "Invalid conversation ID")
+		}
+		member, err := c.cfg.dbQueries.IsConversationParticipant(ctx, database.IsConversationParticipantParams{
+			ConversationID: conversationID,
+			UserID:         c.userID,
+		})
+		if err != nil {
+			log.Printf("IsConversationParticipant error: %v", err)
+			return c.sendError(ctx, "subscribe", "Couldn't subscribe")
+		}
+		if !member {
+			return c.sendError(ctx, "subscribe", "Conversation not found")
+		}
+		c.cfg.realtime.watch(c.realtime, conversationKey(conversationID))
+	default:
+		return c.sendError(ctx, "subscribe", "Unknown topic")
+	}
+	c.topics[topic] = struct{}{}
+	return c.send(ctx, wsServerMessage{Type: "subscribed", Topic: topic})
+}
+
+func (c *wsClient) unsubscribe(ctx context.Context, topic string) error {
+	if _, ok := c.topics[topic]; ok {
+		delete(c.topics, topic)
+		switch {
+		case topic == wsTopicTimeline:
+			c.cfg.stream.unsubscribe(c.chirps)
+			c.chirps = nil
+		case topic == wsTopicNotifications:
+			c.cfg.realtime.unwatch(c.realtime, notificationsKey(c.userID))
+		default:
+			conversationID := uuid.MustParse(strings.TrimPrefix(topic, wsTopicConversationPrefix))
+			c.cfg.realtime.unwatch(c.realtime, conversationKey(conversationID))
+		}
+	}
+	return c.send(ctx, wsServerMessage{Type: "unsubscribed", Topic: topic})
+}
+
+// typing tells the other participants of a conversation that the user is
+// typing, under the same rules as sending a message.
+func (c *wsClient) typing(ctx context.Context, conversationID uuid.UUID) error {
+	if last, ok := c.lastTyping[conversationID]; ok && time.Since(last) < wsTypingInterval {
+		return nil
+	}
+	member, err := c.cfg.dbQueries.IsConversationParticipant(ctx, database.IsConversationParticipantParams{
+		ConversationID: conversationID,
+		UserID:         c.userID,
+	})
+	if err != nil {
+		log.Printf("IsConversationParticipant error: %v", err)
+		return c.sendError(ctx, "typing", "Couldn't send typing indicator")
+	}
+	if !member {
+		return c.sendError(ctx, "typing", "Conversation not found")
+	}
+	blocked, err := c.cfg.dbQueries.ConversationHasBlock(ctx, database.ConversationHasBlockParams{
+		ConversationID: conversationID,
+		UserID:         c.userID,
+	})
+	if err != nil {
+		log.Printf("ConversationHasBlock error: %v", err)
+		return c.sendError(ctx, "typing", "Couldn't send typing indicator")
+	}
+	if blocked {
+		return c.sendError(ctx, "typing", "You can't message a user you blocked or who blocked you")
+	}
+	if err := publishRealtimeEvent(ctx, c.cfg.dbQueries, realtimeEvent{
+		Type:           realtimeTyping,
+		UserID:         c.userID,
+		ConversationID: conversationID,
+	}); err != nil {
+		log.Printf("PublishRealtimeEvent error: %v", err)
+		return c.sendError(ctx, "typing", "Couldn't send typing indicator")
+	}
+	c.lastTyping[conversationID] = time.Now()
+	return nil
+}
+
+// reauthenticate replaces the connection's access token so it stays open
+// past the old token's expiry. A token for another user closes the
+// connection, and like any other request, one for a deleted or suspended
+// account is rejected.
+func (c *wsClient) reauthenticate(ctx context.Context, token string) error {
+	userID, expiresAt, err := c.cfg.validateJWTExpiry(ctx, token)
+	if err != nil {
+		return c.sendError(ctx, "auth", "Couldn't validate JWT")
+	}
+	if userID != c.userID {
+		c.conn.Close(websocket.StatusPolicyViolation, "Token is for another user")
+		return errWSClosed
+	}
+	c.setExpiry(expiresAt)
+	return c.sendAuthenticated(ctx)
+}
+
+func (c *wsClient) sendAuthenticated(ctx context.Context) error {
+	msg := wsServerMessage{Type: "authenticated"}
+	if !c.expiresAt.IsZero() {
+		msg.ExpiresAt = &c.expiresAt
+	}
+	return c.send(ctx, msg)
+}
+
+// sendTimelineEvent sends a chirp event if it belongs in the user's home
+// timeline: chirps by the user or someone they follow that they can see,
+// leaving out muted authors. Deletions are checked the same way against the
+// deleted chirp.
+func (c *wsClient) sendTimelineEvent(ctx context.Context, e *streamEvent) error {
+	if e.Type == chirpEventDeleted {
+		// Only chirps the user could have been sent are reported deleted.
+		sent, err := c.cfg.dbQueries.WasTimelineChirp(ctx, database.WasTimelineChirpParams{
+			ID:       e.ChirpID,
+			ViewerID: c.userID,
+		})
+		if err != nil {
+			log.Printf("WasTimelineChirp error: %v", err)
+			return nil
+		}
+		if !sent {
+			return nil
+		}
+		return c.sendEvent(ctx, wsTopicTimeline, "chirp_deleted", ChirpDeletedEvent{ID: e.ChirpID})
+	}
+
+	row, err := c.cfg.dbQueries.GetTimelineChirp(ctx, database.GetTimelineChirpParams{
+		ID:       e.ChirpID,
+		ViewerID: c.userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("GetTimelineChirp error: %v", err)
+		return nil
+	}
+	chirp, err := c.cfg.chirpResponse(ctx, c.userID, row)
+	if err != nil {
+		log.Println("Failed to load chirp references:", err)
+		return nil
+	}
+	return c.sendEvent(ctx, wsTopicTimeline, "chirp_created", chirp)
+}
+
+// sendRealtimeEvent sends a notification, message or typing indicator if
+// the client is still subscribed to it and allowed to see it. The end of
+// the user's session closes the connection.
+func (c *wsClient) sendRealtimeEvent(ctx context.Context, e realtimeEvent) error {
+	if e.Type == realtimeSessionEnded {
+		c.conn.Close(wsStatusSessionEnded, "Session ended")
+		return errWSClosed
+	}
+	if e.Type == realtimeNotification {
+		if _, ok := c.topics[wsTopicNotifications]; !ok {
+			return nil
+		}
+		row, err := c.cfg.dbQueries.GetNotification(ctx, database.GetNotificationParams{
+			ID:     e.ID,
+			UserID: c.userID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			log.Printf("GetNotification error: %v", err)
+			return nil
+		}
+		notifications, err := c.cfg.notificationResponses(ctx, c.userID, []database.Notification{row})
+		if err != nil {
+			log.Printf("GetNotificationActors error: %v", err)
+			return nil
+		}
+		return c.sendEvent(ctx, wsTopicNotifications, "notification", notifications[0])
+	}
+
+	topic := wsTopicConversationPrefix + e.ConversationID.String()
+	if _, ok := c.topics[topic]; !ok {
+		return nil
+	}
+	if e.Type == realtimeTyping && e.UserID == c.userID {
+		return nil
+	}
+	// Participants who have left, or are on either side of a block with the
+	// sender, don't hear from them.
+	visible, err := c.cfg.dbQueries.ConversationEventVisible(ctx, database.ConversationEventVisibleParams{
+		ConversationID: e.ConversationID,
+		ViewerID:       c.userID,
+		ActorID:        e.UserID,
+	})
+	if err != nil {
+		log.Printf("ConversationEventVisible error: %v", err)
+		return nil
+	}
+	if !visible {
+		return nil
+	}
+	if e.Type == realtimeTyping {
+		return c.sendEvent(ctx, topic, "typing", TypingEvent{ConversationID: e.ConversationID, UserID: e.UserID})
+	}
+	message, err := c.cfg.dbQueries.GetMessage(ctx, database.GetMessageParams{
+		ID:             e.ID,
+		ConversationID: e.ConversationID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("GetMessage error: %v", err)
+		return nil
+	}
+	return c.sendEvent(ctx, topic, "message", messageResponse(message))
+}
+
+func (c *wsClient) sendEvent(ctx context.Context, topic, event string, data any) error {
+	return c.send(ctx, wsServerMessage{Type: "event", Topic: topic, Event: event, Data: data})
+}
+
+func (c *wsClient) sendError(ctx context.Context, request, message string) error {
+	return c.send(ctx, wsServerMessage{Type: "error", Request: request, Error: message})
+}
+
+// send writes msg, giving up on clients that don't read it in time.
+func (c *wsClient) send(ctx context.Context, msg wsServerMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, c.conn, msg)
+}